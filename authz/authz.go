@@ -103,6 +103,10 @@ p, *, *, GET, /api/run-casbin-command, *, *
 p, *, *, POST, /api/refresh-engines, *, *
 p, *, *, GET, /api/get-invitation-info, *, *
 p, *, *, GET, /api/faceid-signin-begin, *, *
+p, *, *, GET, /api/get-ldap-bind-approvals, *, *
+p, *, *, POST, /api/approve-ldap-bind, *, *
+p, *, *, GET, /api/get-radius-challenges, *, *
+p, *, *, POST, /api/approve-radius-challenge, *, *
 `
 
 		sa := stringadapter.NewAdapter(ruleText)
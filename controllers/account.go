@@ -259,6 +259,12 @@ func (c *ApiController) Signup() {
 		user.Groups = []string{application.DefaultGroup}
 	}
 
+	err = object.CheckSignupHooks(application, user, clientIp)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
 	affected, err := object.AddUser(user)
 	if err != nil {
 		c.ResponseError(err.Error())
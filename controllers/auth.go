@@ -89,6 +89,22 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		}
 	}
 
+	hookResult, err := c.getLoginHookResult(application, user)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if hookResult.IsDenied() {
+		c.ResponseError(hookResult.Message)
+		return
+	}
+
+	err = object.ApplyEventHookResult(user, hookResult)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
 	// check whether paid-user have active subscription
 	if user.Type == "paid-user" {
 		subscriptions, err := object.GetSubscriptionsByUser(user.Owner, user.Name)
@@ -213,7 +229,13 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		service := c.Input().Get("service")
 		resp = wrapErrorResponse(nil)
 		if service != "" {
-			st, err := object.GenerateCasToken(userId, service)
+			err = object.CheckCasLogin(application, c.GetAcceptLanguage(), service, user, c.isMfaVerifiedSession(userId))
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+
+			st, err := object.GenerateCasToken(application, userId, service, c.Ctx.Input.CruSession.SessionID())
 			if err != nil {
 				resp = wrapErrorResponse(err)
 			} else {
@@ -245,6 +267,8 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 			c.ResponseError(err.Error(), nil)
 			return
 		}
+
+		object.AddLoginDomainEvent(user, application.Name, clientIp, true)
 	}
 
 	return resp
@@ -291,7 +315,7 @@ func (c *ApiController) GetApplicationLogin() {
 			return
 		}
 
-		err = object.CheckCasLogin(application, c.GetAcceptLanguage(), redirectUri)
+		err = object.CheckCasLogin(application, c.GetAcceptLanguage(), redirectUri, nil, false)
 		if err != nil {
 			c.ResponseError(err.Error())
 			return
@@ -353,8 +377,35 @@ func isProxyProviderType(providerType string) bool {
 	return false
 }
 
-func checkMfaEnable(c *ApiController, user *object.User, organization *object.Organization, verificationType string) bool {
-	if object.IsNeedPromptMfa(organization, user) {
+// getLoginHookResult runs the PostLogin hooks of the user once per request, the MFA check and HandleLoggedIn share the
+// result
+func (c *ApiController) getLoginHookResult(application *object.Application, user *object.User) (*object.EventHookResult, error) {
+	if result, ok := c.Ctx.Input.GetData("loginHookResult").(*object.EventHookResult); ok {
+		return result, nil
+	}
+
+	result, err := object.RunEventHooks(object.EventHookPostLogin, application, user, util.GetClientIpFromRequest(c.Ctx.Request))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Ctx.Input.SetData("loginHookResult", result)
+	return result, nil
+}
+
+func checkMfaEnable(c *ApiController, application *object.Application, user *object.User, organization *object.Organization, verificationType string) bool {
+	isMfaRequired := object.IsNeedPromptMfa(organization, user)
+	if !isMfaRequired && !user.IsMfaEnabled() {
+		// the PostLogin hooks can require a user without MFA to set it up, a denial is answered by HandleLoggedIn
+		hookResult, err := c.getLoginHookResult(application, user)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return true
+		}
+		isMfaRequired = hookResult.RequireMfa && !hookResult.IsDenied()
+	}
+
+	if isMfaRequired {
 		// The prompt page needs the user to be srigned in
 		c.SetSessionUsername(user.GetId())
 		c.ResponseOk(object.RequiredMfa)
@@ -623,7 +674,7 @@ func (c *ApiController) Login() {
 				c.ResponseError(err.Error())
 			}
 
-			if checkMfaEnable(c, user, organization, verificationType) {
+			if checkMfaEnable(c, application, user, organization, verificationType) {
 				return
 			}
 
@@ -759,7 +810,7 @@ func (c *ApiController) Login() {
 					return
 				}
 
-				if checkMfaEnable(c, user, organization, verificationType) {
+				if checkMfaEnable(c, application, user, organization, verificationType) {
 					return
 				}
 
@@ -866,6 +917,12 @@ func (c *ApiController) Login() {
 						Properties:        properties,
 					}
 
+					err = object.CheckSignupHooks(application, user, util.GetClientIpFromRequest(c.Ctx.Request))
+					if err != nil {
+						c.ResponseError(err.Error())
+						return
+					}
+
 					var affected bool
 					affected, err = object.AddUser(user)
 					if err != nil {
@@ -1022,6 +1079,7 @@ func (c *ApiController) Login() {
 			return
 		}
 
+		c.setMfaVerifiedSession(user.GetId())
 		resp = c.HandleLoggedIn(application, user, &authForm)
 		c.setMfaUserSession("")
 
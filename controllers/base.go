@@ -223,6 +223,15 @@ func (c *ApiController) getMfaUserSession() string {
 	return userId.(string)
 }
 
+func (c *ApiController) setMfaVerifiedSession(userId string) {
+	c.SetSession(object.MfaVerifiedSessionUserId, userId)
+}
+
+func (c *ApiController) isMfaVerifiedSession(userId string) bool {
+	verifiedUserId := c.Ctx.Input.CruSession.Get(object.MfaVerifiedSessionUserId)
+	return verifiedUserId != nil && verifiedUserId.(string) == userId
+}
+
 func (c *ApiController) setExpireForSession() {
 	timestamp := time.Now().Unix()
 	timestamp += 3600 * 24
@@ -48,9 +48,11 @@ func (c *RootController) CasValidate() {
 		c.Ctx.Output.Body([]byte("no\n"))
 		return
 	}
-	if ok, response, issuedService, _ := object.GetCasTokenByTicket(ticket); ok {
+	casTicket, response, err := object.GetCasTokenByTicket(ticket)
+	if err == nil && casTicket != nil {
+		_, isRegistered, err := object.GetCasServiceByTicket(casTicket)
 		// check whether service is the one for which we previously issued token
-		if issuedService == service {
+		if err == nil && isRegistered && casTicket.Service == service {
 			c.Ctx.Output.Body([]byte(fmt.Sprintf("yes\n%s\n", response.User)))
 			return
 		}
@@ -98,10 +100,15 @@ func (c *RootController) CasP3ProxyValidate() {
 		c.sendCasAuthenticationResponseErr(InvalidRequest, "service and ticket must exist", format)
 		return
 	}
-	ok, response, issuedService, userId := object.GetCasTokenByTicket(ticket)
+	casTicket, response, err := object.GetCasTokenByTicket(ticket)
+	if err != nil {
+		c.sendCasAuthenticationResponseErr(InternalError, err.Error(), format)
+		return
+	}
 	// find the token
-	if ok {
+	if casTicket != nil {
 		// check whether service is the one for which we previously issued token
+		issuedService := casTicket.Service
 		if strings.HasPrefix(service, issuedService) || strings.HasPrefix(queryUnescape(service), issuedService) {
 			serviceResponse.Success = response
 		} else {
@@ -115,9 +122,30 @@ func (c *RootController) CasP3ProxyValidate() {
 		return
 	}
 
+	// check the registered CAS service of the application
+	casService, isRegistered, err := object.GetCasServiceByTicket(casTicket)
+	if err != nil {
+		c.sendCasAuthenticationResponseErr(InternalError, err.Error(), format)
+		return
+	}
+	if !isRegistered {
+		c.sendCasAuthenticationResponseErr(UnauthorizedService, fmt.Sprintf("service %s is not registered", casTicket.Service), format)
+		return
+	}
+
+	if pgtUrl != "" && casService != nil && !casService.AllowProxy {
+		c.sendCasAuthenticationResponseErr(UnauthorizedServiceProxy, fmt.Sprintf("service %s is not allowed to proxy", casTicket.Service), format)
+		return
+	}
+
 	if pgtUrl != "" && serviceResponse.Failure == nil {
 		// that means we are in proxy web flow
-		pgt := object.StoreCasTokenForPgt(serviceResponse.Success, service, userId)
+		pgt, err := object.StoreCasTokenForPgt(serviceResponse.Success, casTicket)
+		if err != nil {
+			c.sendCasAuthenticationResponseErr(InternalError, err.Error(), format)
+			return
+		}
+
 		pgtiou := serviceResponse.Success.ProxyGrantingTicket
 		// todo: check whether it is https
 		pgtUrlObj, err := url.Parse(pgtUrl)
@@ -169,18 +197,36 @@ func (c *RootController) CasProxy() {
 		return
 	}
 
-	ok, authenticationSuccess, issuedService, userId := object.GetCasTokenByPgt(pgt)
-	if !ok {
+	pgtTicket, authenticationSuccess, err := object.GetCasTokenByPgt(pgt)
+	if err != nil {
+		c.sendCasProxyResponseErr(InternalError, err.Error(), format)
+		return
+	}
+	if pgtTicket == nil {
 		c.sendCasProxyResponseErr(UnauthorizedService, "service not authorized", format)
 		return
 	}
 
+	application, err := object.GetApplication(pgtTicket.Application)
+	if err != nil {
+		c.sendCasProxyResponseErr(InternalError, err.Error(), format)
+		return
+	}
+	if application == nil || (application.IsCasServiceRegistryEnabled() && application.GetCasService(targetService) == nil) {
+		c.sendCasProxyResponseErr(UnauthorizedService, fmt.Sprintf("service %s is not registered", targetService), format)
+		return
+	}
+
 	newAuthenticationSuccess := authenticationSuccess.DeepCopy()
 	if newAuthenticationSuccess.Proxies == nil {
 		newAuthenticationSuccess.Proxies = &object.CasProxies{}
 	}
-	newAuthenticationSuccess.Proxies.Proxies = append(newAuthenticationSuccess.Proxies.Proxies, issuedService)
-	proxyTicket := object.StoreCasTokenForProxyTicket(&newAuthenticationSuccess, targetService, userId)
+	newAuthenticationSuccess.Proxies.Proxies = append(newAuthenticationSuccess.Proxies.Proxies, pgtTicket.Service)
+	proxyTicket, err := object.StoreCasTokenForProxyTicket(&newAuthenticationSuccess, targetService, pgtTicket)
+	if err != nil {
+		c.sendCasProxyResponseErr(InternalError, err.Error(), format)
+		return
+	}
 
 	serviceResponse := object.CasServiceResponse{
 		Xmlns: "http://www.yale.edu/tp/cas",
@@ -222,7 +268,7 @@ func (c *RootController) SamlValidate() {
 		return
 	}
 
-	if !strings.HasPrefix(target, service) {
+	if target != service {
 		c.ResponseError(fmt.Sprintf(c.T("cas:Service %s and %s do not match"), target, service))
 		return
 	}
@@ -0,0 +1,162 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetEventHooks
+// @Title GetEventHooks
+// @Tag Event Hook API
+// @Description get event hooks
+// @Param   owner     query    string  built-in/admin	true        "The owner of event hooks"
+// @Success 200 {array} object.EventHook The Response object
+// @router /get-event-hooks [get]
+func (c *ApiController) GetEventHooks() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	organization := c.Input().Get("organization")
+
+	if limit == "" || page == "" {
+		hooks, err := object.GetMaskedEventHooks(object.GetEventHooks(owner, organization))
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(hooks)
+	} else {
+		limit := util.ParseInt(limit)
+		count, err := object.GetEventHookCount(owner, organization, field, value)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		paginator := pagination.SetPaginator(c.Ctx, limit, count)
+
+		hooks, err := object.GetMaskedEventHooks(object.GetPaginationEventHooks(owner, organization, paginator.Offset(), limit, field, value, sortField, sortOrder))
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(hooks, paginator.Nums())
+	}
+}
+
+// GetEventHook
+// @Title GetEventHook
+// @Tag Event Hook API
+// @Description get event hook
+// @Param   id     query    string  built-in/admin	true        "The id ( owner/name ) of the event hook"
+// @Success 200 {object} object.EventHook The Response object
+// @router /get-event-hook [get]
+func (c *ApiController) GetEventHook() {
+	id := c.Input().Get("id")
+
+	hook, err := object.GetMaskedEventHook(object.GetEventHook(id))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(hook)
+}
+
+// UpdateEventHook
+// @Title UpdateEventHook
+// @Tag Event Hook API
+// @Description update event hook
+// @Param   id     query    string  built-in/admin true        "The id ( owner/name ) of the event hook"
+// @Param   body    body   object.EventHook  true        "The details of the event hook"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-event-hook [post]
+func (c *ApiController) UpdateEventHook() {
+	id := c.Input().Get("id")
+
+	var hook object.EventHook
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	oldHook, err := object.GetEventHook(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if (oldHook != nil && !c.isOrganizationAdmin(oldHook.Organization)) || !c.isOrganizationAdmin(hook.Organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateEventHook(id, &hook))
+	c.ServeJSON()
+}
+
+// AddEventHook
+// @Title AddEventHook
+// @Tag Event Hook API
+// @Description add event hook
+// @Param   body    body   object.EventHook  true        "The details of the event hook"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-event-hook [post]
+func (c *ApiController) AddEventHook() {
+	var hook object.EventHook
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !c.isOrganizationAdmin(hook.Organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.AddEventHook(&hook))
+	c.ServeJSON()
+}
+
+// DeleteEventHook
+// @Title DeleteEventHook
+// @Tag Event Hook API
+// @Description delete event hook
+// @Param   body    body   object.EventHook  true        "The details of the event hook"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-event-hook [post]
+func (c *ApiController) DeleteEventHook() {
+	var hook object.EventHook
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteEventHook(&hook))
+	c.ServeJSON()
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetEventSinks
+// @Title GetEventSinks
+// @Tag Event Sink API
+// @Description get event sinks
+// @Param   owner     query    string  built-in/admin	true        "The owner of event sinks"
+// @Success 200 {array} object.EventSink The Response object
+// @router /get-event-sinks [get]
+func (c *ApiController) GetEventSinks() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	organization := c.Input().Get("organization")
+
+	if limit == "" || page == "" {
+		sinks, err := object.GetMaskedEventSinks(object.GetEventSinks(owner, organization))
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(sinks)
+	} else {
+		limit := util.ParseInt(limit)
+		count, err := object.GetEventSinkCount(owner, organization, field, value)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		paginator := pagination.SetPaginator(c.Ctx, limit, count)
+
+		sinks, err := object.GetMaskedEventSinks(object.GetPaginationEventSinks(owner, organization, paginator.Offset(), limit, field, value, sortField, sortOrder))
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(sinks, paginator.Nums())
+	}
+}
+
+// GetEventSink
+// @Title GetEventSink
+// @Tag Event Sink API
+// @Description get event sink
+// @Param   id     query    string  built-in/admin	true        "The id ( owner/name ) of the event sink"
+// @Success 200 {object} object.EventSink The Response object
+// @router /get-event-sink [get]
+func (c *ApiController) GetEventSink() {
+	id := c.Input().Get("id")
+
+	sink, err := object.GetMaskedEventSink(object.GetEventSink(id))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(sink)
+}
+
+// checkEventSinkAccess checks that an organization admin only publishes the events of its own organization, a sink of
+// all organizations and a File sink, which writes to the server, can only be saved by global admins
+func (c *ApiController) checkEventSinkAccess(sink *object.EventSink) bool {
+	if !c.isOrganizationAdmin(sink.Organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return false
+	}
+
+	if sink.Type == object.EventSinkTypeFile && !c.IsGlobalAdmin() {
+		c.ResponseError("only global admins can add a File event sink")
+		return false
+	}
+	return true
+}
+
+// UpdateEventSink
+// @Title UpdateEventSink
+// @Tag Event Sink API
+// @Description update event sink
+// @Param   id     query    string  built-in/admin true        "The id ( owner/name ) of the event sink"
+// @Param   body    body   object.EventSink  true        "The details of the event sink"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-event-sink [post]
+func (c *ApiController) UpdateEventSink() {
+	id := c.Input().Get("id")
+
+	var sink object.EventSink
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &sink)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	oldSink, err := object.GetEventSink(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if (oldSink != nil && !c.checkEventSinkAccess(oldSink)) || !c.checkEventSinkAccess(&sink) {
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateEventSink(id, &sink))
+	c.ServeJSON()
+}
+
+// AddEventSink
+// @Title AddEventSink
+// @Tag Event Sink API
+// @Description add event sink
+// @Param   body    body   object.EventSink  true        "The details of the event sink"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-event-sink [post]
+func (c *ApiController) AddEventSink() {
+	var sink object.EventSink
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &sink)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !c.checkEventSinkAccess(&sink) {
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.AddEventSink(&sink))
+	c.ServeJSON()
+}
+
+// DeleteEventSink
+// @Title DeleteEventSink
+// @Tag Event Sink API
+// @Description delete event sink
+// @Param   body    body   object.EventSink  true        "The details of the event sink"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-event-sink [post]
+func (c *ApiController) DeleteEventSink() {
+	var sink object.EventSink
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &sink)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteEventSink(&sink))
+	c.ServeJSON()
+}
+
+// GetDomainEvents
+// @Title GetDomainEvents
+// @Tag Event Sink API
+// @Description get the domain events that are kept for the event sinks
+// @Param   organization     query    string  false        "The organization of the events"
+// @Success 200 {array} object.DomainEvent The Response object
+// @router /get-domain-events [get]
+func (c *ApiController) GetDomainEvents() {
+	organization := c.Input().Get("organization")
+	isGlobalAdmin, user := c.isGlobalAdmin()
+	if !isGlobalAdmin {
+		if user == nil || !user.IsAdmin {
+			c.ResponseError(c.T("auth:Unauthorized operation"))
+			return
+		}
+		organization = user.Owner
+	}
+
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	if limit == "" || page == "" {
+		limit = "10"
+		page = "1"
+	}
+
+	count, err := object.GetDomainEventCount(organization, field, value)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	paginator := pagination.SetPaginator(c.Ctx, util.ParseInt(limit), count)
+	events, err := object.GetPaginationDomainEvents(organization, paginator.Offset(), util.ParseInt(limit), field, value, sortField, sortOrder)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(events, paginator.Nums())
+}
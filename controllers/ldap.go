@@ -275,8 +275,42 @@ func (c *ApiController) SyncLdapUsers() {
 		return
 	}
 
+	ldap, err := object.GetLdap(ldapId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if ldap != nil && ldap.EnableGroupSync {
+		err = object.SyncLdapGroups(ldapId)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	}
+
 	c.ResponseOk(&LdapSyncResp{
 		Exist:  exist,
 		Failed: failed,
 	})
 }
+
+// RunLdapSync
+// @Title RunLdapSync
+// @Tag Account API
+// @Description run an incremental or full sync of the ldap server and return the report of the run
+// @Param	id	query	string		true	"id"
+// @Success 200 {object} object.LdapSyncReport The Response object
+// @router /run-ldap-sync [post]
+func (c *ApiController) RunLdapSync() {
+	id := c.Input().Get("id")
+
+	_, ldapId := util.GetOwnerAndNameFromId(id)
+	report, err := object.RunLdapSync(ldapId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(report)
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetLdapBindApprovals
+// @Title GetLdapBindApprovals
+// @Tag LDAP API
+// @Description get the pending LDAP bind approvals of the signed-in user
+// @Success 200 {array} object.LdapBindApproval The Response object
+// @router /get-ldap-bind-approvals [get]
+func (c *ApiController) GetLdapBindApprovals() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	approvals, err := object.GetPendingLdapBindApprovals(userId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(approvals)
+}
+
+// ApproveLdapBind
+// @Title ApproveLdapBind
+// @Tag LDAP API
+// @Description approve or deny a pending LDAP bind of the signed-in user
+// @Param   id     query    string  true        "The id of the LDAP bind approval"
+// @Param   approved     query    string  true        "Whether the bind is approved"
+// @Success 200 {object} controllers.Response The Response object
+// @router /approve-ldap-bind [post]
+func (c *ApiController) ApproveLdapBind() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+	isApproved := c.Input().Get("approved") == "true"
+
+	err := object.SetLdapBindApprovalState(id, userId, isApproved)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetRadiusChallenges
+// @Title GetRadiusChallenges
+// @Tag RADIUS API
+// @Description get the RADIUS sign-in requests of the signed-in user which wait for push approval
+// @Success 200 {array} object.RadiusChallenge The Response object
+// @router /get-radius-challenges [get]
+func (c *ApiController) GetRadiusChallenges() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	challenges, err := object.GetPendingRadiusChallenges(userId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(challenges)
+}
+
+// ApproveRadiusChallenge
+// @Title ApproveRadiusChallenge
+// @Tag RADIUS API
+// @Description approve or deny a RADIUS sign-in request of the signed-in user
+// @Param   state     query    string  true        "The state of the RADIUS challenge"
+// @Param   approved     query    string  true        "Whether the sign-in is approved"
+// @Success 200 {object} controllers.Response The Response object
+// @router /approve-radius-challenge [post]
+func (c *ApiController) ApproveRadiusChallenge() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	state := c.Input().Get("state")
+	isApproved := c.Input().Get("approved") == "true"
+
+	err := object.SetRadiusChallengeApprovalState(state, userId, isApproved)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetRadiusPolicies
+// @Title GetRadiusPolicies
+// @Tag RADIUS API
+// @Description get RADIUS policies
+// @Param   owner     query    string  true        "The owner of RADIUS policies"
+// @Success 200 {array} object.RadiusPolicy The Response object
+// @router /get-radius-policies [get]
+func (c *ApiController) GetRadiusPolicies() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	if limit == "" || page == "" {
+		policies, err := object.GetRadiusPolicies(owner)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(policies)
+	} else {
+		limit := util.ParseInt(limit)
+		count, err := object.GetRadiusPolicyCount(owner, field, value)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		paginator := pagination.SetPaginator(c.Ctx, limit, count)
+		policies, err := object.GetPaginationRadiusPolicies(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(policies, paginator.Nums())
+	}
+}
+
+// GetRadiusPolicy
+// @Title GetRadiusPolicy
+// @Tag RADIUS API
+// @Description get RADIUS policy
+// @Param   id     query    string  true        "The id ( owner/name ) of the RADIUS policy"
+// @Success 200 {object} object.RadiusPolicy The Response object
+// @router /get-radius-policy [get]
+func (c *ApiController) GetRadiusPolicy() {
+	id := c.Input().Get("id")
+
+	policy, err := object.GetRadiusPolicy(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(policy)
+}
+
+// UpdateRadiusPolicy
+// @Title UpdateRadiusPolicy
+// @Tag RADIUS API
+// @Description update RADIUS policy
+// @Param   id     query    string  true        "The id ( owner/name ) of the RADIUS policy"
+// @Param   body    body   object.RadiusPolicy  true        "The details of the RADIUS policy"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-radius-policy [post]
+func (c *ApiController) UpdateRadiusPolicy() {
+	id := c.Input().Get("id")
+
+	var policy object.RadiusPolicy
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &policy)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateRadiusPolicy(id, &policy))
+	c.ServeJSON()
+}
+
+// AddRadiusPolicy
+// @Title AddRadiusPolicy
+// @Tag RADIUS API
+// @Description add RADIUS policy
+// @Param   body    body   object.RadiusPolicy  true        "The details of the RADIUS policy"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-radius-policy [post]
+func (c *ApiController) AddRadiusPolicy() {
+	var policy object.RadiusPolicy
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &policy)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.AddRadiusPolicy(&policy))
+	c.ServeJSON()
+}
+
+// DeleteRadiusPolicy
+// @Title DeleteRadiusPolicy
+// @Tag RADIUS API
+// @Description delete RADIUS policy
+// @Param   body    body   object.RadiusPolicy  true        "The details of the RADIUS policy"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-radius-policy [post]
+func (c *ApiController) DeleteRadiusPolicy() {
+	var policy object.RadiusPolicy
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &policy)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteRadiusPolicy(&policy))
+	c.ServeJSON()
+}
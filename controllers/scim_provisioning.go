@@ -0,0 +1,89 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetScimProvisioningRecords
+// @Title GetScimProvisioningRecords
+// @Tag SCIM API
+// @Description get the SCIM provisioning records of an organization
+// @Param   owner     query    string  true        "The organization of the records"
+// @Success 200 {array} object.ScimProvisioningRecord The Response object
+// @router /get-scim-provisioning-records [get]
+func (c *ApiController) GetScimProvisioningRecords() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	if limit == "" || page == "" {
+		limit = "100"
+		page = "1"
+	}
+
+	count, err := object.GetScimProvisioningRecordCount(owner, field, value)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	paginator := pagination.SetPaginator(c.Ctx, util.ParseInt(limit), count)
+	records, err := object.GetPaginationScimProvisioningRecords(owner, paginator.Offset(), util.ParseInt(limit), field, value, sortField, sortOrder)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(records, paginator.Nums())
+}
+
+// RunScimReconciliation
+// @Title RunScimReconciliation
+// @Tag SCIM API
+// @Description push all the users and groups of the organization to the SCIM service of the application
+// @Param   id     query    string  true        "The id ( owner/name ) of the application"
+// @Success 200 {object} controllers.Response The Response object
+// @router /run-scim-reconciliation [post]
+func (c *ApiController) RunScimReconciliation() {
+	id := c.Input().Get("id")
+
+	application, err := object.GetApplication(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if application == nil {
+		c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), id))
+		return
+	}
+
+	err = object.ReconcileScimProvisioning(application)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
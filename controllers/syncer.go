@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/beego/beego/utils/pagination"
 	"github.com/casdoor/casdoor/object"
@@ -103,6 +104,11 @@ func (c *ApiController) UpdateSyncer() {
 		return
 	}
 
+	if syncer.IsLocalFile() && !c.IsGlobalAdmin() {
+		c.ResponseError("only global admins can sync from a local file, please set a storage provider")
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.UpdateSyncer(id, &syncer))
 	c.ServeJSON()
 }
@@ -122,6 +128,11 @@ func (c *ApiController) AddSyncer() {
 		return
 	}
 
+	if syncer.IsLocalFile() && !c.IsGlobalAdmin() {
+		c.ResponseError("only global admins can sync from a local file, please set a storage provider")
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.AddSyncer(&syncer))
 	c.ServeJSON()
 }
@@ -160,13 +171,84 @@ func (c *ApiController) RunSyncer() {
 		return
 	}
 
-	err = object.RunSyncer(syncer)
+	run, err := object.RunSyncer(syncer)
 	if err != nil {
 		c.ResponseError(err.Error())
 		return
 	}
 
-	c.ResponseOk()
+	c.ResponseOk(run)
+}
+
+// DryRunSyncer
+// @Title DryRunSyncer
+// @Tag Syncer API
+// @Description preview the changes of a sync without applying them
+// @Param   id     query    string  true        "The id ( owner/name ) of the syncer"
+// @Success 200 {object} object.SyncerRun The Response object
+// @router /dry-run-syncer [post]
+func (c *ApiController) DryRunSyncer() {
+	id := c.Input().Get("id")
+	syncer, err := object.GetSyncer(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if syncer == nil {
+		c.ResponseError(fmt.Sprintf("the syncer: %s is not found", id))
+		return
+	}
+
+	run, err := object.DryRunSyncer(syncer)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(run)
+}
+
+// GetSyncerRuns
+// @Title GetSyncerRuns
+// @Tag Syncer API
+// @Description get the sync history of a syncer
+// @Param   id     query    string  true        "The id ( owner/name ) of the syncer"
+// @Success 200 {array} object.SyncerRun The Response object
+// @router /get-syncer-runs [get]
+func (c *ApiController) GetSyncerRuns() {
+	id := c.Input().Get("id")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	owner, _, err := util.GetOwnerAndNameFromIdWithError(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if limit == "" || page == "" {
+		limit = "10"
+		page = "1"
+	}
+
+	count, err := object.GetSyncerRunCount(owner, id, field, value)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	paginator := pagination.SetPaginator(c.Ctx, util.ParseInt(limit), count)
+	runs, err := object.GetPaginationSyncerRuns(owner, id, paginator.Offset(), util.ParseInt(limit), field, value, sortField, sortOrder)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(runs, paginator.Nums())
 }
 
 func (c *ApiController) TestSyncerDb() {
@@ -177,6 +259,11 @@ func (c *ApiController) TestSyncerDb() {
 		return
 	}
 
+	if syncer.IsLocalFile() && !c.IsGlobalAdmin() {
+		c.ResponseError("only global admins can sync from a local file, please set a storage provider")
+		return
+	}
+
 	err = object.TestSyncerDb(syncer)
 	if err != nil {
 		c.ResponseError(err.Error())
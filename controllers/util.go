@@ -152,6 +152,17 @@ func (c *ApiController) RequireAdmin() (string, bool) {
 	return user.Owner, true
 }
 
+// isOrganizationAdmin checks that the signed-in user is a global admin or an admin of the organization, an empty
+// organization covers all organizations and is only allowed for global admins
+func (c *ApiController) isOrganizationAdmin(organization string) bool {
+	isGlobalAdmin, user := c.isGlobalAdmin()
+	if isGlobalAdmin {
+		return true
+	}
+
+	return user != nil && user.IsAdmin && organization != "" && organization == user.Owner
+}
+
 func (c *ApiController) IsOrgAdmin() (bool, bool) {
 	userId, ok := c.RequireSignedIn()
 	if !ok {
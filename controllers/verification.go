@@ -124,7 +124,7 @@ func (c *ApiController) GetVerification() {
 // SignUpWithPhone it's used to sign up a user with phone number
 // @Title SignUpWithPhone
 // @Tag Verification API
-func SignUpWithPhone(phoneNum, CountryCode string) error {
+func SignUpWithPhone(application *object.Application, phoneNum, CountryCode string, clientIp string) error {
 	user := &object.User{
 		Owner:       "built-in",
 		Name:        phoneNum,
@@ -143,6 +143,11 @@ func SignUpWithPhone(phoneNum, CountryCode string) error {
 		Karma:       0,
 	}
 
+	err := object.CheckSignupHooks(application, user, clientIp)
+	if err != nil {
+		return err
+	}
+
 	affected, err := object.AddUser(user)
 	if err != nil {
 		return err
@@ -331,7 +336,7 @@ func (c *ApiController) SendVerificationCode() {
 			if vform.CountryCode == "" {
 				vform.CountryCode = "CN"
 			}
-			err_ := SignUpWithPhone(vform.Dest, "CN")
+			err_ := SignUpWithPhone(application, vform.Dest, "CN", clientIp)
 
 			if err_ != nil {
 				c.ResponseError(err_.Error())
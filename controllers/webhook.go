@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/beego/beego/utils/pagination"
 	"github.com/casdoor/casdoor/object"
@@ -41,7 +42,7 @@ func (c *ApiController) GetWebhooks() {
 	organization := c.Input().Get("organization")
 
 	if limit == "" || page == "" {
-		webhooks, err := object.GetWebhooks(owner, organization)
+		webhooks, err := object.GetMaskedWebhooks(object.GetWebhooks(owner, organization))
 		if err != nil {
 			c.ResponseError(err.Error())
 			return
@@ -58,7 +59,7 @@ func (c *ApiController) GetWebhooks() {
 
 		paginator := pagination.SetPaginator(c.Ctx, limit, count)
 
-		webhooks, err := object.GetPaginationWebhooks(owner, organization, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		webhooks, err := object.GetMaskedWebhooks(object.GetPaginationWebhooks(owner, organization, paginator.Offset(), limit, field, value, sortField, sortOrder))
 		if err != nil {
 			c.ResponseError(err.Error())
 			return
@@ -78,7 +79,7 @@ func (c *ApiController) GetWebhooks() {
 func (c *ApiController) GetWebhook() {
 	id := c.Input().Get("id")
 
-	webhook, err := object.GetWebhook(id)
+	webhook, err := object.GetMaskedWebhook(object.GetWebhook(id))
 	if err != nil {
 		c.ResponseError(err.Error())
 		return
@@ -146,3 +147,112 @@ func (c *ApiController) DeleteWebhook() {
 	c.Data["json"] = wrapActionResponse(object.DeleteWebhook(&webhook))
 	c.ServeJSON()
 }
+
+// GetWebhookDeliveries
+// @Title GetWebhookDeliveries
+// @Tag Webhook API
+// @Description get the delivery log of a webhook
+// @Param   id     query    string  true        "The id ( owner/name ) of the webhook"
+// @Success 200 {array} object.WebhookDelivery The Response object
+// @router /get-webhook-deliveries [get]
+func (c *ApiController) GetWebhookDeliveries() {
+	id := c.Input().Get("id")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	owner, _, err := util.GetOwnerAndNameFromIdWithError(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if limit == "" || page == "" {
+		limit = "10"
+		page = "1"
+	}
+
+	count, err := object.GetWebhookDeliveryCount(owner, id, field, value)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	paginator := pagination.SetPaginator(c.Ctx, util.ParseInt(limit), count)
+	deliveries, err := object.GetPaginationWebhookDeliveries(owner, id, paginator.Offset(), util.ParseInt(limit), field, value, sortField, sortOrder)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(deliveries, paginator.Nums())
+}
+
+// ReplayWebhookDeliveries
+// @Title ReplayWebhookDeliveries
+// @Tag Webhook API
+// @Description send a delivery of a webhook again, or the deliveries created in a time range
+// @Param   id     query    string  true        "The id ( owner/name ) of the webhook"
+// @Param   name     query    string  false        "The name of the delivery"
+// @Param   startTime     query    string  false        "The start of the time range, e.g. 2025-01-01T00:00:00+08:00"
+// @Param   endTime     query    string  false        "The end of the time range"
+// @Param   state     query    string  false        "Only replay the deliveries in the state, e.g. Dead"
+// @Success 200 {object} controllers.Response The Response object
+// @router /replay-webhook-deliveries [post]
+func (c *ApiController) ReplayWebhookDeliveries() {
+	id := c.Input().Get("id")
+	name := c.Input().Get("name")
+	startTime := c.Input().Get("startTime")
+	endTime := c.Input().Get("endTime")
+	state := c.Input().Get("state")
+
+	webhook, err := object.GetWebhook(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if webhook == nil {
+		c.ResponseError(fmt.Sprintf("the webhook: %s is not found", id))
+		return
+	}
+
+	count, err := object.ReplayWebhookDeliveries(webhook, name, startTime, endTime, state)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(count)
+}
+
+// RotateWebhookSecret
+// @Title RotateWebhookSecret
+// @Tag Webhook API
+// @Description generate a new signing secret of a webhook, the previous secret signs the requests too during a grace period
+// @Param   id     query    string  true        "The id ( owner/name ) of the webhook"
+// @Success 200 {object} controllers.Response The Response object
+// @router /rotate-webhook-secret [post]
+func (c *ApiController) RotateWebhookSecret() {
+	id := c.Input().Get("id")
+
+	webhook, err := object.GetWebhook(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if webhook == nil {
+		c.ResponseError(fmt.Sprintf("the webhook: %s is not found", id))
+		return
+	}
+
+	secret, err := object.RotateWebhookSecret(webhook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(secret)
+}
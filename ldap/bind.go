@@ -0,0 +1,344 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	ldap "github.com/casdoor/ldapserver"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/lor00x/goldap/message"
+	"github.com/xorm-io/builder"
+)
+
+const (
+	LdapBindMfaModeTotpSuffix = "TotpSuffix"
+	LdapBindMfaModeApproval   = "Approval"
+)
+
+const (
+	ldapServiceAccountsOu      = "service-accounts"
+	ldapTotpCodeLength         = 6
+	ldapBindFailureWindow      = 15 * time.Minute
+	defaultBindApprovalTimeout = 60 * time.Second
+)
+
+type bindFailure struct {
+	count    int
+	lastTime time.Time
+}
+
+var (
+	bindFailures         = map[string]*bindFailure{}
+	bindFailurePruneTime time.Time
+	bindFailureMutex     sync.Mutex
+)
+
+func getClientIp(m *ldap.Message) string {
+	addr := m.Client.Addr()
+	if addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func getBindFailureLimit() int {
+	limit, err := conf.GetConfigInt64("ldapBindFailureLimit")
+	if err != nil {
+		return 0
+	}
+	return int(limit)
+}
+
+// isBindBlocked reports whether the IP has reached the limit of failed binds in the failure window
+func isBindBlocked(ip string) bool {
+	limit := getBindFailureLimit()
+	if limit <= 0 {
+		return false
+	}
+
+	bindFailureMutex.Lock()
+	defer bindFailureMutex.Unlock()
+
+	failure, ok := bindFailures[ip]
+	if !ok {
+		return false
+	}
+	if time.Since(failure.lastTime) > ldapBindFailureWindow {
+		delete(bindFailures, ip)
+		return false
+	}
+	return failure.count >= limit
+}
+
+// pruneBindFailures deletes the failures outside the failure window once per window, the caller must hold the mutex
+func pruneBindFailures() {
+	if time.Since(bindFailurePruneTime) < ldapBindFailureWindow {
+		return
+	}
+
+	for ip, failure := range bindFailures {
+		if time.Since(failure.lastTime) > ldapBindFailureWindow {
+			delete(bindFailures, ip)
+		}
+	}
+	bindFailurePruneTime = time.Now()
+}
+
+func recordBindFailure(ip string) {
+	bindFailureMutex.Lock()
+	defer bindFailureMutex.Unlock()
+
+	pruneBindFailures()
+
+	failure, ok := bindFailures[ip]
+	if !ok || time.Since(failure.lastTime) > ldapBindFailureWindow {
+		failure = &bindFailure{}
+		bindFailures[ip] = failure
+	}
+	failure.count++
+	failure.lastTime = time.Now()
+}
+
+func resetBindFailures(ip string) {
+	bindFailureMutex.Lock()
+	defer bindFailureMutex.Unlock()
+	delete(bindFailures, ip)
+}
+
+// getServiceAccountNameFromDn returns the name of a service account DN like "cn=app,ou=service-accounts,dc=example,dc=com"
+func getServiceAccountNameFromDn(dn string) (string, bool) {
+	parsedDn, err := goldap.ParseDN(dn)
+	if err != nil || len(parsedDn.RDNs) < 2 {
+		return "", false
+	}
+
+	name, isServiceAccountsOu := "", false
+	for i, rdn := range parsedDn.RDNs {
+		for _, attribute := range rdn.Attributes {
+			if i == 0 && strings.EqualFold(attribute.Type, "cn") {
+				name = attribute.Value
+			} else if i == 1 && strings.EqualFold(attribute.Type, "ou") && strings.EqualFold(attribute.Value, ldapServiceAccountsOu) {
+				isServiceAccountsOu = true
+			}
+		}
+	}
+	return name, name != "" && isServiceAccountsOu
+}
+
+func checkServiceAccountPassword(name string, password string) (*object.LdapServiceAccount, error) {
+	account, err := object.GetLdapServiceAccount(util.GetId("admin", name))
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil || !account.IsEnabled || !account.IsPasswordCorrect(password) {
+		return nil, fmt.Errorf("the service account: %s doesn't exist or the password is incorrect", name)
+	}
+	return account, nil
+}
+
+func getServiceAccount(m *ldap.Message) *object.LdapServiceAccount {
+	conn := getLdapConn(m)
+	if conn == nil {
+		return nil
+	}
+	return conn.getServiceAccount()
+}
+
+// getServiceAccountOrgs returns the organizations the service account can search, which are all organizations
+// when the service account is not limited to some of them
+func getServiceAccountOrgs(account *object.LdapServiceAccount) ([]string, error) {
+	if len(account.Organizations) != 0 {
+		return account.Organizations, nil
+	}
+
+	organizations, err := object.GetOrganizations("admin")
+	if err != nil {
+		return nil, err
+	}
+
+	orgs := []string{}
+	for _, organization := range organizations {
+		orgs = append(orgs, organization.Name)
+	}
+	return orgs, nil
+}
+
+// getServiceAccountUsers returns the users of the organization matching the condition, or of all organizations
+// the service account can search when the organization is "*"
+func getServiceAccountUsers(account *object.LdapServiceAccount, org string, cond builder.Cond) ([]*object.User, int) {
+	if org == "*" && len(account.Organizations) == 0 {
+		users, err := object.GetGlobalUsersWithFilter(cond)
+		if err != nil {
+			log.Printf("GetGlobalUsersWithFilter() error: %s", err.Error())
+			return nil, ldap.LDAPResultOperationsError
+		}
+		return users, ldap.LDAPResultSuccess
+	}
+
+	orgs := account.Organizations
+	if org != "*" {
+		if !account.IsOrganizationAllowed(org) {
+			return nil, ldap.LDAPResultInsufficientAccessRights
+		}
+		orgs = []string{org}
+	}
+
+	res := []*object.User{}
+	for _, org := range orgs {
+		users, err := object.GetUsersWithFilter(org, cond)
+		if err != nil {
+			log.Printf("GetUsersWithFilter() error: %s", err.Error())
+			return nil, ldap.LDAPResultOperationsError
+		}
+		res = append(res, users...)
+	}
+	return res, ldap.LDAPResultSuccess
+}
+
+// filterServiceAccountEntries removes the entries outside the organizations and base DNs of the service account,
+// password hashes are never returned to service accounts
+func filterServiceAccountEntries(account *object.LdapServiceAccount, entries []*ldapEntry) []*ldapEntry {
+	res := []*ldapEntry{}
+	for _, e := range entries {
+		org := getOrgFromDn(e.dn)
+		if org != "" && !account.IsOrganizationAllowed(org) {
+			continue
+		}
+
+		if len(account.BaseDns) > 0 {
+			isAllowed := false
+			for _, baseDn := range account.BaseDns {
+				if isEntryInScope(e.dn, baseDn, message.SearchRequestHomeSubtree) {
+					isAllowed = true
+					break
+				}
+			}
+			if !isAllowed {
+				continue
+			}
+		}
+
+		e.removeAttribute("userPassword")
+		res = append(res, e)
+	}
+	return res
+}
+
+func checkUserPassword(org string, name string, password string) (*object.User, error) {
+	enableCaptcha := false
+	isSigninViaLdap := false
+	isPasswordWithLdapEnabled := password != ""
+	return object.CheckUserPassword(org, name, password, "en", enableCaptcha, isSigninViaLdap, isPasswordWithLdapEnabled)
+}
+
+func getBindApprovalTimeout() time.Duration {
+	timeout, err := conf.GetConfigInt64("ldapBindApprovalTimeout")
+	if err != nil || timeout <= 0 {
+		return defaultBindApprovalTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// checkBindPassword checks the bind password, users with MFA enabled have to append a TOTP code to the password
+// or approve the bind out of band, depending on ldapBindMfaMode. MFA is not checked when no mode is configured.
+func checkBindPassword(org string, name string, password string, clientIp string) (*object.User, error) {
+	mode := conf.GetConfigString("ldapBindMfaMode")
+	if mode == "" {
+		return checkUserPassword(org, name, password)
+	}
+
+	user, err := object.GetUserByFields(org, name)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsMfaEnabled() {
+		return checkUserPassword(org, name, password)
+	}
+
+	switch mode {
+	case LdapBindMfaModeTotpSuffix:
+		mfaProps := user.GetMfaProps(object.TotpType, false)
+		if !mfaProps.Enabled {
+			return nil, fmt.Errorf("the user: %s must set up a TOTP authenticator to bind via LDAP", user.GetId())
+		}
+		if len(password) <= ldapTotpCodeLength {
+			return nil, fmt.Errorf("the password must be followed by the TOTP code")
+		}
+
+		passcode := password[len(password)-ldapTotpCodeLength:]
+		user, err = checkUserPassword(org, name, password[:len(password)-ldapTotpCodeLength])
+		if err != nil {
+			return nil, err
+		}
+
+		err = object.GetMfaUtil(object.TotpType, mfaProps).Verify(passcode)
+		if err != nil {
+			return nil, fmt.Errorf("the TOTP code is incorrect")
+		}
+		return user, nil
+	case LdapBindMfaModeApproval:
+		user, err = checkUserPassword(org, name, password)
+		if err != nil {
+			return nil, err
+		}
+
+		provider, err := object.GetApprovalProvider(user)
+		if err != nil {
+			return nil, err
+		}
+		if provider == nil {
+			return nil, fmt.Errorf("the user: %s can't be asked to approve the bind, please add an email and an Email provider", user.GetId())
+		}
+
+		approval := object.NewLdapBindApproval(user.GetId(), clientIp, getBindApprovalTimeout())
+		err = object.AddLdapBindApproval(approval)
+		if err != nil {
+			return nil, err
+		}
+
+		content := fmt.Sprintf("LDAP bind of %s from %s, please approve or deny it in Casdoor", user.GetId(), clientIp)
+		err = object.SendApprovalRequest(user, provider, content)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("LDAP bind of user: %s from %s is waiting for approval: %s", user.GetId(), clientIp, approval.Id)
+		isApproved, err := approval.Wait()
+		if err != nil {
+			return nil, err
+		}
+		if !isApproved {
+			return nil, fmt.Errorf("the bind was not approved")
+		}
+		return user, nil
+	default:
+		return nil, fmt.Errorf("unknown LDAP bind MFA mode: %s", mode)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetServiceAccountNameFromDn(t *testing.T) {
+	name, ok := getServiceAccountNameFromDn("cn=app,ou=service-accounts,dc=example,dc=com")
+	assert.True(t, ok)
+	assert.Equal(t, "app", name)
+
+	_, ok = getServiceAccountNameFromDn("cn=alice,ou=built-in,dc=example,dc=com")
+	assert.False(t, ok)
+}
+
+func TestFilterServiceAccountEntries(t *testing.T) {
+	alice := newLdapEntry("uid=1,cn=alice,ou=built-in,dc=example,dc=com")
+	alice.addAttribute("userPassword", "{SSHA}xxx")
+	bob := newLdapEntry("uid=2,cn=bob,ou=other,dc=example,dc=com")
+
+	account := &object.LdapServiceAccount{Organizations: []string{"built-in"}}
+	entries := filterServiceAccountEntries(account, []*ldapEntry{alice, bob})
+	assert.Equal(t, []*ldapEntry{alice}, entries)
+	assert.Nil(t, alice.getAttribute("userPassword"))
+
+	account = &object.LdapServiceAccount{BaseDns: []string{"ou=other,dc=example,dc=com"}}
+	entries = filterServiceAccountEntries(account, []*ldapEntry{alice, bob})
+	assert.Equal(t, []*ldapEntry{bob}, entries)
+}
+
+func TestRecordBindFailurePrunesExpiredFailures(t *testing.T) {
+	bindFailures["10.0.0.1"] = &bindFailure{count: 3, lastTime: time.Now().Add(-2 * ldapBindFailureWindow)}
+	bindFailurePruneTime = time.Time{}
+
+	recordBindFailure("10.0.0.2")
+	_, ok := bindFailures["10.0.0.1"]
+	assert.False(t, ok)
+	assert.Equal(t, 1, bindFailures["10.0.0.2"].count)
+
+	resetBindFailures("10.0.0.2")
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+
+	"github.com/casdoor/casdoor/object"
+	ldap "github.com/casdoor/ldapserver"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// ldapListener wraps the accepted connections into ldapConn
+type ldapListener struct {
+	net.Listener
+}
+
+func (l ldapListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return newLdapConn(conn), nil
+}
+
+func wrapLdapListener(s *ldap.Server) {
+	s.Listener = ldapListener{Listener: s.Listener}
+}
+
+// ldapConn attaches response controls to the SearchResultDone messages written to the client,
+// which the ldapserver response writer doesn't support. Every message is written with a single Write call.
+// The underlying connection is replaced by a TLS connection after StartTLS. It also keeps the service account
+// the client is bound as, which the ldapserver client doesn't have a field for.
+type ldapConn struct {
+	net.Conn
+	mutex          sync.Mutex
+	controls       map[int64][]goldap.Control
+	serviceAccount *object.LdapServiceAccount
+}
+
+func newLdapConn(conn net.Conn) *ldapConn {
+	return &ldapConn{
+		Conn:     conn,
+		controls: map[int64][]goldap.Control{},
+	}
+}
+
+func getLdapConn(m *ldap.Message) *ldapConn {
+	conn, ok := m.Client.GetConn().(*ldapConn)
+	if !ok {
+		return nil
+	}
+	return conn
+}
+
+func (c *ldapConn) getConn() net.Conn {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.Conn
+}
+
+func (c *ldapConn) getServiceAccount() *object.LdapServiceAccount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.serviceAccount
+}
+
+func (c *ldapConn) setServiceAccount(account *object.LdapServiceAccount) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.serviceAccount = account
+}
+
+func (c *ldapConn) isTLS() bool {
+	_, ok := c.getConn().(*tls.Conn)
+	return ok
+}
+
+// startTLS performs the TLS handshake on the connection, the StartTLS response must have been sent before
+func (c *ldapConn) startTLS(config *tls.Config) error {
+	tlsConn := tls.Server(c.getConn(), config)
+	err := tlsConn.Handshake()
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Conn = tlsConn
+	return nil
+}
+
+func (c *ldapConn) Read(b []byte) (int, error) {
+	return c.getConn().Read(b)
+}
+
+func (c *ldapConn) setResponseControls(messageId int, controls ...goldap.Control) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.controls[int64(messageId)] = controls
+}
+
+func (c *ldapConn) popResponseControls(messageId int64) []goldap.Control {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	controls, ok := c.controls[messageId]
+	if ok {
+		delete(c.controls, messageId)
+	}
+	return controls
+}
+
+func (c *ldapConn) hasResponseControls() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.controls) > 0
+}
+
+func (c *ldapConn) Write(b []byte) (int, error) {
+	conn := c.getConn()
+	if !c.hasResponseControls() {
+		return conn.Write(b)
+	}
+
+	packet, err := ber.DecodePacketErr(b)
+	if err != nil || len(packet.Children) < 2 {
+		return conn.Write(b)
+	}
+
+	op := packet.Children[1]
+	messageId, ok := packet.Children[0].Value.(int64)
+	if !ok || op.ClassType != ber.ClassApplication || op.Tag != goldap.ApplicationSearchResultDone {
+		return conn.Write(b)
+	}
+
+	controls := c.popResponseControls(messageId)
+	if len(controls) == 0 {
+		return conn.Write(b)
+	}
+
+	controlsPacket := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "Controls")
+	for _, control := range controls {
+		controlsPacket.AppendChild(control.Encode())
+	}
+	packet.AppendChild(controlsPacket)
+
+	_, err = conn.Write(packet.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"strings"
+
+	ldap "github.com/casdoor/ldapserver"
+	"github.com/lor00x/goldap/message"
+)
+
+type ldapAttribute struct {
+	name   string
+	values []string
+}
+
+// ldapEntry is a directory entry that is built in memory, e.g. a group or an organizational unit
+type ldapEntry struct {
+	dn         string
+	attributes []*ldapAttribute
+}
+
+func newLdapEntry(dn string) *ldapEntry {
+	return &ldapEntry{dn: dn}
+}
+
+func (e *ldapEntry) addAttribute(name string, values ...string) {
+	for _, attribute := range e.attributes {
+		if strings.EqualFold(attribute.name, name) {
+			attribute.values = append(attribute.values, values...)
+			return
+		}
+	}
+	e.attributes = append(e.attributes, &ldapAttribute{name: name, values: values})
+}
+
+func (e *ldapEntry) getAttribute(name string) []string {
+	for _, attribute := range e.attributes {
+		if strings.EqualFold(attribute.name, name) {
+			return attribute.values
+		}
+	}
+	return nil
+}
+
+func (e *ldapEntry) removeAttribute(name string) {
+	for i, attribute := range e.attributes {
+		if strings.EqualFold(attribute.name, name) {
+			e.attributes = append(e.attributes[:i], e.attributes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *ldapEntry) toSearchResultEntry(attrs message.AttributeSelection) message.SearchResultEntry {
+	res := ldap.NewSearchResultEntry(e.dn)
+	isAll := isAllAttributesSelected(attrs, true)
+	for _, attribute := range e.attributes {
+		if !isAll && !isAttributeSelected(attrs, attribute.name) {
+			continue
+		}
+
+		values := []message.AttributeValue{}
+		for _, value := range attribute.values {
+			values = append(values, message.AttributeValue(value))
+		}
+		res.AddAttribute(message.AttributeDescription(attribute.name), values...)
+	}
+	return res
+}
+
+func isAttributeSelected(attrs message.AttributeSelection, name string) bool {
+	for _, attr := range attrs {
+		if strings.EqualFold(string(attr), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// getFilterObjectClasses returns the objectClass values asserted by equality anywhere in the filter
+func getFilterObjectClasses(filter message.Filter) []string {
+	res := []string{}
+	switch f := filter.(type) {
+	case message.FilterAnd:
+		for _, v := range f {
+			res = append(res, getFilterObjectClasses(v)...)
+		}
+	case message.FilterOr:
+		for _, v := range f {
+			res = append(res, getFilterObjectClasses(v)...)
+		}
+	case message.FilterEqualityMatch:
+		if strings.EqualFold(string(f.AttributeDesc()), "objectClass") {
+			res = append(res, strings.ToLower(string(f.AssertionValue())))
+		}
+	}
+	return res
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	ldap "github.com/casdoor/ldapserver"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/lor00x/goldap/message"
+)
+
+var ldapSupportedExtensions = []string{string(ldap.NoticeOfStartTLS), string(ldap.NoticeOfPasswordModify)}
+
+// passwordModifyRequest is the value of the Password Modify extended request (RFC 3062)
+type passwordModifyRequest struct {
+	userIdentity string
+	oldPassword  string
+	newPassword  string
+}
+
+func parsePasswordModifyRequest(value *message.OCTETSTRING) (*passwordModifyRequest, error) {
+	req := &passwordModifyRequest{}
+	if value == nil {
+		return req, nil
+	}
+
+	packet, err := ber.DecodePacketErr([]byte(*value))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range packet.Children {
+		if child.ClassType != ber.ClassContext {
+			return nil, fmt.Errorf("the password modify request is malformed")
+		}
+
+		switch child.Tag {
+		case 0:
+			req.userIdentity = child.Data.String()
+		case 1:
+			req.oldPassword = child.Data.String()
+		case 2:
+			req.newPassword = child.Data.String()
+		}
+	}
+	return req, nil
+}
+
+func handleStartTLS(w ldap.ResponseWriter, m *ldap.Message) {
+	res := ldap.NewExtendedResponse(ldap.LDAPResultSuccess)
+	res.SetResponseName(ldap.NoticeOfStartTLS)
+
+	conn := getLdapConn(m)
+	if conn == nil || conn.isTLS() {
+		res.SetResultCode(ldap.LDAPResultOperationsError)
+		res.SetDiagnosticMessage("TLS is already established")
+		w.Write(res)
+		return
+	}
+
+	ldapsCertId := conf.GetConfigString("ldapsCertId")
+	if ldapsCertId == "" {
+		res.SetResultCode(ldap.LDAPResultUnavailable)
+		res.SetDiagnosticMessage("StartTLS is not configured, please set ldapsCertId")
+		w.Write(res)
+		return
+	}
+
+	config, err := getTLSconfig(ldapsCertId)
+	if err != nil {
+		log.Printf("getTLSconfig() error: %s", err.Error())
+		res.SetResultCode(ldap.LDAPResultUnavailable)
+		res.SetDiagnosticMessage(err.Error())
+		w.Write(res)
+		return
+	}
+
+	w.Write(res)
+
+	err = conn.startTLS(config)
+	if err != nil {
+		log.Printf("StartTLS handshake error: %s", err.Error())
+		conn.Close()
+	}
+}
+
+func handlePasswordModify(w ldap.ResponseWriter, m *ldap.Message) {
+	res := ldap.NewExtendedResponse(ldap.LDAPResultSuccess)
+	if !m.Client.IsAuthenticated {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		w.Write(res)
+		return
+	}
+	if getServiceAccount(m) != nil {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		res.SetDiagnosticMessage("service accounts are read-only")
+		w.Write(res)
+		return
+	}
+	// the passwords are sent in clear text, so they are only accepted over LDAPS or after StartTLS
+	if !isConfidential(m) {
+		res.SetResultCode(ldap.LDAPResultConfidentialityRequired)
+		res.SetDiagnosticMessage("the password can only be modified over a TLS connection")
+		w.Write(res)
+		return
+	}
+
+	r := m.GetExtendedRequest()
+	req, err := parsePasswordModifyRequest(r.RequestValue())
+	if err != nil {
+		res.SetResultCode(ldap.LDAPResultProtocolError)
+		res.SetDiagnosticMessage(err.Error())
+		w.Write(res)
+		return
+	}
+
+	// the password is changed for the bound user when no user identity is given
+	name, org := m.Client.UserName, m.Client.OrgName
+	if req.userIdentity != "" {
+		name, org, err = getNameAndOrgFromDN(req.userIdentity)
+		if err != nil {
+			res.SetResultCode(ldap.LDAPResultInvalidDNSyntax)
+			res.SetDiagnosticMessage(err.Error())
+			w.Write(res)
+			return
+		}
+	}
+
+	if req.newPassword == "" {
+		res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+		res.SetDiagnosticMessage("the new password is required, password generation is not supported")
+		w.Write(res)
+		return
+	}
+
+	requestUserId := util.GetId(m.Client.OrgName, m.Client.UserName)
+	userId := util.GetId(org, name)
+	hasPermission, err := object.CheckUserPermission(requestUserId, userId, true, "en")
+	if !hasPermission {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		if err != nil {
+			res.SetDiagnosticMessage(err.Error())
+		}
+		w.Write(res)
+		return
+	}
+
+	user, err := object.GetUser(userId)
+	if err != nil {
+		log.Printf("GetUser() error: %s", err.Error())
+		res.SetResultCode(ldap.LDAPResultOperationsError)
+		res.SetDiagnosticMessage(err.Error())
+		w.Write(res)
+		return
+	}
+	if user == nil {
+		res.SetResultCode(ldap.LDAPResultNoSuchObject)
+		res.SetDiagnosticMessage(fmt.Sprintf("the user: %s doesn't exist", userId))
+		w.Write(res)
+		return
+	}
+
+	code, err := setUserPassword(user, req.oldPassword, req.newPassword, m.Client.IsOrgAdmin)
+	if err != nil {
+		log.Printf("setUserPassword() error: %s", err.Error())
+		res.SetResultCode(code)
+		res.SetDiagnosticMessage(err.Error())
+	}
+	w.Write(res)
+}
+
+// setUserPassword changes the user's password with the checks of the set password API, the old password
+// is only optional for admins
+func setUserPassword(user *object.User, oldPassword string, newPassword string, isAdmin bool) (int, error) {
+	if strings.Contains(newPassword, " ") {
+		return ldap.LDAPResultConstraintViolation, fmt.Errorf("new password cannot contain blank space")
+	}
+
+	if !isAdmin || oldPassword != "" {
+		var err error
+		if user.Ldap == "" {
+			err = object.CheckPassword(user, oldPassword, "en")
+		} else {
+			err = object.CheckLdapUserPassword(user, oldPassword, "en")
+		}
+		if err != nil {
+			return ldap.LDAPResultInvalidCredentials, err
+		}
+	}
+
+	msg := object.CheckPasswordComplexity(user, newPassword)
+	if msg != "" {
+		return ldap.LDAPResultConstraintViolation, errors.New(msg)
+	}
+
+	organization, err := object.GetOrganizationByUser(user)
+	if err != nil {
+		return ldap.LDAPResultOther, err
+	}
+	if organization == nil {
+		return ldap.LDAPResultOther, fmt.Errorf("the organization: %s is not found", user.Owner)
+	}
+
+	if user.Ldap != "" {
+		if isAdmin {
+			oldPassword = ""
+		}
+		err = object.ResetLdapPassword(user, oldPassword, newPassword, "en")
+	} else {
+		user.Password = newPassword
+		user.UpdateUserPassword(organization)
+		user.NeedUpdatePassword = false
+		user.LastChangePasswordTime = util.GetCurrentTime()
+		_, err = object.UpdateUser(user.GetId(), user, []string{"password", "need_update_password", "password_type", "last_change_password_time"}, false)
+	}
+	if err != nil {
+		return ldap.LDAPResultOther, err
+	}
+	return ldap.LDAPResultSuccess, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"testing"
+
+	"github.com/casdoor/casdoor/object"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/lor00x/goldap/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePasswordModifyRequest(t *testing.T) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Password Modify Request")
+	packet.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 0, "cn=alice,ou=built-in,dc=example,dc=com", "User Identity"))
+	packet.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 2, "new-password", "New Password"))
+	value := message.OCTETSTRING(packet.Bytes())
+
+	req, err := parsePasswordModifyRequest(&value)
+	assert.Nil(t, err)
+	assert.Equal(t, "cn=alice,ou=built-in,dc=example,dc=com", req.userIdentity)
+	assert.Equal(t, "", req.oldPassword)
+	assert.Equal(t, "new-password", req.newPassword)
+
+	req, err = parsePasswordModifyRequest(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", req.userIdentity)
+}
+
+func TestSetUserAttribute(t *testing.T) {
+	user := &object.User{}
+	column, err := setUserAttribute(user, "mail", "alice@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "email", column)
+	assert.Equal(t, "alice@example.com", user.Email)
+
+	_, err = setUserAttribute(user, "uidNumber", "1000")
+	assert.NotNil(t, err)
+	assert.True(t, isGeneratedAttribute("uidNumber"))
+
+	_, err = getPasswordValue([]message.AttributeValue{"{SSHA}abc"})
+	assert.NotNil(t, err)
+	password, err := getPasswordValue([]message.AttributeValue{"secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", password)
+}
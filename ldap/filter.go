@@ -0,0 +1,210 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"strconv"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/lor00x/goldap/message"
+)
+
+// filterResult is the three-valued result of a filter defined in RFC 4511 section 4.5.1.7
+type filterResult int
+
+const (
+	filterFalse filterResult = iota
+	filterTrue
+	filterUndefined
+)
+
+// matchFilter evaluates the search filter against the entry, an entry only matches a filter that evaluates to true
+func (e *ldapEntry) matchFilter(filter message.Filter) bool {
+	return e.evaluateFilter(filter) == filterTrue
+}
+
+// evaluateFilter evaluates the filter with the three-valued logic of RFC 4511, an assertion on an attribute the entry
+// doesn't have is undefined so that a NOT over it stays undefined instead of matching the entry
+func (e *ldapEntry) evaluateFilter(filter message.Filter) filterResult {
+	switch f := filter.(type) {
+	case message.FilterAnd:
+		res := filterTrue
+		for _, v := range f {
+			switch e.evaluateFilter(v) {
+			case filterFalse:
+				return filterFalse
+			case filterUndefined:
+				res = filterUndefined
+			}
+		}
+		return res
+	case message.FilterOr:
+		res := filterFalse
+		for _, v := range f {
+			switch e.evaluateFilter(v) {
+			case filterTrue:
+				return filterTrue
+			case filterUndefined:
+				res = filterUndefined
+			}
+		}
+		return res
+	case message.FilterNot:
+		switch e.evaluateFilter(f.Filter) {
+		case filterTrue:
+			return filterFalse
+		case filterFalse:
+			return filterTrue
+		default:
+			return filterUndefined
+		}
+	case message.FilterEqualityMatch:
+		attr := string(f.AttributeDesc())
+		if stringInSlice(strings.ToLower(attr), ldapDnAttributes) {
+			return e.matchValues(attr, func(value string) bool {
+				return isDnValueMatched(value, string(f.AssertionValue()))
+			})
+		}
+		return e.matchValues(attr, func(value string) bool {
+			return strings.EqualFold(value, string(f.AssertionValue()))
+		})
+	case message.FilterApproxMatch:
+		return e.matchValues(string(f.AttributeDesc()), func(value string) bool {
+			return normalizeApproxValue(value) == normalizeApproxValue(string(f.AssertionValue()))
+		})
+	case message.FilterGreaterOrEqual:
+		return e.matchValues(string(f.AttributeDesc()), func(value string) bool {
+			return compareValues(value, string(f.AssertionValue())) >= 0
+		})
+	case message.FilterLessOrEqual:
+		return e.matchValues(string(f.AttributeDesc()), func(value string) bool {
+			return compareValues(value, string(f.AssertionValue())) <= 0
+		})
+	case message.FilterPresent:
+		if strings.EqualFold(string(f), "objectClass") || len(e.getAttribute(string(f))) > 0 {
+			return filterTrue
+		}
+		return filterFalse
+	case message.FilterSubstrings:
+		return e.matchValues(string(f.Type_()), func(value string) bool {
+			return matchSubstrings(value, f.Substrings())
+		})
+	default:
+		// extensible match is not supported, it is evaluated as undefined
+		return filterUndefined
+	}
+}
+
+var ldapDnAttributes = []string{"member", "memberof", "uniquemember"}
+
+// isDnValueMatched compares the DNs regardless of case and spacing, a group can also be asserted by its id like "built-in/dev"
+func isDnValueMatched(value string, assertion string) bool {
+	if !strings.Contains(assertion, "=") {
+		groupId, ok := getGroupIdFromDn(value)
+		return ok && groupId == assertion
+	}
+
+	parsedValue, err := goldap.ParseDN(value)
+	if err != nil {
+		return strings.EqualFold(value, assertion)
+	}
+	parsedAssertion, err := goldap.ParseDN(assertion)
+	if err != nil {
+		return strings.EqualFold(value, assertion)
+	}
+	return parsedValue.EqualFold(parsedAssertion)
+}
+
+// matchValues is undefined when the entry doesn't have the attribute, otherwise it is true if a value matches
+func (e *ldapEntry) matchValues(name string, match func(value string) bool) filterResult {
+	values := e.getAttribute(name)
+	if len(values) == 0 {
+		return filterUndefined
+	}
+
+	for _, value := range values {
+		if match(value) {
+			return filterTrue
+		}
+	}
+	return filterFalse
+}
+
+func normalizeApproxValue(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
+
+// compareValues compares integers numerically and other values as case-insensitive strings
+func compareValues(a string, b string) int {
+	x, err1 := strconv.ParseInt(a, 10, 64)
+	y, err2 := strconv.ParseInt(b, 10, 64)
+	if err1 == nil && err2 == nil {
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func matchSubstrings(value string, substrings []message.Substring) bool {
+	value = strings.ToLower(value)
+	for _, substring := range substrings {
+		switch s := substring.(type) {
+		case message.SubstringInitial:
+			prefix := strings.ToLower(string(s))
+			if !strings.HasPrefix(value, prefix) {
+				return false
+			}
+			value = value[len(prefix):]
+		case message.SubstringAny:
+			part := strings.ToLower(string(s))
+			index := strings.Index(value, part)
+			if index == -1 {
+				return false
+			}
+			value = value[index+len(part):]
+		case message.SubstringFinal:
+			if !strings.HasSuffix(value, strings.ToLower(string(s))) {
+				return false
+			}
+			value = ""
+		}
+	}
+	return true
+}
+
+func containsNotFilter(filter message.Filter) bool {
+	switch f := filter.(type) {
+	case message.FilterAnd:
+		for _, v := range f {
+			if containsNotFilter(v) {
+				return true
+			}
+		}
+	case message.FilterOr:
+		for _, v := range f {
+			if containsNotFilter(v) {
+				return true
+			}
+		}
+	case message.FilterNot:
+		return true
+	}
+	return false
+}
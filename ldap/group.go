@@ -0,0 +1,409 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	ldap "github.com/casdoor/ldapserver"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+const ldapGroupsOu = "groups"
+
+var ldapGroupObjectClasses = []string{"groupofnames", "groupofuniquenames", "posixgroup", "group"}
+
+// getDcSuffix returns the domain components of the DN, e.g. "dc=example,dc=com"
+func getDcSuffix(dn string) string {
+	parsedDn, err := goldap.ParseDN(dn)
+	if err != nil {
+		return ""
+	}
+
+	dcs := []string{}
+	for _, rdn := range parsedDn.RDNs {
+		for _, attribute := range rdn.Attributes {
+			if strings.EqualFold(attribute.Type, "dc") {
+				dcs = append(dcs, fmt.Sprintf("dc=%s", attribute.Value))
+			}
+		}
+	}
+	return strings.Join(dcs, ",")
+}
+
+func joinDn(rdns ...string) string {
+	res := []string{}
+	for _, rdn := range rdns {
+		if rdn != "" {
+			res = append(res, rdn)
+		}
+	}
+	return strings.Join(res, ",")
+}
+
+func getOrgDn(org string, suffix string) string {
+	return joinDn(fmt.Sprintf("ou=%s", goldap.EscapeDN(org)), suffix)
+}
+
+func getUserDn(user *object.User, suffix string) string {
+	return joinDn(fmt.Sprintf("uid=%s,cn=%s", user.Id, goldap.EscapeDN(user.Name)), getOrgDn(user.Owner, suffix))
+}
+
+func getGroupDn(owner string, name string, suffix string) string {
+	return joinDn(fmt.Sprintf("cn=%s,ou=%s", goldap.EscapeDN(name), ldapGroupsOu), getOrgDn(owner, suffix))
+}
+
+// getGroupIdFromDn converts a group DN like "cn=dev,ou=groups,ou=built-in,dc=example,dc=com" to the group id "built-in/dev"
+func getGroupIdFromDn(dn string) (string, bool) {
+	parsedDn, err := goldap.ParseDN(dn)
+	if err != nil || len(parsedDn.RDNs) < 3 {
+		return "", false
+	}
+
+	cn, isGroupsOu, org := "", false, ""
+	for i, rdn := range parsedDn.RDNs {
+		for _, attribute := range rdn.Attributes {
+			if i == 0 && strings.EqualFold(attribute.Type, "cn") {
+				cn = attribute.Value
+			} else if strings.EqualFold(attribute.Type, "ou") {
+				if !isGroupsOu && strings.EqualFold(attribute.Value, ldapGroupsOu) {
+					isGroupsOu = true
+				} else {
+					org = attribute.Value
+				}
+			}
+		}
+	}
+
+	if cn == "" || !isGroupsOu || org == "" {
+		return "", false
+	}
+	return util.GetId(org, cn), true
+}
+
+func getGidNumber(groupId string) string {
+	return fmt.Sprintf("%v", hash(groupId))
+}
+
+func isGroupSearch(baseDn string, objectClasses []string) bool {
+	for _, objectClass := range objectClasses {
+		if stringInSlice(objectClass, ldapGroupObjectClasses) {
+			return true
+		}
+	}
+
+	parsedDn, err := goldap.ParseDN(baseDn)
+	if err != nil || len(parsedDn.RDNs) == 0 {
+		return false
+	}
+	for _, attribute := range parsedDn.RDNs[0].Attributes {
+		if strings.EqualFold(attribute.Type, "ou") && strings.EqualFold(attribute.Value, ldapGroupsOu) {
+			return true
+		}
+	}
+
+	_, ok := getGroupIdFromDn(baseDn)
+	return ok
+}
+
+func isOrgSearch(objectClasses []string) bool {
+	return stringInSlice("organizationalunit", objectClasses)
+}
+
+// getAncestorGroupIds returns the ids of the group and all the groups it is nested in
+func getAncestorGroupIds(groupId string, groupMap map[string]*object.Group) []string {
+	res := []string{}
+	visited := map[string]bool{}
+	for groupId != "" && !visited[groupId] {
+		group, ok := groupMap[groupId]
+		if !ok {
+			break
+		}
+
+		visited[groupId] = true
+		res = append(res, groupId)
+		if group.IsTopGroup || group.ParentId == "" {
+			break
+		}
+		groupId = util.GetId(group.Owner, group.ParentId)
+	}
+	return res
+}
+
+// getUserMemberOf returns the DNs of the groups the user belongs to, including the parent groups of nested groups
+func getUserMemberOf(user *object.User, groupMap map[string]*object.Group, suffix string) []string {
+	res := []string{}
+	visited := map[string]bool{}
+	for _, groupId := range user.Groups {
+		ancestorIds := getAncestorGroupIds(groupId, groupMap)
+		if len(ancestorIds) == 0 {
+			ancestorIds = []string{groupId}
+		}
+
+		for _, ancestorId := range ancestorIds {
+			if visited[ancestorId] {
+				continue
+			}
+			visited[ancestorId] = true
+
+			owner, name := util.GetOwnerAndNameFromId(ancestorId)
+			res = append(res, getGroupDn(owner, name, suffix))
+		}
+	}
+	return res
+}
+
+// getDescendantGroupIds returns the ids of the group and all the groups nested in it
+func getDescendantGroupIds(groupId string) []string {
+	owner, _ := util.GetOwnerAndNameFromId(groupId)
+	groups, err := object.GetGroups(owner)
+	if err != nil {
+		panic(err)
+	}
+
+	res := []string{groupId}
+	groupMap := getGroupMap(groups)
+	for _, group := range groups {
+		id := group.GetId()
+		if id == groupId {
+			continue
+		}
+
+		for _, ancestorId := range getAncestorGroupIds(id, groupMap) {
+			if ancestorId == groupId {
+				res = append(res, id)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func getGroupMap(groups []*object.Group) map[string]*object.Group {
+	groupMap := map[string]*object.Group{}
+	for _, group := range groups {
+		groupMap[group.GetId()] = group
+	}
+	return groupMap
+}
+
+// buildGroupEntries builds the groupOfNames and posixGroup entries of an organization. The member attribute
+// lists the direct members and the nested child groups, memberUid lists all the users transitively.
+func buildGroupEntries(groups []*object.Group, users []*object.User, suffix string) []*ldapEntry {
+	groupMap := getGroupMap(groups)
+
+	directMembers := map[string][]string{}
+	transitiveUids := map[string][]string{}
+	for _, user := range users {
+		for _, groupId := range user.Groups {
+			directMembers[groupId] = append(directMembers[groupId], getUserDn(user, suffix))
+		}
+
+		for _, groupDn := range getUserMemberOf(user, groupMap, suffix) {
+			groupId, ok := getGroupIdFromDn(groupDn)
+			if ok {
+				transitiveUids[groupId] = append(transitiveUids[groupId], user.Name)
+			}
+		}
+	}
+
+	childGroups := map[string][]string{}
+	for _, group := range groups {
+		if group.IsTopGroup || group.ParentId == "" {
+			continue
+		}
+		parentId := util.GetId(group.Owner, group.ParentId)
+		childGroups[parentId] = append(childGroups[parentId], getGroupDn(group.Owner, group.Name, suffix))
+	}
+
+	entries := []*ldapEntry{}
+	for _, group := range groups {
+		groupId := group.GetId()
+		groupDn := getGroupDn(group.Owner, group.Name, suffix)
+
+		e := newLdapEntry(groupDn)
+		e.addAttribute("objectClass", "top", "groupOfNames", "posixGroup")
+		e.addAttribute("cn", group.Name)
+		e.addAttribute("gidNumber", getGidNumber(groupId))
+		if group.DisplayName != "" {
+			e.addAttribute("displayName", group.DisplayName)
+			e.addAttribute("description", group.DisplayName)
+		}
+
+		members := append(directMembers[groupId], childGroups[groupId]...)
+		sort.Strings(members)
+		if len(members) > 0 {
+			e.addAttribute("member", members...)
+		}
+
+		memberUids := transitiveUids[groupId]
+		sort.Strings(memberUids)
+		if len(memberUids) > 0 {
+			e.addAttribute("memberUid", memberUids...)
+		}
+
+		if !group.IsTopGroup && group.ParentId != "" {
+			e.addAttribute(ldapMemberOfAttr, getGroupDn(group.Owner, group.ParentId, suffix))
+		}
+
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func buildOrgEntry(organization *object.Organization, suffix string) *ldapEntry {
+	e := newLdapEntry(getOrgDn(organization.Name, suffix))
+	e.addAttribute("objectClass", "top", "organizationalUnit")
+	e.addAttribute("ou", organization.Name)
+	if organization.DisplayName != "" {
+		e.addAttribute("description", organization.DisplayName)
+	}
+	return e
+}
+
+func buildGroupsOuEntry(org string, suffix string) *ldapEntry {
+	e := newLdapEntry(joinDn(fmt.Sprintf("ou=%s", ldapGroupsOu), getOrgDn(org, suffix)))
+	e.addAttribute("objectClass", "top", "organizationalUnit")
+	e.addAttribute("ou", ldapGroupsOu)
+	return e
+}
+
+// getOrgFromDn returns the organization in the DN, e.g. "built-in" for "cn=dev,ou=groups,ou=built-in,dc=example,dc=com"
+func getOrgFromDn(dn string) string {
+	parsedDn, err := goldap.ParseDN(dn)
+	if err != nil {
+		return ""
+	}
+
+	org := ""
+	for _, rdn := range parsedDn.RDNs {
+		for _, attribute := range rdn.Attributes {
+			if strings.EqualFold(attribute.Type, "ou") && !strings.EqualFold(attribute.Value, ldapGroupsOu) {
+				org = attribute.Value
+			}
+		}
+	}
+	return org
+}
+
+// getSearchOrgs returns the organizations the client can search under the base DN
+func getSearchOrgs(m *ldap.Message, baseDn string) ([]string, int) {
+	org := getOrgFromDn(baseDn)
+	account := getServiceAccount(m)
+	if org == "" || org == "*" {
+		if account != nil {
+			orgs, err := getServiceAccountOrgs(account)
+			if err != nil {
+				log.Printf("getServiceAccountOrgs() error: %s", err.Error())
+				return nil, ldap.LDAPResultOperationsError
+			}
+			return orgs, ldap.LDAPResultSuccess
+		}
+		if !m.Client.IsGlobalAdmin {
+			return []string{m.Client.OrgName}, ldap.LDAPResultSuccess
+		}
+
+		organizations, err := object.GetOrganizations("admin")
+		if err != nil {
+			panic(err)
+		}
+
+		orgs := []string{}
+		for _, organization := range organizations {
+			orgs = append(orgs, organization.Name)
+		}
+		return orgs, ldap.LDAPResultSuccess
+	}
+
+	if account != nil {
+		if !account.IsOrganizationAllowed(org) {
+			return nil, ldap.LDAPResultInsufficientAccessRights
+		}
+	} else if !m.Client.IsGlobalAdmin && org != m.Client.OrgName {
+		return nil, ldap.LDAPResultInsufficientAccessRights
+	}
+	return []string{org}, ldap.LDAPResultSuccess
+}
+
+func GetFilteredGroups(m *ldap.Message) ([]*ldapEntry, int) {
+	r := m.GetSearchRequest()
+	baseDn := string(r.BaseObject())
+	suffix := getDcSuffix(baseDn)
+
+	orgs, code := getSearchOrgs(m, baseDn)
+	if code != ldap.LDAPResultSuccess {
+		return nil, code
+	}
+
+	baseGroupId, isGroupDn := getGroupIdFromDn(baseDn)
+
+	res := []*ldapEntry{}
+	for _, org := range orgs {
+		groups, err := object.GetGroups(org)
+		if err != nil {
+			panic(err)
+		}
+
+		users, err := object.GetUsers(org)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, e := range buildGroupEntries(groups, users, suffix) {
+			if isGroupDn {
+				groupId, _ := getGroupIdFromDn(e.dn)
+				if groupId != baseGroupId {
+					continue
+				}
+			}
+
+			if e.matchFilter(r.Filter()) {
+				res = append(res, e)
+			}
+		}
+	}
+	return res, ldap.LDAPResultSuccess
+}
+
+func GetFilteredOrgs(m *ldap.Message) ([]*ldapEntry, int) {
+	r := m.GetSearchRequest()
+	baseDn := string(r.BaseObject())
+	suffix := getDcSuffix(baseDn)
+
+	orgs, code := getSearchOrgs(m, baseDn)
+	if code != ldap.LDAPResultSuccess {
+		return nil, code
+	}
+
+	organizations, err := object.GetOrganizations("admin", orgs...)
+	if err != nil {
+		panic(err)
+	}
+
+	res := []*ldapEntry{}
+	for _, organization := range organizations {
+		for _, e := range []*ldapEntry{buildOrgEntry(organization, suffix), buildGroupsOuEntry(organization.Name, suffix)} {
+			if e.matchFilter(r.Filter()) {
+				res = append(res, e)
+			}
+		}
+	}
+	return res, ldap.LDAPResultSuccess
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"testing"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/lor00x/goldap/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestFilter(t *testing.T, filter string) message.Filter {
+	searchRequest, err := buildLdapSearchRequest(filter)
+	if err != nil {
+		assert.FailNow(t, "Unable to create searchRequest", err)
+	}
+	m, err := message.ReadLDAPMessage(message.NewBytes(0, searchRequest.Bytes()))
+	if err != nil {
+		assert.FailNow(t, "Unable to create searchRequest", err)
+	}
+	req := m.ProtocolOp().(message.SearchRequest)
+	return req.Filter()
+}
+
+func TestGetGroupIdFromDn(t *testing.T) {
+	groupId, ok := getGroupIdFromDn("cn=dev,ou=groups,ou=built-in,dc=example,dc=com")
+	assert.True(t, ok)
+	assert.Equal(t, "built-in/dev", groupId)
+
+	_, ok = getGroupIdFromDn("uid=1,cn=alice,ou=built-in,dc=example,dc=com")
+	assert.False(t, ok)
+
+	assert.Equal(t, "dc=example,dc=com", getDcSuffix("ou=built-in,dc=example,dc=com"))
+	assert.Equal(t, "built-in", getOrgFromDn("ou=groups,ou=built-in,dc=example,dc=com"))
+}
+
+func TestBuildGroupEntries(t *testing.T) {
+	groups := []*object.Group{
+		{Owner: "built-in", Name: "eng", IsTopGroup: true},
+		{Owner: "built-in", Name: "dev", ParentId: "eng"},
+	}
+	users := []*object.User{
+		{Owner: "built-in", Name: "alice", Id: "1", Groups: []string{"built-in/dev"}},
+		{Owner: "built-in", Name: "bob", Id: "2", Groups: []string{"built-in/eng"}},
+	}
+
+	entries := buildGroupEntries(groups, users, "dc=example,dc=com")
+	assert.Equal(t, 2, len(entries))
+
+	eng, dev := entries[0], entries[1]
+	assert.Equal(t, "cn=eng,ou=groups,ou=built-in,dc=example,dc=com", eng.dn)
+	assert.Equal(t, []string{"alice", "bob"}, eng.getAttribute("memberUid"))
+	assert.Equal(t, []string{
+		"cn=dev,ou=groups,ou=built-in,dc=example,dc=com",
+		"uid=2,cn=bob,ou=built-in,dc=example,dc=com",
+	}, eng.getAttribute("member"))
+	assert.Equal(t, []string{"alice"}, dev.getAttribute("memberUid"))
+	assert.Equal(t, []string{"cn=eng,ou=groups,ou=built-in,dc=example,dc=com"}, dev.getAttribute(ldapMemberOfAttr))
+	assert.NotEqual(t, eng.getAttribute("gidNumber"), dev.getAttribute("gidNumber"))
+
+	assert.True(t, eng.matchFilter(getTestFilter(t, "(&(objectClass=posixGroup)(memberUid=alice))")))
+	assert.True(t, dev.matchFilter(getTestFilter(t, "(cn=de*)")))
+	assert.False(t, dev.matchFilter(getTestFilter(t, "(memberUid=bob)")))
+
+	user := users[0]
+	memberOf := getUserMemberOf(user, getGroupMap(groups), "dc=example,dc=com")
+	assert.Equal(t, []string{
+		"cn=dev,ou=groups,ou=built-in,dc=example,dc=com",
+		"cn=eng,ou=groups,ou=built-in,dc=example,dc=com",
+	}, memberOf)
+}
@@ -0,0 +1,433 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	ldap "github.com/casdoor/ldapserver"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/lor00x/goldap/message"
+)
+
+const ldapSubschemaDn = "cn=Subschema"
+
+var ldapUserObjectClasses = []string{"inetorgperson", "posixaccount", "person", "organizationalperson", "account", "user"}
+
+var ldapSupportedControls = []string{goldap.ControlTypePaging}
+
+var ldapSchemaAttributeTypes = []string{
+	"( 2.5.4.0 NAME 'objectClass' EQUALITY objectIdentifierMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )",
+	"( 2.5.4.3 NAME 'cn' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )",
+	"( 2.5.4.11 NAME 'ou' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )",
+	"( 2.5.4.12 NAME 'title' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )",
+	"( 2.5.4.13 NAME 'description' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )",
+	"( 2.5.4.31 NAME 'member' EQUALITY distinguishedNameMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.12 )",
+	"( 2.5.4.35 NAME 'userPassword' EQUALITY octetStringMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.40 )",
+	"( 0.9.2342.19200300.100.1.1 NAME 'uid' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )",
+	"( 0.9.2342.19200300.100.1.3 NAME 'mail' EQUALITY caseIgnoreIA5Match SUBSTR caseIgnoreIA5SubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.26 )",
+	"( 0.9.2342.19200300.100.1.41 NAME 'mobile' EQUALITY telephoneNumberMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.50 )",
+	"( 1.2.840.113549.1.9.1 NAME 'email' EQUALITY caseIgnoreIA5Match SUBSTR caseIgnoreIA5SubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.26 )",
+	"( 2.16.840.1.113730.3.1.241 NAME 'displayName' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 SINGLE-VALUE )",
+	"( 1.3.6.1.1.1.1.0 NAME 'uidNumber' EQUALITY integerMatch ORDERING integerOrderingMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 SINGLE-VALUE )",
+	"( 1.3.6.1.1.1.1.1 NAME 'gidNumber' EQUALITY integerMatch ORDERING integerOrderingMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 SINGLE-VALUE )",
+	"( 1.3.6.1.1.1.1.3 NAME 'homeDirectory' EQUALITY caseExactIA5Match SYNTAX 1.3.6.1.4.1.1466.115.121.1.26 SINGLE-VALUE )",
+	"( 1.3.6.1.1.1.1.12 NAME 'memberUid' EQUALITY caseExactMatch SUBSTR caseExactSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.26 )",
+	"( 1.2.840.113556.1.2.102 NAME 'memberOf' EQUALITY distinguishedNameMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.12 )",
+	"( 2.5.21.5 NAME 'attributeTypes' EQUALITY objectIdentifierFirstComponentMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.3 USAGE directoryOperation )",
+	"( 2.5.21.6 NAME 'objectClasses' EQUALITY objectIdentifierFirstComponentMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.37 USAGE directoryOperation )",
+}
+
+var ldapSchemaObjectClasses = []string{
+	"( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )",
+	"( 2.5.6.5 NAME 'organizationalUnit' SUP top STRUCTURAL MUST ou MAY description )",
+	"( 2.5.6.9 NAME 'groupOfNames' SUP top STRUCTURAL MUST cn MAY ( member $ description $ displayName $ memberOf ) )",
+	"( 2.16.840.1.113730.3.2.2 NAME 'inetOrgPerson' SUP top STRUCTURAL MUST cn MAY ( uid $ mail $ email $ mobile $ displayName $ title $ userPassword $ memberOf ) )",
+	"( 1.3.6.1.1.1.2.0 NAME 'posixAccount' SUP top AUXILIARY MUST ( cn $ uid $ uidNumber $ gidNumber $ homeDirectory ) MAY ( userPassword $ description ) )",
+	"( 1.3.6.1.1.1.2.2 NAME 'posixGroup' SUP top AUXILIARY MUST ( cn $ gidNumber ) MAY ( memberUid $ description ) )",
+	"( 2.5.20.1 NAME 'subschema' AUXILIARY MAY ( attributeTypes $ objectClasses ) )",
+}
+
+func isRootDseSearch(r message.SearchRequest) bool {
+	return r.BaseObject() == "" && r.Scope() == message.SearchRequestScopeBaseObject
+}
+
+func buildRootDseEntry() *ldapEntry {
+	e := newLdapEntry("")
+	e.addAttribute("objectClass", "top")
+	if baseDn := conf.GetConfigString("ldapBaseDn"); baseDn != "" {
+		e.addAttribute("namingContexts", baseDn)
+	}
+	e.addAttribute("supportedLDAPVersion", "3")
+	e.addAttribute("supportedControl", ldapSupportedControls...)
+	e.addAttribute("supportedExtension", ldapSupportedExtensions...)
+	e.addAttribute("subschemaSubentry", ldapSubschemaDn)
+	e.addAttribute("vendorName", "Casdoor")
+	return e
+}
+
+func buildSubschemaEntry() *ldapEntry {
+	e := newLdapEntry(ldapSubschemaDn)
+	e.addAttribute("objectClass", "top", "subentry", "subschema")
+	e.addAttribute("cn", "Subschema")
+	e.addAttribute("attributeTypes", ldapSchemaAttributeTypes...)
+	e.addAttribute("objectClasses", ldapSchemaObjectClasses...)
+	return e
+}
+
+func buildUserEntry(user *object.User, groupMap map[string]*object.Group, suffix string, attrs message.AttributeSelection) *ldapEntry {
+	e := newLdapEntry(getUserDn(user, suffix))
+	e.addAttribute("objectClass", "top", "inetOrgPerson", "posixAccount")
+	e.addAttribute("cn", user.Name)
+	e.addAttribute("uid", user.Id)
+	if user.Name != user.Id {
+		e.addAttribute("uid", user.Name)
+	}
+
+	uidNumberStr := fmt.Sprintf("%v", hash(user.Name))
+	e.addAttribute("uidNumber", uidNumberStr)
+	e.addAttribute("gidNumber", uidNumberStr)
+	e.addAttribute("homeDirectory", "/home/"+user.Name)
+
+	memberOf := getUserMemberOf(user, groupMap, suffix)
+	if len(memberOf) > 0 {
+		e.addAttribute(ldapMemberOfAttr, memberOf...)
+	}
+
+	names := []string{}
+	for _, name := range AdditionalLdapAttributes {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// the password hash is only returned when it is requested
+		if name == "userPassword" && !isAllAttributesSelected(attrs, false) && !isAttributeSelected(attrs, name) {
+			continue
+		}
+
+		value := string(getAttribute(name, user))
+		if value != "" {
+			e.addAttribute(name, value)
+		}
+	}
+	return e
+}
+
+func isAllAttributesSelected(attrs message.AttributeSelection, isEmptyAll bool) bool {
+	if len(attrs) == 0 {
+		return isEmptyAll
+	}
+
+	for _, attr := range attrs {
+		if string(attr) == "*" || string(attr) == "+" {
+			return true
+		}
+	}
+	return false
+}
+
+// isEntryInScope checks the entry DN against the base DN and the search scope, a base DN with a wildcard
+// organization like "ou=*,dc=example,dc=com" matches all entries
+func isEntryInScope(dn string, baseDn string, scope int) bool {
+	if strings.Contains(baseDn, "*") {
+		return true
+	}
+
+	parsedDn, err := goldap.ParseDN(dn)
+	if err != nil {
+		return false
+	}
+	parsedBaseDn, err := goldap.ParseDN(baseDn)
+	if err != nil {
+		return false
+	}
+
+	switch scope {
+	case message.SearchRequestScopeBaseObject:
+		return parsedBaseDn.EqualFold(parsedDn)
+	case message.SearchRequestSingleLevel:
+		return len(parsedDn.RDNs) == len(parsedBaseDn.RDNs)+1 && parsedBaseDn.AncestorOfFold(parsedDn)
+	default:
+		return parsedBaseDn.EqualFold(parsedDn) || parsedBaseDn.AncestorOfFold(parsedDn)
+	}
+}
+
+func isEntryMatched(e *ldapEntry, r message.SearchRequest) bool {
+	return isEntryInScope(e.dn, string(r.BaseObject()), int(r.Scope())) && e.matchFilter(r.Filter())
+}
+
+func filterSearchEntries(entries []*ldapEntry, r message.SearchRequest) []*ldapEntry {
+	res := []*ldapEntry{}
+	for _, e := range entries {
+		if isEntryMatched(e, r) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func hasObjectClass(objectClasses []string, list []string) bool {
+	for _, objectClass := range objectClasses {
+		if stringInSlice(objectClass, list) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSearchEntries collects the organizations, groups and users the search may return. The objectClass
+// assertions of the filter are used to skip the kinds of entries that can't match.
+func getSearchEntries(m *ldap.Message) ([]*ldapEntry, int) {
+	r := m.GetSearchRequest()
+	baseDn := string(r.BaseObject())
+	if strings.EqualFold(baseDn, ldapSubschemaDn) {
+		return filterSearchEntries([]*ldapEntry{buildSubschemaEntry()}, r), ldap.LDAPResultSuccess
+	}
+
+	objectClasses := getFilterObjectClasses(r.Filter())
+	searchUsers := hasObjectClass(objectClasses, ldapUserObjectClasses)
+	searchGroups := hasObjectClass(objectClasses, ldapGroupObjectClasses)
+	searchOrgs := isOrgSearch(objectClasses)
+	if !searchUsers && !searchGroups && !searchOrgs {
+		searchUsers, searchGroups, searchOrgs = true, true, true
+	}
+	if isGroupSearch(baseDn, nil) {
+		searchUsers = false
+	}
+
+	entries := []*ldapEntry{}
+	if searchOrgs {
+		orgEntries, code := GetFilteredOrgs(m)
+		if code != ldap.LDAPResultSuccess {
+			return nil, code
+		}
+		entries = append(entries, filterSearchEntries(orgEntries, r)...)
+	}
+
+	if searchGroups {
+		groupEntries, code := GetFilteredGroups(m)
+		if code != ldap.LDAPResultSuccess {
+			return nil, code
+		}
+		entries = append(entries, filterSearchEntries(groupEntries, r)...)
+	}
+
+	if searchUsers {
+		userEntries, code := getUserEntries(m)
+		if code != ldap.LDAPResultSuccess {
+			// a base DN or a filter without users is not an error when the organizations or groups are searched too
+			isOtherSearched := searchGroups || searchOrgs
+			if !isOtherSearched || (code != ldap.LDAPResultNoSuchObject && code != ldap.LDAPResultInsufficientAccessRights) {
+				return nil, code
+			}
+		}
+		entries = append(entries, userEntries...)
+	}
+
+	if account := getServiceAccount(m); account != nil {
+		entries = filterServiceAccountEntries(account, entries)
+	}
+	return entries, ldap.LDAPResultSuccess
+}
+
+func getUserEntries(m *ldap.Message) ([]*ldapEntry, int) {
+	r := m.GetSearchRequest()
+	users, isTagSearch, code := getFilteredUsers(m)
+	if code != ldap.LDAPResultSuccess {
+		return nil, code
+	}
+
+	suffix := getDcSuffix(string(r.BaseObject()))
+	groupMaps := map[string]map[string]*object.Group{}
+	res := []*ldapEntry{}
+	for _, user := range users {
+		groupMap, ok := groupMaps[user.Owner]
+		if !ok {
+			groups, err := object.GetGroups(user.Owner)
+			if err != nil {
+				panic(err)
+			}
+
+			groupMap = getGroupMap(groups)
+			groupMaps[user.Owner] = groupMap
+		}
+
+		e := buildUserEntry(user, groupMap, suffix, r.Attributes())
+		// the users of a tag are searched with the tag as the base DN, e.g. "cn=staff,ou=built-in,dc=example,dc=com"
+		if isTagSearch || isEntryMatched(e, r) {
+			res = append(res, e)
+		}
+	}
+	return res, ldap.LDAPResultSuccess
+}
+
+// getPagingControl returns the Simple Paged Results control (RFC 2696) of the request, nil if there is none
+func getPagingControl(m *ldap.Message) (*goldap.ControlPaging, error) {
+	controls := m.Controls()
+	if controls == nil {
+		return nil, nil
+	}
+
+	for _, control := range *controls {
+		if string(control.ControlType()) != goldap.ControlTypePaging {
+			continue
+		}
+
+		paging := &goldap.ControlPaging{}
+		value := control.ControlValue()
+		if value == nil {
+			return nil, fmt.Errorf("the paged results control has no value")
+		}
+
+		packet, err := ber.DecodePacketErr([]byte(*value))
+		if err != nil {
+			return nil, err
+		}
+		if len(packet.Children) < 2 {
+			return nil, fmt.Errorf("the paged results control is malformed")
+		}
+
+		size, ok := packet.Children[0].Value.(int64)
+		if !ok || size < 0 {
+			return nil, fmt.Errorf("the paged results control has an invalid size")
+		}
+		paging.PagingSize = uint32(size)
+		paging.Cookie = packet.Children[1].Data.Bytes()
+		return paging, nil
+	}
+	return nil, nil
+}
+
+// getSearchPage returns the entries of the page and the cookie of the next page, the cookie is the offset
+// of the next entry and is empty for the last page
+func getSearchPage(entries []*ldapEntry, paging *goldap.ControlPaging) ([]*ldapEntry, string, error) {
+	offset := 0
+	if len(paging.Cookie) > 0 {
+		var err error
+		offset, err = strconv.Atoi(string(paging.Cookie))
+		if err != nil || offset < 0 || offset > len(entries) {
+			return nil, "", fmt.Errorf("the paged results cookie: %s is invalid", string(paging.Cookie))
+		}
+	}
+
+	// a page size of 0 abandons the paged search
+	if paging.PagingSize == 0 {
+		return []*ldapEntry{}, "", nil
+	}
+
+	end := offset + int(paging.PagingSize)
+	if end >= len(entries) {
+		return entries[offset:], "", nil
+	}
+	return entries[offset:end], strconv.Itoa(end), nil
+}
+
+func handleSearch(w ldap.ResponseWriter, m *ldap.Message) {
+	startTime := time.Now()
+	res := ldap.NewSearchResultDoneResponse(ldap.LDAPResultSuccess)
+	r := m.GetSearchRequest()
+
+	if isRootDseSearch(r) {
+		e := buildRootDseEntry()
+		if e.matchFilter(r.Filter()) {
+			w.Write(e.toSearchResultEntry(r.Attributes()))
+		}
+		w.Write(res)
+		return
+	}
+
+	if !m.Client.IsAuthenticated {
+		res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+		w.Write(res)
+		return
+	}
+
+	// Handle Stop Signal (server stop / client disconnected / Abandoned request....)
+	select {
+	case <-m.Done:
+		log.Print("Leaving handleSearch...")
+		return
+	default:
+	}
+
+	paging, err := getPagingControl(m)
+	if err != nil {
+		log.Printf("getPagingControl() error: %s", err.Error())
+		res.SetResultCode(ldap.LDAPResultProtocolError)
+		w.Write(res)
+		return
+	}
+
+	entries, code := getSearchEntries(m)
+	if code != ldap.LDAPResultSuccess {
+		res.SetResultCode(code)
+		w.Write(res)
+		return
+	}
+
+	resultCode := ldap.LDAPResultSuccess
+	sizeLimit := int(r.SizeLimit())
+	if sizeLimit > 0 && len(entries) > sizeLimit {
+		entries = entries[:sizeLimit]
+		resultCode = ldap.LDAPResultSizeLimitExceeded
+	}
+
+	if paging != nil {
+		page, cookie, err := getSearchPage(entries, paging)
+		if err != nil {
+			log.Printf("getSearchPage() error: %s", err.Error())
+			res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+			w.Write(res)
+			return
+		}
+		if cookie != "" {
+			resultCode = ldap.LDAPResultSuccess
+		}
+
+		if conn := getLdapConn(m); conn != nil {
+			responsePaging := &goldap.ControlPaging{PagingSize: uint32(len(entries)), Cookie: []byte(cookie)}
+			conn.setResponseControls(int(m.MessageID()), responsePaging)
+		}
+		entries = page
+	}
+
+	timeLimit := time.Duration(r.TimeLimit()) * time.Second
+	for _, e := range entries {
+		select {
+		case <-m.Done:
+			log.Print("Leaving handleSearch...")
+			return
+		default:
+		}
+
+		if timeLimit > 0 && time.Since(startTime) > timeLimit {
+			resultCode = ldap.LDAPResultTimeLimitExceeded
+			break
+		}
+
+		w.Write(e.toSearchResultEntry(r.Attributes()))
+	}
+
+	res.SetResultCode(resultCode)
+	w.Write(res)
+}
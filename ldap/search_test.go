@@ -0,0 +1,119 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	ldap "github.com/casdoor/ldapserver"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/lor00x/goldap/message"
+	"github.com/stretchr/testify/assert"
+)
+
+type bufferConn struct {
+	net.Conn
+	buffer bytes.Buffer
+}
+
+func (c *bufferConn) Write(b []byte) (int, error) {
+	return c.buffer.Write(b)
+}
+
+func TestMatchFilter(t *testing.T) {
+	e := newLdapEntry("uid=1,cn=alice,ou=built-in,dc=example,dc=com")
+	e.addAttribute("cn", "alice")
+	e.addAttribute("displayName", "Alice  Smith")
+	e.addAttribute("uidNumber", "1000")
+	e.addAttribute(ldapMemberOfAttr, "cn=dev,ou=groups,ou=built-in,dc=example,dc=com")
+
+	scenarios := []struct {
+		filter   string
+		expected bool
+	}{
+		{"(objectClass=*)", true},
+		{"(cn=ALICE)", true},
+		{"(!(cn=alice))", false},
+		{"(|(cn=bob)(cn=al*))", true},
+		{"(&(cn=alice)(mail=*))", false},
+		{"(!(mail=alice@example.com))", false},
+		{"(!(&(cn=bob)(mail=alice@example.com)))", true},
+		{"(|(mail=alice@example.com)(cn=alice))", true},
+		{"(displayName~=alice smith)", true},
+		{"(uidNumber>=999)", true},
+		{"(uidNumber<=999)", false},
+		{"(memberOf=CN=dev, OU=groups,ou=built-in,dc=example,dc=com)", true},
+		{"(memberOf=built-in/dev)", true},
+		{"(memberOf=built-in/eng)", false},
+	}
+
+	for _, scenario := range scenarios {
+		assert.Equal(t, scenario.expected, e.matchFilter(getTestFilter(t, scenario.filter)), scenario.filter)
+	}
+}
+
+func TestIsEntryInScope(t *testing.T) {
+	dn := "uid=1,cn=alice,ou=built-in,dc=example,dc=com"
+	assert.True(t, isEntryInScope(dn, "dc=example,dc=com", message.SearchRequestHomeSubtree))
+	assert.True(t, isEntryInScope(dn, "ou=*,dc=example,dc=com", message.SearchRequestHomeSubtree))
+	assert.False(t, isEntryInScope(dn, "ou=built-in,dc=example,dc=com", message.SearchRequestSingleLevel))
+	assert.True(t, isEntryInScope(dn, "cn=alice,ou=built-in,dc=example,dc=com", message.SearchRequestSingleLevel))
+	assert.True(t, isEntryInScope(dn, "UID=1,CN=alice,ou=built-in,dc=example,dc=com", message.SearchRequestScopeBaseObject))
+	assert.False(t, isEntryInScope(dn, "ou=other,dc=example,dc=com", message.SearchRequestHomeSubtree))
+}
+
+func TestGetSearchPage(t *testing.T) {
+	entries := []*ldapEntry{newLdapEntry("cn=1"), newLdapEntry("cn=2"), newLdapEntry("cn=3")}
+
+	page, cookie, err := getSearchPage(entries, &goldap.ControlPaging{PagingSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(page))
+	assert.Equal(t, "2", cookie)
+
+	page, cookie, err = getSearchPage(entries, &goldap.ControlPaging{PagingSize: 2, Cookie: []byte(cookie)})
+	assert.Nil(t, err)
+	assert.Equal(t, "cn=3", page[0].dn)
+	assert.Equal(t, "", cookie)
+
+	_, _, err = getSearchPage(entries, &goldap.ControlPaging{PagingSize: 2, Cookie: []byte("x")})
+	assert.NotNil(t, err)
+}
+
+func TestLdapConnResponseControls(t *testing.T) {
+	buffer := &bufferConn{}
+	conn := newLdapConn(buffer)
+	conn.setResponseControls(2, &goldap.ControlPaging{PagingSize: 3, Cookie: []byte("2")})
+
+	m := message.NewLDAPMessageWithProtocolOp(ldap.NewSearchResultDoneResponse(ldap.LDAPResultSuccess))
+	m.SetMessageID(2)
+	data, err := m.Write()
+	assert.Nil(t, err)
+	_, err = conn.Write(data.Bytes())
+	assert.Nil(t, err)
+
+	packet, err := ber.DecodePacketErr(buffer.buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(packet.Children))
+
+	control, err := goldap.DecodeControl(packet.Children[2].Children[0])
+	assert.Nil(t, err)
+	paging := control.(*goldap.ControlPaging)
+	assert.Equal(t, uint32(3), paging.PagingSize)
+	assert.Equal(t, []byte("2"), paging.Cookie)
+	assert.False(t, conn.hasResponseControls())
+}
@@ -23,7 +23,6 @@ import (
 	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/object"
 	ldap "github.com/casdoor/ldapserver"
-	"github.com/lor00x/goldap/message"
 )
 
 func StartLdapServer() {
@@ -36,6 +35,11 @@ func StartLdapServer() {
 
 	routes.Bind(handleBind)
 	routes.Search(handleSearch).Label(" SEARCH****")
+	routes.Extended(handleStartTLS).RequestName(ldap.NoticeOfStartTLS).Label(" StartTLS****")
+	routes.Extended(handlePasswordModify).RequestName(ldap.NoticeOfPasswordModify).Label(" PASSWORD MODIFY****")
+	routes.Add(handleAdd).Label(" ADD****")
+	routes.Modify(handleModify).Label(" MODIFY****")
+	routes.Delete(handleDelete).Label(" DELETE****")
 
 	server.Handle(routes)
 	serverSsl.Handle(routes)
@@ -43,7 +47,7 @@ func StartLdapServer() {
 		if ldapServerPort == "" || ldapServerPort == "0" {
 			return
 		}
-		err := server.ListenAndServe("0.0.0.0:" + ldapServerPort)
+		err := server.ListenAndServe("0.0.0.0:"+ldapServerPort, wrapLdapListener)
 		if err != nil {
 			log.Printf("StartLdapServer() failed, err = %s", err.Error())
 		}
@@ -65,7 +69,7 @@ func StartLdapServer() {
 		secureConn := func(s *ldap.Server) {
 			s.Listener = tls.NewListener(s.Listener, config)
 		}
-		err = serverSsl.ListenAndServe("0.0.0.0:"+ldapsServerPort, secureConn)
+		err = serverSsl.ListenAndServe("0.0.0.0:"+ldapsServerPort, secureConn, wrapLdapListener)
 		if err != nil {
 			log.Printf("StartLdapsServer() failed, err = %s", err.Error())
 		}
@@ -97,6 +101,51 @@ func handleBind(w ldap.ResponseWriter, m *ldap.Message) {
 	res := ldap.NewBindResponse(ldap.LDAPResultSuccess)
 
 	if r.AuthenticationChoice() == "simple" {
+		conn := getLdapConn(m)
+		if conn != nil {
+			conn.setServiceAccount(nil)
+		}
+		m.Client.IsAuthenticated, m.Client.IsGlobalAdmin, m.Client.IsOrgAdmin = false, false, false
+
+		clientIp := getClientIp(m)
+		if isBindBlocked(clientIp) {
+			log.Printf("Bind blocked for %s: too many failed binds", clientIp)
+			res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+			res.SetDiagnosticMessage("too many failed binds, please try again later")
+			w.Write(res)
+			return
+		}
+
+		bindPassword := string(r.AuthenticationSimple())
+
+		if accountName, ok := getServiceAccountNameFromDn(string(r.Name())); ok {
+			account, err := checkServiceAccountPassword(accountName, bindPassword)
+			if err != nil {
+				log.Printf("Bind failed ServiceAccount=%s, ErrMsg=%s", string(r.Name()), err)
+				recordBindFailure(clientIp)
+				res.SetResultCode(ldap.LDAPResultInvalidCredentials)
+				res.SetDiagnosticMessage("invalid credentials ErrMsg: " + err.Error())
+				w.Write(res)
+				return
+			}
+			if conn == nil {
+				res.SetResultCode(ldap.LDAPResultOperationsError)
+				res.SetDiagnosticMessage("service accounts are not supported on this connection")
+				w.Write(res)
+				return
+			}
+
+			resetBindFailures(clientIp)
+			conn.setServiceAccount(account)
+			// the service account is kept on the connection instead of making the client a global admin,
+			// its searches are limited to its organizations and it can't write
+			m.Client.IsAuthenticated = true
+			m.Client.UserName = account.Name
+			m.Client.OrgName = account.Owner
+			w.Write(res)
+			return
+		}
+
 		bindUsername, bindOrg, err := getNameAndOrgFromDN(string(r.Name()))
 		if err != nil {
 			log.Printf("getNameAndOrgFromDN() error: %s", err.Error())
@@ -106,23 +155,16 @@ func handleBind(w ldap.ResponseWriter, m *ldap.Message) {
 			return
 		}
 
-		bindPassword := string(r.AuthenticationSimple())
-
-		enableCaptcha := false
-		isSigninViaLdap := false
-		isPasswordWithLdapEnabled := false
-		if bindPassword != "" {
-			isPasswordWithLdapEnabled = true
-		}
-
-		bindUser, err := object.CheckUserPassword(bindOrg, bindUsername, bindPassword, "en", enableCaptcha, isSigninViaLdap, isPasswordWithLdapEnabled)
+		bindUser, err := checkBindPassword(bindOrg, bindUsername, bindPassword, clientIp)
 		if err != nil {
-			log.Printf("Bind failed User=%s, Pass=%#v, ErrMsg=%s", string(r.Name()), r.Authentication(), err)
+			log.Printf("Bind failed User=%s, ErrMsg=%s", string(r.Name()), err)
+			recordBindFailure(clientIp)
 			res.SetResultCode(ldap.LDAPResultInvalidCredentials)
 			res.SetDiagnosticMessage("invalid credentials ErrMsg: " + err.Error())
 			w.Write(res)
 			return
 		}
+		resetBindFailures(clientIp)
 
 		if bindOrg == "built-in" || bindUser.IsGlobalAdmin() {
 			m.Client.IsGlobalAdmin, m.Client.IsOrgAdmin = true, true
@@ -140,66 +182,6 @@ func handleBind(w ldap.ResponseWriter, m *ldap.Message) {
 	w.Write(res)
 }
 
-func handleSearch(w ldap.ResponseWriter, m *ldap.Message) {
-	res := ldap.NewSearchResultDoneResponse(ldap.LDAPResultSuccess)
-	if !m.Client.IsAuthenticated {
-		res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
-		w.Write(res)
-		return
-	}
-
-	r := m.GetSearchRequest()
-	if r.FilterString() == "(objectClass=*)" {
-		w.Write(res)
-		return
-	}
-
-	// Handle Stop Signal (server stop / client disconnected / Abandoned request....)
-	select {
-	case <-m.Done:
-		log.Print("Leaving handleSearch...")
-		return
-	default:
-	}
-
-	users, code := GetFilteredUsers(m)
-	if code != ldap.LDAPResultSuccess {
-		res.SetResultCode(code)
-		w.Write(res)
-		return
-	}
-
-	for _, user := range users {
-		dn := fmt.Sprintf("uid=%s,cn=%s,%s", user.Id, user.Name, string(r.BaseObject()))
-		e := ldap.NewSearchResultEntry(dn)
-		uidNumberStr := fmt.Sprintf("%v", hash(user.Name))
-		e.AddAttribute("uidNumber", message.AttributeValue(uidNumberStr))
-		e.AddAttribute("gidNumber", message.AttributeValue(uidNumberStr))
-		e.AddAttribute("homeDirectory", message.AttributeValue("/home/"+user.Name))
-		e.AddAttribute("cn", message.AttributeValue(user.Name))
-		e.AddAttribute("uid", message.AttributeValue(user.Id))
-		for _, group := range user.Groups {
-			e.AddAttribute(ldapMemberOfAttr, message.AttributeValue(group))
-		}
-		attrs := r.Attributes()
-		for _, attr := range attrs {
-			if string(attr) == "*" {
-				attrs = AdditionalLdapAttributes
-				break
-			}
-		}
-		for _, attr := range attrs {
-			e.AddAttribute(message.AttributeDescription(attr), getAttribute(string(attr), user))
-			if string(attr) == "title" {
-				e.AddAttribute(message.AttributeDescription(attr), getAttribute("title", user))
-			}
-		}
-
-		w.Write(e)
-	}
-	w.Write(res)
-}
-
 func hash(s string) uint32 {
 	h := fnv.New32a()
 	h.Write([]byte(s))
@@ -111,37 +111,40 @@ func getNameAndOrgFromDN(DN string) (string, string, error) {
 	return params["cn"], params["ou"], nil
 }
 
-func getNameAndOrgFromFilter(baseDN, filter string) (string, string, int) {
+func getNameAndOrgFromFilter(baseDN string, filter message.Filter) (string, string, int) {
+	name := getUsernameFromFilter(filter)
 	if !strings.Contains(baseDN, "ou=") {
-		return "", "", ldap.LDAPResultInvalidDNSyntax
+		return name, "", ldap.LDAPResultSuccess
 	}
 
-	name, org, err := getNameAndOrgFromDN(fmt.Sprintf("cn=%s,", getUsername(filter)) + baseDN)
+	name, org, err := getNameAndOrgFromDN(fmt.Sprintf("cn=%s,", name) + baseDN)
 	if err != nil {
-		panic(err)
+		return "", "", ldap.LDAPResultInvalidDNSyntax
 	}
 
 	return name, org, ldap.LDAPResultSuccess
 }
 
-func getUsername(filter string) string {
-	nameIndex := strings.Index(filter, "cn=")
-	if nameIndex == -1 {
-		nameIndex = strings.Index(filter, "uid=")
-		if nameIndex == -1 {
-			return "*"
-		} else {
-			nameIndex += 4
+// getUsernameFromFilter returns the user name asserted by a cn or uid equality of the filter or of its AND filter,
+// "*" if the filter doesn't select a single user
+func getUsernameFromFilter(filter message.Filter) string {
+	switch f := filter.(type) {
+	case message.FilterEqualityMatch:
+		attr := strings.ToLower(string(f.AttributeDesc()))
+		if attr == "cn" || attr == "uid" {
+			return string(f.AssertionValue())
+		}
+	case message.FilterAnd:
+		for _, v := range f {
+			if _, ok := v.(message.FilterEqualityMatch); !ok {
+				continue
+			}
+			if name := getUsernameFromFilter(v); name != "*" {
+				return name
+			}
 		}
-	} else {
-		nameIndex += 3
-	}
-
-	var name string
-	for i := nameIndex; filter[i] != ')'; i++ {
-		name = name + string(filter[i])
 	}
-	return name
+	return "*"
 }
 
 func stringInSlice(value string, list []string) bool {
@@ -187,19 +190,31 @@ func buildUserFilterCondition(filter interface{}) (builder.Cond, error) {
 		if attr == ldapMemberOfAttr {
 			var names []string
 			groupId := string(f.AssertionValue())
-			users := object.GetGroupUsersWithoutError(groupId)
-			for _, user := range users {
-				names = append(names, user.Name)
+			if id, ok := getGroupIdFromDn(groupId); ok {
+				groupId = id
+			}
+			for _, id := range getDescendantGroupIds(groupId) {
+				users := object.GetGroupUsersWithoutError(id)
+				for _, user := range users {
+					names = append(names, user.Name)
+				}
 			}
 			return builder.In("name", names), nil
 		}
 
+		if strings.EqualFold(attr, "objectClass") {
+			return builder.Expr("1 = 1"), nil
+		}
+
 		field, err := getUserFieldFromAttribute(attr)
 		if err != nil {
 			return nil, err
 		}
 		return builder.Eq{field: string(f.AssertionValue())}, nil
 	case message.FilterPresent:
+		if strings.EqualFold(string(f), "objectClass") {
+			return builder.Expr("1 = 1"), nil
+		}
 		field, err := getUserFieldFromAttribute(string(f))
 		if err != nil {
 			return nil, err
@@ -242,31 +257,59 @@ func buildUserFilterCondition(filter interface{}) (builder.Cond, error) {
 	}
 }
 
-func buildSafeCondition(filter interface{}) builder.Cond {
+// buildSafeCondition converts the filter to a database condition that selects the candidate users, the filter
+// itself is evaluated on the candidates afterwards. No condition is used when the filter can't be converted or
+// contains a NOT filter, whose database semantics differ for empty fields.
+func buildSafeCondition(filter message.Filter) builder.Cond {
+	if containsNotFilter(filter) {
+		return nil
+	}
+
 	condition, err := buildUserFilterCondition(filter)
 	if err != nil {
 		log.Printf("err = %v", err.Error())
-		return builder.And(builder.Expr("1 != 1"))
+		return nil
 	}
 	return condition
 }
 
 func GetFilteredUsers(m *ldap.Message) (filteredUsers []*object.User, code int) {
+	filteredUsers, _, code = getFilteredUsers(m)
+	return filteredUsers, code
+}
+
+// getFilteredUsers returns the candidate users of the search, the returned bool is true when the users
+// are selected by a tag of the organization instead of the filter
+func getFilteredUsers(m *ldap.Message) (filteredUsers []*object.User, isTagSearch bool, code int) {
 	var err error
 	r := m.GetSearchRequest()
 
-	name, org, code := getNameAndOrgFromFilter(string(r.BaseObject()), r.FilterString())
+	name, org, code := getNameAndOrgFromFilter(string(r.BaseObject()), r.Filter())
 	if code != ldap.LDAPResultSuccess {
-		return nil, code
+		return nil, false, code
+	}
+
+	account := getServiceAccount(m)
+	if org == "" {
+		name = "*"
+		if m.Client.IsGlobalAdmin || account != nil {
+			org = "*"
+		} else {
+			org = m.Client.OrgName
+		}
 	}
 
 	if name == "*" { // get all users from organization 'org'
+		if account != nil {
+			filteredUsers, code = getServiceAccountUsers(account, org, buildSafeCondition(r.Filter()))
+			return filteredUsers, false, code
+		}
 		if m.Client.IsGlobalAdmin && org == "*" {
 			filteredUsers, err = object.GetGlobalUsersWithFilter(buildSafeCondition(r.Filter()))
 			if err != nil {
 				panic(err)
 			}
-			return filteredUsers, ldap.LDAPResultSuccess
+			return filteredUsers, false, ldap.LDAPResultSuccess
 		}
 		if m.Client.IsGlobalAdmin || org == m.Client.OrgName {
 			filteredUsers, err = object.GetUsersWithFilter(org, buildSafeCondition(r.Filter()))
@@ -274,18 +317,25 @@ func GetFilteredUsers(m *ldap.Message) (filteredUsers []*object.User, code int)
 				panic(err)
 			}
 
-			return filteredUsers, ldap.LDAPResultSuccess
+			return filteredUsers, false, ldap.LDAPResultSuccess
 		} else {
-			return nil, ldap.LDAPResultInsufficientAccessRights
+			return nil, false, ldap.LDAPResultInsufficientAccessRights
 		}
 	} else {
 		requestUserId := util.GetId(m.Client.OrgName, m.Client.UserName)
 		userId := util.GetId(org, name)
 
-		hasPermission, err := object.CheckUserPermission(requestUserId, userId, true, "en")
-		if !hasPermission {
-			log.Printf("err = %v", err.Error())
-			return nil, ldap.LDAPResultInsufficientAccessRights
+		// service accounts are limited to their organizations instead of the user permission check
+		if account != nil {
+			if !account.IsOrganizationAllowed(org) {
+				return nil, false, ldap.LDAPResultInsufficientAccessRights
+			}
+		} else {
+			hasPermission, err := object.CheckUserPermission(requestUserId, userId, true, "en")
+			if !hasPermission {
+				log.Printf("err = %v", err.Error())
+				return nil, false, ldap.LDAPResultInsufficientAccessRights
+			}
 		}
 
 		user, err := object.GetUser(userId)
@@ -295,7 +345,7 @@ func GetFilteredUsers(m *ldap.Message) (filteredUsers []*object.User, code int)
 
 		if user != nil {
 			filteredUsers = append(filteredUsers, user)
-			return filteredUsers, ldap.LDAPResultSuccess
+			return filteredUsers, false, ldap.LDAPResultSuccess
 		}
 
 		organization, err := object.GetOrganization(util.GetId("admin", org))
@@ -304,11 +354,11 @@ func GetFilteredUsers(m *ldap.Message) (filteredUsers []*object.User, code int)
 		}
 
 		if organization == nil {
-			return nil, ldap.LDAPResultNoSuchObject
+			return nil, false, ldap.LDAPResultNoSuchObject
 		}
 
 		if !stringInSlice(name, organization.Tags) {
-			return nil, ldap.LDAPResultNoSuchObject
+			return nil, false, ldap.LDAPResultNoSuchObject
 		}
 
 		users, err := object.GetUsersByTagWithFilter(org, name, buildSafeCondition(r.Filter()))
@@ -317,7 +367,7 @@ func GetFilteredUsers(m *ldap.Message) (filteredUsers []*object.User, code int)
 		}
 
 		filteredUsers = append(filteredUsers, users...)
-		return filteredUsers, ldap.LDAPResultSuccess
+		return filteredUsers, true, ldap.LDAPResultSuccess
 	}
 }
 
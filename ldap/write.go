@@ -0,0 +1,310 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	ldap "github.com/casdoor/ldapserver"
+	"github.com/lor00x/goldap/message"
+)
+
+// ldapGeneratedAttributes are derived from the user and the DN, they are ignored on add and can't be modified
+var ldapGeneratedAttributes = []string{"objectclass", "cn", "uid", "uidnumber", "gidnumber", "homedirectory", "memberof"}
+
+// setUserAttribute sets the user field of a writable LDAP attribute and returns its column
+func setUserAttribute(user *object.User, attr string, value string) (string, error) {
+	switch strings.ToLower(attr) {
+	case "displayname":
+		user.DisplayName = value
+		return "display_name", nil
+	case "mail", "email":
+		user.Email = value
+		return "email", nil
+	case "mobile":
+		user.Phone = value
+		return "phone", nil
+	case "title":
+		user.Tag = value
+		return "tag", nil
+	default:
+		return "", fmt.Errorf("attribute %s is not writable", attr)
+	}
+}
+
+func isGeneratedAttribute(attr string) bool {
+	return stringInSlice(strings.ToLower(attr), ldapGeneratedAttributes)
+}
+
+// isConfidential reports whether a clear text password can be sent on the connection, i.e. over LDAPS or after StartTLS
+func isConfidential(m *ldap.Message) bool {
+	conn := getLdapConn(m)
+	return conn != nil && conn.isTLS()
+}
+
+func getPasswordValue(values []message.AttributeValue) (string, error) {
+	if len(values) != 1 {
+		return "", fmt.Errorf("userPassword must have exactly one value")
+	}
+
+	password := string(values[0])
+	if strings.HasPrefix(password, "{") && strings.Contains(password, "}") {
+		return "", fmt.Errorf("hashed passwords are not supported, please send the password in clear text")
+	}
+	return password, nil
+}
+
+// checkWriteAccess checks that write operations are enabled and the client is an admin of the entry's organization,
+// it returns the user name and organization of the entry DN
+func checkWriteAccess(m *ldap.Message, dn string) (string, string, int, error) {
+	if !conf.GetConfigBool("ldapWriteEnabled") {
+		return "", "", ldap.LDAPResultUnwillingToPerform, fmt.Errorf("write operations are disabled, please set ldapWriteEnabled")
+	}
+
+	if getServiceAccount(m) != nil {
+		return "", "", ldap.LDAPResultInsufficientAccessRights, fmt.Errorf("service accounts are read-only")
+	}
+
+	if !m.Client.IsAuthenticated || !m.Client.IsOrgAdmin {
+		return "", "", ldap.LDAPResultInsufficientAccessRights, fmt.Errorf("write operations require an admin bind")
+	}
+
+	if _, ok := getGroupIdFromDn(dn); ok {
+		return "", "", ldap.LDAPResultUnwillingToPerform, fmt.Errorf("only user entries can be written")
+	}
+
+	name, org, err := getNameAndOrgFromDN(dn)
+	if err != nil {
+		return "", "", ldap.LDAPResultInvalidDNSyntax, err
+	}
+
+	if !m.Client.IsGlobalAdmin && org != m.Client.OrgName {
+		return "", "", ldap.LDAPResultInsufficientAccessRights, fmt.Errorf("the entry: %s is not in the organization: %s", dn, m.Client.OrgName)
+	}
+	return name, org, ldap.LDAPResultSuccess, nil
+}
+
+func handleAdd(w ldap.ResponseWriter, m *ldap.Message) {
+	res := ldap.NewAddResponse(ldap.LDAPResultSuccess)
+	r := m.GetAddRequest()
+
+	code, err := addUserEntry(m, string(r.Entry()), r.Attributes())
+	if err != nil {
+		log.Printf("handleAdd() error: %s", err.Error())
+		res.SetResultCode(code)
+		(*message.LDAPResult)(&res).SetDiagnosticMessage(err.Error())
+	}
+	w.Write(res)
+}
+
+func addUserEntry(m *ldap.Message, dn string, attributes message.AttributeList) (int, error) {
+	name, org, code, err := checkWriteAccess(m, dn)
+	if err != nil {
+		return code, err
+	}
+
+	oldUser, err := object.GetUser(util.GetId(org, name))
+	if err != nil {
+		return ldap.LDAPResultOther, err
+	}
+	if oldUser != nil {
+		return ldap.LDAPResultEntryAlreadyExists, fmt.Errorf("the user: %s already exists", util.GetId(org, name))
+	}
+
+	user := &object.User{
+		Owner:       org,
+		Name:        name,
+		CreatedTime: util.GetCurrentTime(),
+		Type:        "normal-user",
+		Properties:  map[string]string{},
+	}
+
+	for _, attribute := range attributes {
+		attr := string(attribute.Type_())
+		values := attribute.Vals()
+		if strings.EqualFold(attr, "userPassword") {
+			if !isConfidential(m) {
+				return ldap.LDAPResultConfidentialityRequired, fmt.Errorf("userPassword can only be set over a TLS connection")
+			}
+			user.Password, err = getPasswordValue(values)
+			if err != nil {
+				return ldap.LDAPResultConstraintViolation, err
+			}
+			continue
+		}
+		if isGeneratedAttribute(attr) {
+			continue
+		}
+		if len(values) != 1 {
+			return ldap.LDAPResultConstraintViolation, fmt.Errorf("attribute %s must have exactly one value", attr)
+		}
+
+		_, err = setUserAttribute(user, attr, string(values[0]))
+		if err != nil {
+			return ldap.LDAPResultUndefinedAttributeType, err
+		}
+	}
+
+	msg := object.CheckUpdateUser(&object.User{}, user, "en")
+	if msg != "" {
+		return ldap.LDAPResultConstraintViolation, errors.New(msg)
+	}
+
+	if user.Password != "" {
+		msg = object.CheckPasswordComplexity(user, user.Password)
+		if msg != "" {
+			return ldap.LDAPResultConstraintViolation, errors.New(msg)
+		}
+	}
+
+	_, err = object.AddUser(user)
+	if err != nil {
+		return ldap.LDAPResultOther, err
+	}
+	return ldap.LDAPResultSuccess, nil
+}
+
+func handleModify(w ldap.ResponseWriter, m *ldap.Message) {
+	res := ldap.NewModifyResponse(ldap.LDAPResultSuccess)
+	r := m.GetModifyRequest()
+
+	code, err := modifyUserEntry(m, string(r.Object()), r.Changes())
+	if err != nil {
+		log.Printf("handleModify() error: %s", err.Error())
+		res.SetResultCode(code)
+		(*message.LDAPResult)(&res).SetDiagnosticMessage(err.Error())
+	}
+	w.Write(res)
+}
+
+func modifyUserEntry(m *ldap.Message, dn string, changes []message.ModifyRequestChange) (int, error) {
+	name, org, code, err := checkWriteAccess(m, dn)
+	if err != nil {
+		return code, err
+	}
+
+	userId := util.GetId(org, name)
+	oldUser, err := object.GetUser(userId)
+	if err != nil {
+		return ldap.LDAPResultOther, err
+	}
+	if oldUser == nil {
+		return ldap.LDAPResultNoSuchObject, fmt.Errorf("the user: %s doesn't exist", userId)
+	}
+
+	user := *oldUser
+	columns := []string{}
+	password := ""
+	for _, change := range changes {
+		modification := change.Modification()
+		attr := string(modification.Type_())
+		values := modification.Vals()
+
+		if strings.EqualFold(attr, "userPassword") {
+			if !isConfidential(m) {
+				return ldap.LDAPResultConfidentialityRequired, fmt.Errorf("userPassword can only be set over a TLS connection")
+			}
+			if change.Operation() == message.ModifyRequestChangeOperationDelete {
+				return ldap.LDAPResultUnwillingToPerform, fmt.Errorf("userPassword can't be deleted")
+			}
+			password, err = getPasswordValue(values)
+			if err != nil {
+				return ldap.LDAPResultConstraintViolation, err
+			}
+			continue
+		}
+		if isGeneratedAttribute(attr) {
+			return ldap.LDAPResultUnwillingToPerform, fmt.Errorf("attribute %s can't be modified", attr)
+		}
+
+		value := ""
+		if change.Operation() != message.ModifyRequestChangeOperationDelete {
+			if len(values) != 1 {
+				return ldap.LDAPResultConstraintViolation, fmt.Errorf("attribute %s must have exactly one value", attr)
+			}
+			value = string(values[0])
+		}
+
+		column, err := setUserAttribute(&user, attr, value)
+		if err != nil {
+			return ldap.LDAPResultUndefinedAttributeType, err
+		}
+		if !util.ContainsString(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	if len(columns) > 0 {
+		msg := object.CheckUpdateUser(oldUser, &user, "en")
+		if msg != "" {
+			return ldap.LDAPResultConstraintViolation, errors.New(msg)
+		}
+
+		_, err = object.UpdateUser(userId, &user, columns, true)
+		if err != nil {
+			return ldap.LDAPResultOther, err
+		}
+	}
+
+	if password != "" {
+		return setUserPassword(&user, "", password, true)
+	}
+	return ldap.LDAPResultSuccess, nil
+}
+
+func handleDelete(w ldap.ResponseWriter, m *ldap.Message) {
+	res := ldap.NewDeleteResponse(ldap.LDAPResultSuccess)
+	r := m.GetDeleteRequest()
+
+	code, err := deleteUserEntry(m, string(r))
+	if err != nil {
+		log.Printf("handleDelete() error: %s", err.Error())
+		res.SetResultCode(code)
+		(*message.LDAPResult)(&res).SetDiagnosticMessage(err.Error())
+	}
+	w.Write(res)
+}
+
+func deleteUserEntry(m *ldap.Message, dn string) (int, error) {
+	name, org, code, err := checkWriteAccess(m, dn)
+	if err != nil {
+		return code, err
+	}
+
+	if org == "built-in" && name == "admin" {
+		return ldap.LDAPResultUnwillingToPerform, fmt.Errorf("the built-in admin can't be deleted")
+	}
+
+	userId := util.GetId(org, name)
+	user, err := object.GetUser(userId)
+	if err != nil {
+		return ldap.LDAPResultOther, err
+	}
+	if user == nil {
+		return ldap.LDAPResultNoSuchObject, fmt.Errorf("the user: %s doesn't exist", userId)
+	}
+
+	_, err = object.DeleteUser(user)
+	if err != nil {
+		return ldap.LDAPResultOther, err
+	}
+	return ldap.LDAPResultSuccess, nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/casdoor/casdoor/proxy"
 	"github.com/casdoor/casdoor/radius"
 	"github.com/casdoor/casdoor/routers"
+	"github.com/casdoor/casdoor/tacacs"
 	"github.com/casdoor/casdoor/util"
 )
 
@@ -47,6 +48,9 @@ func main() {
 	object.InitCasvisorConfig()
 
 	util.SafeGoroutine(func() { object.RunSyncUsersJob() })
+	util.SafeGoroutine(func() { object.RunScimProvisioningWorker() })
+	util.SafeGoroutine(func() { object.RunWebhookDeliveryWorker() })
+	util.SafeGoroutine(func() { object.RunEventBusWorker() })
 	util.SafeGoroutine(func() { controllers.InitCLIDownloader() })
 
 	// beego.DelStaticPath("/static")
@@ -109,6 +113,8 @@ func main() {
 
 	go ldap.StartLdapServer()
 	go radius.StartRadiusServer()
+	go radius.StartRadSecServer()
+	go tacacs.StartTacacsServer()
 	go object.ClearThroughputPerSecond()
 
 	beego.Run(fmt.Sprintf(":%v", port))
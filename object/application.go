@@ -95,6 +95,7 @@ type Application struct {
 	CertPublicKey         string          `xorm:"-" json:"certPublicKey"`
 	Tags                  []string        `xorm:"mediumtext" json:"tags"`
 	SamlAttributes        []*SamlItem     `xorm:"varchar(1000)" json:"samlAttributes"`
+	CasServices           []*CasService   `xorm:"mediumtext" json:"casServices"`
 	IsShared              bool            `json:"isShared"`
 	IpRestriction         string          `json:"ipRestriction"`
 
@@ -126,6 +127,8 @@ type Application struct {
 
 	FailedSigninLimit      int `json:"failedSigninLimit"`
 	FailedSigninFrozenTime int `json:"failedSigninFrozenTime"`
+
+	ScimProvisioning *ScimProvisioning `xorm:"mediumtext" json:"scimProvisioning"`
 }
 
 func GetApplicationCount(owner, field, value string) (int64, error) {
@@ -530,6 +533,9 @@ func GetMaskedApplication(application *Application, userId string) *Application
 
 	application.ClientSecret = "***"
 	application.Cert = "***"
+	if application.ScimProvisioning != nil {
+		application.ScimProvisioning.Token = "***"
+	}
 	application.EnablePassword = false
 	application.EnableSigninSession = false
 	application.EnableCodeSignin = false
@@ -660,6 +666,10 @@ func UpdateApplication(id string, application *Application) (bool, error) {
 		providerItem.Provider = nil
 	}
 
+	if application.ScimProvisioning != nil && application.ScimProvisioning.Token == "***" && oldApplication.ScimProvisioning != nil {
+		application.ScimProvisioning.Token = oldApplication.ScimProvisioning.Token
+	}
+
 	session := ormer.Engine.ID(core.PK{owner, name}).AllCols()
 	if application.ClientSecret == "***" {
 		session.Omit("client_secret")
@@ -669,6 +679,7 @@ func UpdateApplication(id string, application *Application) (bool, error) {
 		return false, err
 	}
 
+	clearScimProvisioningApplicationCache()
 	return affected != 0, nil
 }
 
@@ -704,6 +715,7 @@ func AddApplication(application *Application) (bool, error) {
 		return false, nil
 	}
 
+	clearScimProvisioningApplicationCache()
 	return affected != 0, nil
 }
 
@@ -713,6 +725,7 @@ func deleteApplication(application *Application) (bool, error) {
 		return false, err
 	}
 
+	clearScimProvisioningApplicationCache()
 	return affected != 0, nil
 }
 
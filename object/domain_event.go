@@ -0,0 +1,215 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+)
+
+// The domain events are written to an outbox table when the entities change, the event bus worker publishes them to
+// the event sinks in the order of their ids and moves the cursor of a sink only after the sink accepted them. A
+// delivery is retried until it succeeds, so the consumers receive each event at least once and should deduplicate
+// by the name of the event. The events of an entity are published in order because a failed event blocks the later
+// events of the sink.
+
+const (
+	DomainEventUserCreated    = "user.created"
+	DomainEventUserUpdated    = "user.updated"
+	DomainEventUserDeleted    = "user.deleted"
+	DomainEventLoginSucceeded = "login.succeeded"
+	DomainEventLoginFailed    = "login.failed"
+	DomainEventRoleChanged    = "role.changed"
+	DomainEventPaymentPaid    = "payment.paid"
+
+	domainEventRetention  = 7 * 24 * time.Hour
+	domainEventSettleTime = 10 * time.Second
+)
+
+var DomainEventTypes = []string{
+	DomainEventUserCreated, DomainEventUserUpdated, DomainEventUserDeleted, DomainEventLoginSucceeded,
+	DomainEventLoginFailed, DomainEventRoleChanged, DomainEventPaymentPaid,
+}
+
+type DomainEvent struct {
+	Id           int64  `xorm:"pk autoincr" json:"id"`
+	Name         string `xorm:"varchar(100) unique" json:"name"`
+	CreatedTime  string `xorm:"varchar(100) index" json:"createdTime"`
+	Type         string `xorm:"varchar(100)" json:"type"`
+	Organization string `xorm:"varchar(100)" json:"organization"`
+	EntityType   string `xorm:"varchar(100)" json:"entityType"`
+	EntityId     string `xorm:"varchar(200)" json:"entityId"`
+	Data         string `xorm:"mediumtext" json:"data"`
+}
+
+// DomainEventMessage is the message published to the sinks
+type DomainEventMessage struct {
+	Id           int64           `json:"id"`
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	Time         string          `json:"time"`
+	Organization string          `json:"organization"`
+	EntityType   string          `json:"entityType"`
+	EntityId     string          `json:"entityId"`
+	Data         json.RawMessage `json:"data"`
+}
+
+func (event *DomainEvent) getMessage() string {
+	message := &DomainEventMessage{
+		Id:           event.Id,
+		Name:         event.Name,
+		Type:         event.Type,
+		Time:         event.CreatedTime,
+		Organization: event.Organization,
+		EntityType:   event.EntityType,
+		EntityId:     event.EntityId,
+		Data:         json.RawMessage(event.Data),
+	}
+	return util.StructToJson(message)
+}
+
+func GetDomainEventCount(organization, field, value string) (int64, error) {
+	session := GetSession("", -1, -1, field, value, "", "")
+	return session.Count(&DomainEvent{Organization: organization})
+}
+
+func GetPaginationDomainEvents(organization string, offset, limit int, field, value, sortField, sortOrder string) ([]*DomainEvent, error) {
+	if sortField == "" || sortOrder == "" {
+		sortField = "id"
+		sortOrder = "descend"
+	}
+
+	events := []*DomainEvent{}
+	session := GetSession("", offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&events, &DomainEvent{Organization: organization})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// addDomainEvent writes the event to the outbox if an event sink is enabled, the change of the entity is already
+// saved so a failure is logged instead of returned
+func addDomainEvent(eventType string, organization string, entityType string, entityId string, data interface{}) {
+	count, err := ormer.Engine.Where("is_enabled = ?", true).Count(&EventSink{})
+	if err != nil || count == 0 {
+		return
+	}
+
+	event := &DomainEvent{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Type:         eventType,
+		Organization: organization,
+		EntityType:   entityType,
+		EntityId:     entityId,
+		Data:         util.StructToJson(data),
+	}
+	_, err = ormer.Engine.Insert(event)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to add the domain event: %s of %s, error: %s", eventType, entityId, err))
+		return
+	}
+
+	signalEventBusWorker()
+}
+
+func addUserDomainEvent(eventType string, user *User) {
+	addDomainEvent(eventType, user.Owner, "user", user.GetId(), getEventHookUserMap(user))
+}
+
+// AddLoginDomainEvent records a sign-in of the user to the application
+func AddLoginDomainEvent(user *User, application string, clientIp string, succeeded bool) {
+	eventType := DomainEventLoginSucceeded
+	if !succeeded {
+		eventType = DomainEventLoginFailed
+	}
+
+	data := map[string]interface{}{
+		"user":             user.GetId(),
+		"application":      application,
+		"clientIp":         clientIp,
+		"signinWrongTimes": user.SigninWrongTimes,
+	}
+	addDomainEvent(eventType, user.Owner, "user", user.GetId(), data)
+}
+
+func addRoleDomainEvent(action string, role *Role) {
+	data := map[string]interface{}{
+		"action": action,
+		"role":   role,
+	}
+	addDomainEvent(DomainEventRoleChanged, role.Owner, "role", role.GetId(), data)
+}
+
+func getDomainEvents(lastId int64, limit int) ([]*DomainEvent, error) {
+	events := []*DomainEvent{}
+	err := ormer.Engine.Where("id > ?", lastId).Asc("id").Limit(limit).Find(&events)
+	return events, err
+}
+
+// getSettledDomainEvents returns the events before the first gap of the ids after the cursor. The id of an event is
+// taken when it's inserted but the event is read only after it's committed, so a gap can be an event committed later,
+// the events after the gap wait until it's filled or until the settle time passes and the insert is taken as failed.
+func getSettledDomainEvents(lastId int64, events []*DomainEvent) []*DomainEvent {
+	for i, event := range events {
+		if event.Id != lastId+1 && !event.isSettled() {
+			return events[:i]
+		}
+		lastId = event.Id
+	}
+	return events
+}
+
+func (event *DomainEvent) isSettled() bool {
+	createdTime, err := time.Parse(time.RFC3339, event.CreatedTime)
+	if err != nil {
+		return true
+	}
+	return time.Since(createdTime) > domainEventSettleTime
+}
+
+// deleteExpiredDomainEvents deletes the expired events that all the enabled sinks have published, a sink enabled again
+// resumes from the earliest event that is kept
+func deleteExpiredDomainEvents(sinks []*EventSink) error {
+	expireTime := time.Now().Add(-domainEventRetention).Format(time.RFC3339)
+	session := ormer.Engine.Where("created_time < ?", expireTime)
+	if len(sinks) != 0 {
+		minEventId := sinks[0].LastEventId
+		for _, sink := range sinks {
+			if sink.LastEventId < minEventId {
+				minEventId = sink.LastEventId
+			}
+		}
+		session = session.And("id <= ?", minEventId)
+	}
+
+	_, err := session.Delete(&DomainEvent{})
+	return err
+}
+
+func getLastDomainEventId() (int64, error) {
+	event := DomainEvent{}
+	existed, err := ormer.Engine.Desc("id").Get(&event)
+	if err != nil || !existed {
+		return 0, err
+	}
+	return event.Id, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/Knetic/govaluate"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	EventHookPreSignup        = "PreSignup"
+	EventHookPostLogin        = "PostLogin"
+	EventHookPreTokenIssuance = "PreTokenIssuance"
+
+	EventHookTypeHttp   = "HTTP"
+	EventHookTypeScript = "Script"
+
+	EventHookActionAllow      = "Allow"
+	EventHookActionDeny       = "Deny"
+	EventHookActionRequireMfa = "RequireMfa"
+)
+
+// EventHook is a blocking hook that runs before an action of the users of the organization, it allows or denies the
+// action and may require MFA, add claims to the token or change attributes of the user. A hook calls an HTTP
+// endpoint or evaluates its rules, the failure policy decides the action when the hook fails or times out.
+type EventHook struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Organization  string           `xorm:"varchar(100) index" json:"organization"`
+	Application   string           `xorm:"varchar(100)" json:"application"`
+	Events        []string         `xorm:"varchar(1000)" json:"events"`
+	Type          string           `xorm:"varchar(100)" json:"type"`
+	Url           string           `xorm:"varchar(200)" json:"url"`
+	Headers       []*Header        `xorm:"mediumtext" json:"headers"`
+	Secret        string           `xorm:"varchar(100)" json:"secret"`
+	Rules         []*EventHookRule `xorm:"mediumtext" json:"rules"`
+	Timeout       int              `json:"timeout"`
+	FailurePolicy string           `xorm:"varchar(100)" json:"failurePolicy"`
+	IsEnabled     bool             `json:"isEnabled"`
+}
+
+// EventHookRule is a rule of a script hook, the condition, the claims and the attributes are govaluate expressions
+// on the parameters of the event, e.g. condition: "endsWith(user.Email, '@example.com') && event == 'PostLogin'",
+// claims: {"tier": "property('tier')"}. An empty condition always matches.
+type EventHookRule struct {
+	Condition  string            `json:"condition"`
+	Action     string            `json:"action"`
+	Message    string            `json:"message"`
+	Claims     map[string]string `json:"claims"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+func GetEventHookCount(owner, organization, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&EventHook{Organization: organization})
+}
+
+func GetEventHooks(owner string, organization string) ([]*EventHook, error) {
+	hooks := []*EventHook{}
+	err := ormer.Engine.Desc("created_time").Find(&hooks, &EventHook{Owner: owner, Organization: organization})
+	if err != nil {
+		return hooks, err
+	}
+
+	return hooks, nil
+}
+
+func GetPaginationEventHooks(owner, organization string, offset, limit int, field, value, sortField, sortOrder string) ([]*EventHook, error) {
+	hooks := []*EventHook{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&hooks, &EventHook{Organization: organization})
+	if err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+func getEventHook(owner string, name string) (*EventHook, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	hook := EventHook{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&hook)
+	if err != nil {
+		return &hook, err
+	}
+
+	if existed {
+		return &hook, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetEventHook(id string) (*EventHook, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getEventHook(owner, name)
+}
+
+func GetMaskedEventHook(hook *EventHook, errs ...error) (*EventHook, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	if hook == nil {
+		return nil, nil
+	}
+
+	if hook.Secret != "" {
+		hook.Secret = "***"
+	}
+	return hook, nil
+}
+
+func GetMaskedEventHooks(hooks []*EventHook, errs ...error) ([]*EventHook, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	var err error
+	for _, hook := range hooks {
+		hook, err = GetMaskedEventHook(hook)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hooks, nil
+}
+
+func checkEventHook(hook *EventHook) error {
+	for _, event := range hook.Events {
+		if !util.InSlice([]string{EventHookPreSignup, EventHookPostLogin, EventHookPreTokenIssuance}, event) {
+			return fmt.Errorf("the event of the hook: %s is not supported", event)
+		}
+	}
+
+	switch hook.Type {
+	case EventHookTypeHttp:
+		if hook.Url == "" {
+			return fmt.Errorf("the URL of the HTTP hook should not be empty")
+		}
+	case EventHookTypeScript:
+		for _, rule := range hook.Rules {
+			expressions := []string{rule.Condition}
+			for _, expression := range rule.Claims {
+				expressions = append(expressions, expression)
+			}
+			for _, expression := range rule.Attributes {
+				expressions = append(expressions, expression)
+			}
+
+			for _, expression := range expressions {
+				if expression == "" {
+					continue
+				}
+				_, err := govaluate.NewEvaluableExpressionWithFunctions(expression, getEventHookFunctions(nil))
+				if err != nil {
+					return fmt.Errorf("the expression: %s of the hook is invalid: %s", expression, err.Error())
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("the type of the hook: %s is not supported", hook.Type)
+	}
+	return nil
+}
+
+func UpdateEventHook(id string, hook *EventHook) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	h, err := getEventHook(owner, name)
+	if err != nil {
+		return false, err
+	} else if h == nil {
+		return false, nil
+	}
+
+	if hook.Secret == "***" {
+		hook.Secret = h.Secret
+	}
+
+	err = checkEventHook(hook)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(hook)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func AddEventHook(hook *EventHook) (bool, error) {
+	err := checkEventHook(hook)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.Insert(hook)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteEventHook(hook *EventHook) (bool, error) {
+	affected, err := ormer.Engine.ID(core.PK{hook.Owner, hook.Name}).Delete(&EventHook{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (hook *EventHook) GetId() string {
+	return fmt.Sprintf("%s/%s", hook.Owner, hook.Name)
+}
@@ -0,0 +1,457 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Knetic/govaluate"
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const eventHookDefaultTimeout = 5 * time.Second
+
+// the attributes of a user that a hook can change, by their JSON names
+var eventHookUserAttributes = []string{
+	"displayName", "firstName", "lastName", "avatar", "affiliation", "title", "tag", "region", "location", "language",
+	"homepage", "bio", "properties",
+}
+
+var eventHookSensitiveFields = []string{
+	"password", "passwordSalt", "accessKey", "accessSecret", "totpSecret", "recoveryCodes", "mfaAccounts",
+	"managedAccounts", "webauthnCredentials", "faceIds",
+}
+
+// EventHookRequest is the body posted to an HTTP hook
+type EventHookRequest struct {
+	Event        string                 `json:"event"`
+	Organization string                 `json:"organization"`
+	Application  string                 `json:"application"`
+	ClientIp     string                 `json:"clientIp"`
+	User         map[string]interface{} `json:"user"`
+}
+
+// EventHookResult is the response of an HTTP hook and the merged result of the hooks of an event, an empty action
+// allows the event
+type EventHookResult struct {
+	Action         string                 `json:"action"`
+	Message        string                 `json:"message"`
+	RequireMfa     bool                   `json:"requireMfa"`
+	Claims         map[string]interface{} `json:"claims"`
+	UserAttributes map[string]interface{} `json:"userAttributes"`
+}
+
+func (result *EventHookResult) IsDenied() bool {
+	return result.Action == EventHookActionDeny
+}
+
+func (hook *EventHook) getTimeout() time.Duration {
+	if hook.Timeout > 0 {
+		return time.Duration(hook.Timeout) * time.Millisecond
+	}
+	return eventHookDefaultTimeout
+}
+
+func getEventHooksByEvent(organization string, application string, event string) ([]*EventHook, error) {
+	hooks := []*EventHook{}
+	err := ormer.Engine.Asc("created_time").Find(&hooks, &EventHook{Organization: organization, IsEnabled: true})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*EventHook{}
+	for _, hook := range hooks {
+		if util.InSlice(hook.Events, event) && (hook.Application == "" || hook.Application == application) {
+			res = append(res, hook)
+		}
+	}
+	return res, nil
+}
+
+func getEventHookUserMap(user *User) map[string]interface{} {
+	m := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(util.StructToJson(user)), &m)
+	for _, field := range eventHookSensitiveFields {
+		delete(m, field)
+	}
+	return m
+}
+
+// getEventHookSandboxUser returns a copy of the user without the sensitive fields for the rules of a script hook
+func getEventHookSandboxUser(user *User) *User {
+	sandboxUser := *user
+	userValue := reflect.ValueOf(&sandboxUser).Elem()
+	userType := userValue.Type()
+	for i := 0; i < userType.NumField(); i++ {
+		name := strings.Split(userType.Field(i).Tag.Get("json"), ",")[0]
+		if util.InSlice(eventHookSensitiveFields, name) {
+			userValue.Field(i).Set(reflect.Zero(userType.Field(i).Type))
+		}
+	}
+	return &sandboxUser
+}
+
+// RunEventHooks runs the hooks of the event in the order of their creation, the first hook that denies the event
+// stops the others. The claims and the attributes of the hooks are merged, a later hook overrides an earlier one.
+func RunEventHooks(event string, application *Application, user *User, clientIp string) (*EventHookResult, error) {
+	hooks, err := getEventHooksByEvent(user.Owner, application.Name, event)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &EventHookResult{Action: EventHookActionAllow, Claims: map[string]interface{}{}, UserAttributes: map[string]interface{}{}}
+	if len(hooks) == 0 {
+		return res, nil
+	}
+
+	request := &EventHookRequest{
+		Event:        event,
+		Organization: user.Owner,
+		Application:  application.Name,
+		ClientIp:     clientIp,
+		User:         getEventHookUserMap(user),
+	}
+
+	for _, hook := range hooks {
+		var result *EventHookResult
+		if hook.Type == EventHookTypeScript {
+			result, err = hook.runScript(request, user)
+		} else {
+			result, err = hook.runHttp(request)
+		}
+
+		if err != nil {
+			if hook.FailurePolicy == EventHookActionAllow {
+				logs.Warning(fmt.Sprintf("the hook: %s failed and is skipped, error: %s", hook.GetId(), err))
+				continue
+			}
+			return &EventHookResult{Action: EventHookActionDeny, Message: fmt.Sprintf("the hook: %s failed: %s", hook.GetId(), err.Error())}, nil
+		}
+
+		if result.IsDenied() {
+			if result.Message == "" {
+				result.Message = fmt.Sprintf("the action is denied by the hook: %s", hook.GetId())
+			}
+			return result, nil
+		}
+
+		res.RequireMfa = res.RequireMfa || result.RequireMfa
+		for k, v := range result.Claims {
+			res.Claims[k] = v
+		}
+		for k, v := range result.UserAttributes {
+			res.UserAttributes[k] = v
+		}
+	}
+	return res, nil
+}
+
+func (hook *EventHook) runHttp(request *EventHookRequest) (*EventHookResult, error) {
+	body := util.StructToJson(request)
+	req, err := http.NewRequest(http.MethodPost, hook.Url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for _, header := range hook.Headers {
+		req.Header.Set(header.Name, header.Value)
+	}
+	if hook.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, getWebhookSignature(hook.Secret, timestamp, body))
+	}
+
+	client := &http.Client{Timeout: hook.getTimeout()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("the hook returned status code: %d", resp.StatusCode)
+	}
+
+	result := &EventHookResult{}
+	err = json.Unmarshal(respBody, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Action != "" && result.Action != EventHookActionAllow && result.Action != EventHookActionDeny {
+		return nil, fmt.Errorf("the action of the hook: %s is not supported", result.Action)
+	}
+	return result, nil
+}
+
+func getEventHookFunctions(user *User) map[string]govaluate.ExpressionFunction {
+	toString := func(args []interface{}, i int) string {
+		if i >= len(args) || args[i] == nil {
+			return ""
+		}
+		return fmt.Sprint(args[i])
+	}
+
+	return map[string]govaluate.ExpressionFunction{
+		"property": func(args ...interface{}) (interface{}, error) {
+			if user == nil || user.Properties == nil {
+				return "", nil
+			}
+			return user.Properties[toString(args, 0)], nil
+		},
+		"inGroup": func(args ...interface{}) (interface{}, error) {
+			return user != nil && util.InSlice(user.Groups, toString(args, 0)), nil
+		},
+		"contains": func(args ...interface{}) (interface{}, error) {
+			return strings.Contains(toString(args, 0), toString(args, 1)), nil
+		},
+		"startsWith": func(args ...interface{}) (interface{}, error) {
+			return strings.HasPrefix(toString(args, 0), toString(args, 1)), nil
+		},
+		"endsWith": func(args ...interface{}) (interface{}, error) {
+			return strings.HasSuffix(toString(args, 0), toString(args, 1)), nil
+		},
+		"lower": func(args ...interface{}) (interface{}, error) {
+			return strings.ToLower(toString(args, 0)), nil
+		},
+		"upper": func(args ...interface{}) (interface{}, error) {
+			return strings.ToUpper(toString(args, 0)), nil
+		},
+	}
+}
+
+func evaluateEventHookExpression(expression string, parameters map[string]interface{}, functions map[string]govaluate.ExpressionFunction) (interface{}, error) {
+	evaluable, err := govaluate.NewEvaluableExpressionWithFunctions(expression, functions)
+	if err != nil {
+		return nil, err
+	}
+	return evaluable.Evaluate(parameters)
+}
+
+// runScript evaluates the rules of the hook in a goroutine so that a slow rule is abandoned at the timeout, the
+// expressions can only read the parameters of the event
+func (hook *EventHook) runScript(request *EventHookRequest, user *User) (*EventHookResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hook.getTimeout())
+	defer cancel()
+
+	type scriptResult struct {
+		result *EventHookResult
+		err    error
+	}
+	ch := make(chan scriptResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- scriptResult{err: fmt.Errorf("the rules of the hook panicked: %v", r)}
+			}
+		}()
+
+		result, err := hook.evaluateRules(request, user)
+		ch <- scriptResult{result: result, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("the rules of the hook timed out after %s", hook.getTimeout())
+	case r := <-ch:
+		return r.result, r.err
+	}
+}
+
+func (hook *EventHook) evaluateRules(request *EventHookRequest, user *User) (*EventHookResult, error) {
+	sandboxUser := getEventHookSandboxUser(user)
+
+	parameters := map[string]interface{}{
+		"event":        request.Event,
+		"organization": request.Organization,
+		"application":  request.Application,
+		"clientIp":     request.ClientIp,
+		"user":         sandboxUser,
+	}
+	functions := getEventHookFunctions(sandboxUser)
+
+	result := &EventHookResult{Action: EventHookActionAllow, Claims: map[string]interface{}{}, UserAttributes: map[string]interface{}{}}
+	for _, rule := range hook.Rules {
+		if rule.Condition != "" {
+			value, err := evaluateEventHookExpression(rule.Condition, parameters, functions)
+			if err != nil {
+				return nil, err
+			}
+			if matched, ok := value.(bool); !ok || !matched {
+				continue
+			}
+		}
+
+		for k, expression := range rule.Claims {
+			value, err := evaluateEventHookExpression(expression, parameters, functions)
+			if err != nil {
+				return nil, err
+			}
+			result.Claims[k] = value
+		}
+		for k, expression := range rule.Attributes {
+			value, err := evaluateEventHookExpression(expression, parameters, functions)
+			if err != nil {
+				return nil, err
+			}
+			result.UserAttributes[k] = value
+		}
+
+		switch rule.Action {
+		case EventHookActionDeny:
+			result.Action = EventHookActionDeny
+			result.Message = rule.Message
+			return result, nil
+		case EventHookActionRequireMfa:
+			result.RequireMfa = true
+		case EventHookActionAllow:
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// applyUserAttributes sets the attributes of the result on the user and returns their columns, the properties are
+// merged into the properties of the user
+func (result *EventHookResult) applyUserAttributes(user *User) []string {
+	columns := []string{}
+	userValue := reflect.ValueOf(user).Elem()
+	userType := userValue.Type()
+	for i := 0; i < userType.NumField(); i++ {
+		field := userType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		value, ok := result.UserAttributes[name]
+		if !ok || !util.InSlice(eventHookUserAttributes, name) {
+			continue
+		}
+
+		if name == "properties" {
+			properties, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if user.Properties == nil {
+				user.Properties = map[string]string{}
+			}
+			for k, v := range properties {
+				user.Properties[k] = fmt.Sprint(v)
+			}
+		} else if field.Type.Kind() == reflect.String {
+			userValue.Field(i).SetString(fmt.Sprint(value))
+		} else {
+			continue
+		}
+		columns = append(columns, util.CamelToSnakeCase(field.Name))
+	}
+	return columns
+}
+
+// ApplyEventHookResult saves the attributes that the hooks of a login changed
+func ApplyEventHookResult(user *User, result *EventHookResult) error {
+	columns := result.applyUserAttributes(user)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	_, err := UpdateUser(user.GetId(), user, columns, false)
+	return err
+}
+
+// CheckSignupHooks runs the hooks before a user signs up, the attributes are set on the new user
+func CheckSignupHooks(application *Application, user *User, clientIp string) error {
+	result, err := RunEventHooks(EventHookPreSignup, application, user, clientIp)
+	if err != nil {
+		return err
+	}
+	if result.IsDenied() {
+		return fmt.Errorf("%s", result.Message)
+	}
+
+	result.applyUserAttributes(user)
+	return nil
+}
+
+// runTokenHooks runs the hooks before a token is issued to the user, the attributes change the user in the token only
+// and the claims are added to the token
+func runTokenHooks(application *Application, user *User) (*User, map[string]interface{}, error) {
+	if user.Type == "application" {
+		return user, nil, nil
+	}
+
+	result, err := RunEventHooks(EventHookPreTokenIssuance, application, user, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.IsDenied() {
+		return nil, nil, fmt.Errorf("%s", result.Message)
+	}
+
+	if len(result.UserAttributes) != 0 {
+		tokenUser := *user
+		if user.Properties != nil {
+			tokenUser.Properties = map[string]string{}
+			for k, v := range user.Properties {
+				tokenUser.Properties[k] = v
+			}
+		}
+		result.applyUserAttributes(&tokenUser)
+		user = &tokenUser
+	}
+	return user, result.Claims, nil
+}
+
+var jwtRegisteredClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// addEventHookClaims adds the claims of the hooks to the token, the registered claims are not overridden
+func addEventHookClaims(token *jwt.Token, claims map[string]interface{}) error {
+	if len(claims) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(token.Claims)
+	if err != nil {
+		return err
+	}
+
+	mapClaims := jwt.MapClaims{}
+	err = json.Unmarshal(data, &mapClaims)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range claims {
+		if !util.InSlice(jwtRegisteredClaims, k) {
+			mapClaims[k] = v
+		}
+	}
+	token.Claims = mapClaims
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateEventHookRules(t *testing.T) {
+	hook := &EventHook{
+		Type:   EventHookTypeScript,
+		Events: []string{EventHookPostLogin},
+		Rules: []*EventHookRule{
+			{Condition: "endsWith(user.Email, '@blocked.com')", Action: EventHookActionDeny, Message: "blocked"},
+			{Condition: "property('tier') == 'gold'", Action: EventHookActionRequireMfa, Claims: map[string]string{"tier": "property('tier')"}},
+			{Action: EventHookActionAllow, Attributes: map[string]string{"title": "upper(user.Name)", "password": "'x'"}},
+		},
+	}
+	assert.Nil(t, checkEventHook(hook))
+
+	request := &EventHookRequest{Event: EventHookPostLogin}
+	user := &User{Owner: "built-in", Name: "alice", Email: "alice@example.com", Password: "secret", Properties: map[string]string{"tier": "gold"}}
+	result, err := hook.runScript(request, user)
+	assert.Nil(t, err)
+	assert.False(t, result.IsDenied())
+	assert.True(t, result.RequireMfa)
+	assert.Equal(t, "gold", result.Claims["tier"])
+
+	assert.Equal(t, []string{"title"}, result.applyUserAttributes(user))
+	assert.Equal(t, "ALICE", user.Title)
+	assert.Equal(t, "secret", user.Password)
+
+	user.Email = "bob@blocked.com"
+	result, err = hook.runScript(request, user)
+	assert.Nil(t, err)
+	assert.True(t, result.IsDenied())
+	assert.Equal(t, "blocked", result.Message)
+
+	hook.Rules = []*EventHookRule{{Condition: "user.Name =="}}
+	assert.NotNil(t, checkEventHook(hook))
+}
+
+func TestAddEventHookClaims(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{Subject: "alice"})
+	err := addEventHookClaims(token, map[string]interface{}{"tier": "gold", "sub": "bob"})
+	assert.Nil(t, err)
+
+	claims := token.Claims.(jwt.MapClaims)
+	assert.Equal(t, "gold", claims["tier"])
+	assert.Equal(t, "alice", claims["sub"])
+}
+
+func TestGetEventHookSandboxUser(t *testing.T) {
+	user := &User{Name: "alice", Password: "secret", AccessKey: "key", RecoveryCodes: []string{"code"}, MfaAccounts: []MfaAccount{{AccountName: "alice"}}}
+	sandboxUser := getEventHookSandboxUser(user)
+	assert.Equal(t, "alice", sandboxUser.Name)
+	assert.Empty(t, sandboxUser.Password)
+	assert.Empty(t, sandboxUser.AccessKey)
+	assert.Nil(t, sandboxUser.RecoveryCodes)
+	assert.Nil(t, sandboxUser.MfaAccounts)
+	assert.Equal(t, "secret", user.Password)
+}
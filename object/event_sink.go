@@ -0,0 +1,362 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	EventSinkTypeNats           = "NATS"
+	EventSinkTypeKafkaRestProxy = "Kafka REST Proxy"
+	EventSinkTypeRedis          = "Redis"
+	EventSinkTypeFile           = "File"
+
+	eventSinkBatchSize = 100
+	eventSinkTimeout   = 10 * time.Second
+)
+
+// EventSink publishes the domain events of the organization, or of all organizations if the organization is empty.
+// The endpoint is the URL of the NATS servers, the URL of the Kafka REST proxy, the address of the Redis server or the
+// path of the JSONL file in eventSinkFileBaseDir. The topic is the subject prefix, the Kafka topic or the Redis stream.
+type EventSink struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Organization string   `xorm:"varchar(100) index" json:"organization"`
+	Type         string   `xorm:"varchar(100)" json:"type"`
+	Endpoint     string   `xorm:"varchar(500)" json:"endpoint"`
+	Topic        string   `xorm:"varchar(200)" json:"topic"`
+	Username     string   `xorm:"varchar(100)" json:"username"`
+	Password     string   `xorm:"varchar(200)" json:"password"`
+	EventTypes   []string `xorm:"varchar(1000)" json:"eventTypes"`
+	IsEnabled    bool     `json:"isEnabled"`
+
+	LastEventId int64  `json:"lastEventId"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+	LastError   string `xorm:"mediumtext" json:"lastError"`
+}
+
+// EventSinkPublisher publishes the events in order and returns after the sink accepted all of them
+type EventSinkPublisher interface {
+	Publish(events []*DomainEvent) error
+	Close() error
+}
+
+var eventBusSignal = make(chan struct{}, 1)
+
+func GetEventSinkCount(owner, organization, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&EventSink{Organization: organization})
+}
+
+func GetEventSinks(owner string, organization string) ([]*EventSink, error) {
+	sinks := []*EventSink{}
+	err := ormer.Engine.Desc("created_time").Find(&sinks, &EventSink{Owner: owner, Organization: organization})
+	if err != nil {
+		return sinks, err
+	}
+
+	return sinks, nil
+}
+
+func GetPaginationEventSinks(owner, organization string, offset, limit int, field, value, sortField, sortOrder string) ([]*EventSink, error) {
+	sinks := []*EventSink{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&sinks, &EventSink{Organization: organization})
+	if err != nil {
+		return nil, err
+	}
+
+	return sinks, nil
+}
+
+func getEventSink(owner string, name string) (*EventSink, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	sink := EventSink{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&sink)
+	if err != nil {
+		return &sink, err
+	}
+
+	if existed {
+		return &sink, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetEventSink(id string) (*EventSink, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getEventSink(owner, name)
+}
+
+func GetMaskedEventSink(sink *EventSink, errs ...error) (*EventSink, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	if sink == nil {
+		return nil, nil
+	}
+
+	if sink.Password != "" {
+		sink.Password = "***"
+	}
+	return sink, nil
+}
+
+func GetMaskedEventSinks(sinks []*EventSink, errs ...error) ([]*EventSink, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	var err error
+	for _, sink := range sinks {
+		sink, err = GetMaskedEventSink(sink)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sinks, nil
+}
+
+func checkEventSink(sink *EventSink) error {
+	if !util.InSlice([]string{EventSinkTypeNats, EventSinkTypeKafkaRestProxy, EventSinkTypeRedis, EventSinkTypeFile}, sink.Type) {
+		return fmt.Errorf("the type of the event sink: %s is not supported", sink.Type)
+	}
+	if sink.Endpoint == "" {
+		return fmt.Errorf("the endpoint of the event sink should not be empty")
+	}
+	if sink.Topic == "" && sink.Type != EventSinkTypeFile {
+		return fmt.Errorf("the topic of the event sink should not be empty")
+	}
+	if sink.Type == EventSinkTypeFile {
+		_, err := getEventSinkFilePath(sink.Endpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, eventType := range sink.EventTypes {
+		if !util.InSlice(DomainEventTypes, eventType) {
+			return fmt.Errorf("the event type: %s is not supported", eventType)
+		}
+	}
+	return nil
+}
+
+// UpdateEventSink keeps the cursor of the sink, the events are published from where the sink stopped
+func UpdateEventSink(id string, sink *EventSink) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	oldSink, err := getEventSink(owner, name)
+	if err != nil {
+		return false, err
+	} else if oldSink == nil {
+		return false, nil
+	}
+
+	if sink.Password == "***" {
+		sink.Password = oldSink.Password
+	}
+
+	err = checkEventSink(sink)
+	if err != nil {
+		return false, err
+	}
+
+	sink.LastEventId = oldSink.LastEventId
+	sink.UpdatedTime = oldSink.UpdatedTime
+	sink.LastError = oldSink.LastError
+
+	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(sink)
+	if err != nil {
+		return false, err
+	}
+
+	if sink.IsEnabled {
+		signalEventBusWorker()
+	}
+	return affected != 0, nil
+}
+
+// AddEventSink adds the sink at the end of the event stream, the earlier events are not published to it
+func AddEventSink(sink *EventSink) (bool, error) {
+	err := checkEventSink(sink)
+	if err != nil {
+		return false, err
+	}
+
+	sink.LastEventId, err = getLastDomainEventId()
+	if err != nil {
+		return false, err
+	}
+	sink.LastError = ""
+
+	affected, err := ormer.Engine.Insert(sink)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteEventSink(sink *EventSink) (bool, error) {
+	affected, err := ormer.Engine.ID(core.PK{sink.Owner, sink.Name}).Delete(&EventSink{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (sink *EventSink) GetId() string {
+	return fmt.Sprintf("%s/%s", sink.Owner, sink.Name)
+}
+
+func (sink *EventSink) isSubscribed(event *DomainEvent) bool {
+	if sink.Organization != "" && sink.Organization != event.Organization {
+		return false
+	}
+	return len(sink.EventTypes) == 0 || util.InSlice(sink.EventTypes, event.Type)
+}
+
+func getEventSinkPublisher(sink *EventSink) (EventSinkPublisher, error) {
+	switch sink.Type {
+	case EventSinkTypeNats:
+		return newNatsEventSinkPublisher(sink)
+	case EventSinkTypeKafkaRestProxy:
+		return newKafkaRestProxyEventSinkPublisher(sink), nil
+	case EventSinkTypeRedis:
+		return newRedisEventSinkPublisher(sink)
+	case EventSinkTypeFile:
+		return newFileEventSinkPublisher(sink)
+	default:
+		return nil, fmt.Errorf("the type of the event sink: %s is not supported", sink.Type)
+	}
+}
+
+func signalEventBusWorker() {
+	select {
+	case eventBusSignal <- struct{}{}:
+	default:
+	}
+}
+
+func (sink *EventSink) updateCursor(lastEventId int64, lastError string) error {
+	sink.LastEventId = lastEventId
+	sink.UpdatedTime = util.GetCurrentTime()
+	sink.LastError = lastError
+	_, err := ormer.Engine.ID(core.PK{sink.Owner, sink.Name}).Cols("last_event_id", "updated_time", "last_error").Update(sink)
+	return err
+}
+
+// publishEvents publishes the settled events after the cursor of the sink in batches, a failed batch is published
+// again by the next run
+func (sink *EventSink) publishEvents() error {
+	var publisher EventSinkPublisher
+	defer func() {
+		if publisher != nil {
+			_ = publisher.Close()
+		}
+	}()
+
+	for {
+		events, err := getDomainEvents(sink.LastEventId, eventSinkBatchSize)
+		if err != nil {
+			return err
+		}
+
+		settledEvents := getSettledDomainEvents(sink.LastEventId, events)
+		if len(settledEvents) == 0 {
+			return nil
+		}
+
+		subscribedEvents := []*DomainEvent{}
+		for _, event := range settledEvents {
+			if sink.isSubscribed(event) {
+				subscribedEvents = append(subscribedEvents, event)
+			}
+		}
+
+		if len(subscribedEvents) != 0 {
+			if publisher == nil {
+				publisher, err = getEventSinkPublisher(sink)
+			}
+			if err == nil {
+				err = publisher.Publish(subscribedEvents)
+			}
+			if err != nil {
+				_ = sink.updateCursor(sink.LastEventId, err.Error())
+				return err
+			}
+		}
+
+		err = sink.updateCursor(settledEvents[len(settledEvents)-1].Id, "")
+		if err != nil {
+			return err
+		}
+
+		if len(settledEvents) < eventSinkBatchSize {
+			return nil
+		}
+	}
+}
+
+func publishDomainEvents() error {
+	sinks := []*EventSink{}
+	err := ormer.Engine.Where("is_enabled = ?", true).Find(&sinks)
+	if err != nil {
+		return err
+	}
+
+	for _, sink := range sinks {
+		err = sink.publishEvents()
+		if err != nil {
+			logs.Warning(fmt.Sprintf("failed to publish the domain events to the event sink: %s, error: %s", sink.GetId(), err))
+		}
+	}
+
+	return deleteExpiredDomainEvents(sinks)
+}
+
+// RunEventBusWorker publishes the domain events to the event sinks when an event is added and retries the failed
+// sinks periodically
+func RunEventBusWorker() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-eventBusSignal:
+		}
+
+		err := publishDomainEvents()
+		if err != nil {
+			logs.Warning(fmt.Sprintf("event bus failed, error: %s", err))
+		}
+	}
+}
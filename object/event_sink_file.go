@@ -0,0 +1,121 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/casdoor/casdoor/conf"
+)
+
+// fileEventSinkPublisher appends the events to a JSONL file, a line is written again if the process stops before
+// the cursor is saved
+type fileEventSinkPublisher struct {
+	file *os.File
+}
+
+func isPathInDir(dir string, path string) bool {
+	relPath, err := filepath.Rel(dir, path)
+	return err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+// getEventSinkFilePath resolves the path of a File sink, which must be inside eventSinkFileBaseDir. The existing part
+// of the path is resolved so that a symbolic link can't point outside the directory. File sinks are disabled when
+// eventSinkFileBaseDir is empty.
+func getEventSinkFilePath(path string) (string, error) {
+	baseDir := conf.GetConfigString("eventSinkFileBaseDir")
+	if baseDir == "" {
+		return "", fmt.Errorf("file event sinks are disabled, please set eventSinkFileBaseDir")
+	}
+
+	baseDir, err := filepath.EvalSymlinks(baseDir)
+	if err != nil {
+		return "", err
+	}
+	baseDir, err = filepath.Abs(baseDir)
+	if err != nil {
+		return "", err
+	}
+
+	fullPath := filepath.Join(baseDir, path)
+	if fullPath == baseDir || !isPathInDir(baseDir, fullPath) {
+		return "", fmt.Errorf("the path: %s is outside eventSinkFileBaseDir", path)
+	}
+
+	existingPath := fullPath
+	for {
+		_, err = os.Lstat(existingPath)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		existingPath = filepath.Dir(existingPath)
+	}
+
+	resolvedPath, err := filepath.EvalSymlinks(existingPath)
+	if err != nil {
+		return "", err
+	}
+	if !isPathInDir(baseDir, resolvedPath) {
+		return "", fmt.Errorf("the path: %s is outside eventSinkFileBaseDir", path)
+	}
+
+	relPath, err := filepath.Rel(existingPath, fullPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedPath, relPath), nil
+}
+
+func newFileEventSinkPublisher(sink *EventSink) (EventSinkPublisher, error) {
+	path, err := getEventSinkFilePath(sink.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileEventSinkPublisher{file: file}, nil
+}
+
+func (p *fileEventSinkPublisher) Publish(events []*DomainEvent) error {
+	data := []byte{}
+	for _, event := range events {
+		data = append(data, event.getMessage()...)
+		data = append(data, '\n')
+	}
+
+	_, err := p.file.Write(data)
+	if err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+func (p *fileEventSinkPublisher) Close() error {
+	return p.file.Close()
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// kafkaRestProxyEventSinkPublisher produces the events through the REST proxy of a Kafka-compatible broker, e.g. the
+// Confluent REST Proxy or the Redpanda HTTP Proxy, it doesn't connect to the brokers. The key of a record is the id of
+// the entity so that the events of an entity go to the same partition and keep their order.
+type kafkaRestProxyEventSinkPublisher struct {
+	sink   *EventSink
+	client *http.Client
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaOffset struct {
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+	ErrorCode *int   `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+func newKafkaRestProxyEventSinkPublisher(sink *EventSink) EventSinkPublisher {
+	return &kafkaRestProxyEventSinkPublisher{sink: sink, client: &http.Client{Timeout: eventSinkTimeout}}
+}
+
+func (p *kafkaRestProxyEventSinkPublisher) Publish(events []*DomainEvent) error {
+	records := []*kafkaRecord{}
+	for _, event := range events {
+		records = append(records, &kafkaRecord{Key: event.EntityId, Value: json.RawMessage(event.getMessage())})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+
+	topicUrl := fmt.Sprintf("%s/topics/%s", strings.TrimSuffix(p.sink.Endpoint, "/"), url.PathEscape(p.sink.Topic))
+	req, err := http.NewRequest(http.MethodPost, topicUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if p.sink.Username != "" {
+		req.SetBasicAuth(p.sink.Username, p.sink.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the Kafka REST proxy returned status code: %d, body: %s", resp.StatusCode, respBody)
+	}
+
+	var result struct {
+		Offsets []*kafkaOffset `json:"offsets"`
+	}
+	err = json.Unmarshal(respBody, &result)
+	if err != nil {
+		return err
+	}
+
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil || offset.Error != "" {
+			return fmt.Errorf("the Kafka REST proxy failed to produce a record to partition: %d, error: %s", offset.Partition, offset.Error)
+		}
+	}
+	if len(result.Offsets) != len(records) {
+		return fmt.Errorf("the Kafka REST proxy produced %d of %d records", len(result.Offsets), len(records))
+	}
+	return nil
+}
+
+func (p *kafkaRestProxyEventSinkPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+// natsEventSinkPublisher publishes the events to JetStream, the subject of an event is "<topic>.<event type>" and must
+// be captured by a stream. Each event waits for the acknowledgement of the stream before the next one, and its name
+// is the message id so that the stream drops the duplicates of a retry.
+type natsEventSinkPublisher struct {
+	sink *EventSink
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+// getNatsAuthOption returns the authentication of the sink, the password is the seed of a user NKey when it starts
+// with "SU", otherwise the username and the password are sent to the server
+func getNatsAuthOption(sink *EventSink) (nats.Option, error) {
+	if strings.HasPrefix(sink.Password, "SU") {
+		keyPair, err := nkeys.FromSeed([]byte(sink.Password))
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, err := keyPair.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		return nats.Nkey(publicKey, keyPair.Sign), nil
+	}
+
+	if sink.Username != "" {
+		return nats.UserInfo(sink.Username, sink.Password), nil
+	}
+	return nil, nil
+}
+
+// newNatsEventSinkPublisher connects to the servers of the endpoint, e.g. "nats://host:4222" or "tls://host:4222" for a
+// TLS connection, several servers are separated by commas
+func newNatsEventSinkPublisher(sink *EventSink) (EventSinkPublisher, error) {
+	options := []nats.Option{nats.Name("casdoor"), nats.Timeout(eventSinkTimeout)}
+	authOption, err := getNatsAuthOption(sink)
+	if err != nil {
+		return nil, err
+	}
+	if authOption != nil {
+		options = append(options, authOption)
+	}
+
+	conn, err := nats.Connect(sink.Endpoint, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := conn.JetStream(nats.MaxWait(eventSinkTimeout))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &natsEventSinkPublisher{sink: sink, conn: conn, js: js}, nil
+}
+
+func (p *natsEventSinkPublisher) Publish(events []*DomainEvent) error {
+	for _, event := range events {
+		subject := fmt.Sprintf("%s.%s", p.sink.Topic, event.Type)
+		_, err := p.js.Publish(subject, []byte(event.getMessage()), nats.MsgId(event.Name))
+		if errors.Is(err, nats.ErrNoStreamResponse) {
+			return fmt.Errorf("no JetStream stream captures the subject: %s", subject)
+		}
+		if err != nil {
+			return fmt.Errorf("JetStream refused the event: %s, error: %s", event.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (p *natsEventSinkPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
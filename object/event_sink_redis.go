@@ -0,0 +1,77 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// redisEventSinkPublisher appends the events to a Redis stream with XADD, the stream keeps the order of the events
+type redisEventSinkPublisher struct {
+	sink *EventSink
+	conn redis.Conn
+}
+
+func newRedisEventSinkPublisher(sink *EventSink) (EventSinkPublisher, error) {
+	options := []redis.DialOption{
+		redis.DialConnectTimeout(eventSinkTimeout),
+		redis.DialReadTimeout(eventSinkTimeout),
+		redis.DialWriteTimeout(eventSinkTimeout),
+	}
+	if sink.Password != "" {
+		options = append(options, redis.DialPassword(sink.Password))
+	}
+
+	var conn redis.Conn
+	var err error
+	if strings.HasPrefix(sink.Endpoint, "redis://") || strings.HasPrefix(sink.Endpoint, "rediss://") {
+		conn, err = redis.DialURL(sink.Endpoint, options...)
+	} else {
+		conn, err = redis.Dial("tcp", sink.Endpoint, options...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &redisEventSinkPublisher{sink: sink, conn: conn}, nil
+}
+
+func (p *redisEventSinkPublisher) Publish(events []*DomainEvent) error {
+	for _, event := range events {
+		err := p.conn.Send("XADD", p.sink.Topic, "*", "name", event.Name, "type", event.Type, "entityId", event.EntityId, "message", event.getMessage())
+		if err != nil {
+			return err
+		}
+	}
+
+	err := p.conn.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		_, err = redis.String(p.conn.Receive())
+		if err != nil {
+			return fmt.Errorf("failed to add the event: %s to the Redis stream, error: %s", event.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *redisEventSinkPublisher) Close() error {
+	return p.conn.Close()
+}
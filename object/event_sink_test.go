@@ -0,0 +1,118 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestDomainEvents() []*DomainEvent {
+	return []*DomainEvent{
+		{Id: 1, Name: "e1", Type: DomainEventUserCreated, Organization: "built-in", EntityType: "user", EntityId: "built-in/alice", Data: `{"name":"alice"}`},
+		{Id: 2, Name: "e2", Type: DomainEventRoleChanged, Organization: "org", EntityType: "role", EntityId: "org/admin", Data: `{"action":"created"}`},
+	}
+}
+
+func TestEventSinkIsSubscribed(t *testing.T) {
+	events := getTestDomainEvents()
+
+	sink := &EventSink{}
+	assert.True(t, sink.isSubscribed(events[0]))
+
+	sink = &EventSink{Organization: "built-in", EventTypes: []string{DomainEventRoleChanged}}
+	assert.False(t, sink.isSubscribed(events[0]))
+	assert.False(t, sink.isSubscribed(events[1]))
+
+	sink.Organization = ""
+	assert.True(t, sink.isSubscribed(events[1]))
+}
+
+func TestGetSettledDomainEvents(t *testing.T) {
+	now := time.Now()
+	events := []*DomainEvent{
+		{Id: 5, CreatedTime: now.Format(time.RFC3339)},
+		{Id: 7, CreatedTime: now.Format(time.RFC3339)},
+	}
+	assert.Equal(t, 1, len(getSettledDomainEvents(4, events)))
+	assert.Equal(t, 0, len(getSettledDomainEvents(3, events)))
+
+	events[1].CreatedTime = now.Add(-time.Minute).Format(time.RFC3339)
+	assert.Equal(t, 2, len(getSettledDomainEvents(4, events)))
+}
+
+func TestFileEventSinkPublisher(t *testing.T) {
+	baseDir := t.TempDir()
+	t.Setenv("eventSinkFileBaseDir", baseDir)
+
+	_, err := getEventSinkFilePath("../events.jsonl")
+	assert.NotNil(t, err)
+	assert.Nil(t, os.Symlink(os.TempDir(), filepath.Join(baseDir, "tmp")))
+	_, err = getEventSinkFilePath("tmp/events.jsonl")
+	assert.NotNil(t, err)
+
+	publisher, err := newFileEventSinkPublisher(&EventSink{Type: EventSinkTypeFile, Endpoint: "events/events.jsonl"})
+	assert.Nil(t, err)
+	path := filepath.Join(baseDir, "events", "events.jsonl")
+
+	err = publisher.Publish(getTestDomainEvents())
+	assert.Nil(t, err)
+	assert.Nil(t, publisher.Close())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	message := &DomainEventMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), message))
+	assert.Equal(t, "built-in/alice", message.EntityId)
+	assert.JSONEq(t, `{"name":"alice"}`, string(message.Data))
+}
+
+func TestKafkaRestProxyEventSinkPublisher(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/casdoor-events", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"partition":1,"offset":5}]}`))
+	}))
+	defer server.Close()
+
+	publisher := newKafkaRestProxyEventSinkPublisher(&EventSink{Type: EventSinkTypeKafkaRestProxy, Endpoint: server.URL + "/", Topic: "casdoor-events"})
+	err := publisher.Publish(getTestDomainEvents())
+	assert.Nil(t, err)
+
+	var request struct {
+		Records []*kafkaRecord `json:"records"`
+	}
+	assert.Nil(t, json.Unmarshal(body, &request))
+	assert.Equal(t, 2, len(request.Records))
+	assert.Equal(t, "org/admin", request.Records[1].Key)
+
+	err = publisher.Publish(getTestDomainEvents()[:1])
+	assert.NotNil(t, err)
+}
@@ -45,7 +45,8 @@ type Group struct {
 	HaveChildren bool     `xorm:"-" json:"haveChildren"`
 	Children     []*Group `json:"children,omitempty"`
 
-	IsEnabled bool `json:"isEnabled"`
+	IsEnabled bool   `json:"isEnabled"`
+	Ldap      string `xorm:"varchar(100)" json:"ldap"`
 }
 
 type GroupNode struct{}
@@ -70,6 +71,30 @@ func GetGroups(owner string) ([]*Group, error) {
 	return groups, nil
 }
 
+func GetGroupCountWithFilter(owner string, cond builder.Cond) (int64, error) {
+	return getFilterSession(owner, cond).Count(&Group{})
+}
+
+func GetPaginationGroupsWithFilter(owner string, offset, limit int, cond builder.Cond, sortField, sortOrder string) ([]*Group, error) {
+	groups := []*Group{}
+	session := getFilterSession(owner, cond).Limit(limit, offset)
+	if sortField == "" {
+		sortField = "created_time"
+	}
+	if sortOrder == "descend" {
+		session = session.Desc(util.SnakeString(sortField))
+	} else {
+		session = session.Asc(util.SnakeString(sortField))
+	}
+
+	err := session.Find(&groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
 func GetPaginationGroups(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*Group, error) {
 	groups := []*Group{}
 	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
@@ -152,6 +177,13 @@ func UpdateGroup(id string, group *Group) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		if name != group.Name {
+			renameScimProvisioningObject(owner, ScimProvisioningGroup, name, group.Name)
+		}
+		provisionScimGroup(group)
+	}
+
 	return affected != 0, nil
 }
 
@@ -166,6 +198,10 @@ func AddGroup(group *Group) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		provisionScimGroup(group)
+	}
+
 	return affected != 0, nil
 }
 
@@ -177,6 +213,12 @@ func AddGroups(groups []*Group) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+
+	if affected != 0 {
+		for _, group := range groups {
+			provisionScimGroup(group)
+		}
+	}
 	return affected != 0, nil
 }
 
@@ -186,6 +228,10 @@ func deleteGroup(group *Group) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		deprovisionScimGroup(group)
+	}
+
 	return affected != 0, nil
 }
 
@@ -36,6 +36,26 @@ type Ldap struct {
 	DefaultGroup        string   `xorm:"varchar(100)" json:"defaultGroup"`
 	PasswordType        string   `xorm:"varchar(100)" json:"passwordType"`
 
+	EnableGroupSync bool               `json:"enableGroupSync"`
+	GroupBaseDn     string             `xorm:"varchar(100)" json:"groupBaseDn"`
+	GroupFilter     string             `xorm:"varchar(200)" json:"groupFilter"`
+	RoleMappings    []*LdapRoleMapping `xorm:"mediumtext" json:"roleMappings"`
+	// the groups added to each role by the role mappings, they are removed again once they are no longer mapped
+	RoleMappingGroups map[string][]string `xorm:"mediumtext" json:"roleMappingGroups"`
+
+	SyncMode           string          `xorm:"varchar(100)" json:"syncMode"`
+	SyncCursor         string          `xorm:"mediumtext" json:"syncCursor"`
+	DisabledUserAction string          `xorm:"varchar(100)" json:"disabledUserAction"`
+	RemovedUserAction  string          `xorm:"varchar(100)" json:"removedUserAction"`
+	LastSyncReport     *LdapSyncReport `xorm:"mediumtext" json:"lastSyncReport"`
+	// the time the whole directory was last checked for removed users
+	LastRemovedUserCheck string `xorm:"varchar(100)" json:"lastRemovedUserCheck"`
+
+	EnableWriteBack         bool                    `json:"enableWriteBack"`
+	WriteBackBaseDn         string                  `xorm:"varchar(100)" json:"writeBackBaseDn"`
+	WriteBackAttributes     []*LdapAttributeMapping `xorm:"mediumtext" json:"writeBackAttributes"`
+	WriteBackConflictPolicy string                  `xorm:"varchar(100)" json:"writeBackConflictPolicy"`
+
 	AutoSync int    `json:"autoSync"`
 	LastSync string `xorm:"varchar(100)" json:"lastSync"`
 }
@@ -150,8 +170,17 @@ func UpdateLdap(ldap *Ldap) (bool, error) {
 		ldap.Password = l.Password
 	}
 
+	// the cursor only applies to the entries of the same base DN and filter
+	ldap.SyncCursor = l.SyncCursor
+	if ldap.BaseDn != l.BaseDn || ldap.Filter != l.Filter || ldap.SyncMode != l.SyncMode {
+		ldap.SyncCursor = ""
+	}
+
 	affected, err := ormer.Engine.ID(ldap.Id).Cols("owner", "server_name", "host",
-		"port", "enable_ssl", "username", "password", "base_dn", "filter", "filter_fields", "auto_sync", "default_group", "password_type", "allow_self_signed_cert").Update(ldap)
+		"port", "enable_ssl", "username", "password", "base_dn", "filter", "filter_fields", "auto_sync", "default_group", "password_type", "allow_self_signed_cert",
+		"enable_group_sync", "group_base_dn", "group_filter", "role_mappings",
+		"sync_mode", "sync_cursor", "disabled_user_action", "removed_user_action",
+		"enable_write_back", "write_back_base_dn", "write_back_attributes", "write_back_conflict_policy").Update(ldap)
 	if err != nil {
 		return false, nil
 	}
@@ -86,40 +86,15 @@ func (l *LdapAutoSynchronizer) syncRoutine(ldap *Ldap, stopChan chan struct{}) e
 		case <-ticker.C:
 		}
 
-		err := UpdateLdapSyncTime(ldap.Id)
-		if err != nil {
-			return err
-		}
-
-		// fetch all users
-		conn, err := ldap.GetLdapConn()
+		report, err := RunLdapSync(ldap.Id)
 		if err != nil {
 			logs.Warning(fmt.Sprintf("autoSync failed for %s, error %s", ldap.Id, err))
 			continue
 		}
 
-		users, err := conn.GetLdapUsers(ldap)
-		if err != nil {
-			conn.Close()
-			logs.Warning(fmt.Sprintf("autoSync failed for %s, error %s", ldap.Id, err))
-			continue
+		if len(report.Failed) != 0 {
+			logs.Warning(fmt.Sprintf("ldap autosync for %s has %d failed users", ldap.Id, len(report.Failed)), report.Failed)
 		}
-
-		existed, failed, err := SyncLdapUsers(ldap.Owner, AutoAdjustLdapUser(users), ldap.Id)
-		if err != nil {
-			conn.Close()
-			logs.Warning(fmt.Sprintf("autoSync failed for %s, error %s", ldap.Id, err))
-			continue
-		}
-
-		if len(failed) != 0 {
-			logs.Warning(fmt.Sprintf("ldap autosync,%d new users,but %d user failed during :", len(users)-len(existed)-len(failed), len(failed)), failed)
-			logs.Warning(err.Error())
-		} else {
-			logs.Info(fmt.Sprintf("ldap autosync success, %d new users, %d existing users", len(users)-len(existed), len(existed)))
-		}
-
-		conn.Close()
 	}
 }
 
@@ -0,0 +1,129 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	LdapBindApprovalStatePending  = "Pending"
+	LdapBindApprovalStateApproved = "Approved"
+	LdapBindApprovalStateDenied   = "Denied"
+
+	ldapBindApprovalPollInterval = time.Second
+)
+
+// LdapBindApproval is an out-of-band approval of an LDAP bind, the bind waits until the user approves
+// or denies it from a signed-in session, or until it expires. It is kept in the database so that the user
+// can decide it on any instance.
+type LdapBindApproval struct {
+	Id          string `xorm:"varchar(100) notnull pk" json:"id"`
+	UserId      string `xorm:"varchar(100) index" json:"userId"`
+	ClientIp    string `xorm:"varchar(100)" json:"clientIp"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	ExpireTime  int64  `xorm:"index" json:"expireTime"`
+	State       string `xorm:"varchar(100)" json:"state"`
+}
+
+func NewLdapBindApproval(userId string, clientIp string, timeout time.Duration) *LdapBindApproval {
+	return &LdapBindApproval{
+		Id:          util.GenerateId(),
+		UserId:      userId,
+		ClientIp:    clientIp,
+		CreatedTime: util.GetCurrentTime(),
+		ExpireTime:  time.Now().Add(timeout).Unix(),
+		State:       LdapBindApprovalStatePending,
+	}
+}
+
+func AddLdapBindApproval(approval *LdapBindApproval) error {
+	_, err := ormer.Engine.Where("expire_time < ?", time.Now().Unix()).Delete(&LdapBindApproval{})
+	if err != nil {
+		return err
+	}
+
+	_, err = ormer.Engine.Insert(approval)
+	return err
+}
+
+func getLdapBindApprovalState(id string) (string, error) {
+	approval := LdapBindApproval{Id: id}
+	existed, err := ormer.Engine.Get(&approval)
+	if err != nil {
+		return "", err
+	}
+
+	if !existed {
+		return "", fmt.Errorf("the LDAP bind approval: %s is not found", id)
+	}
+	return approval.State, nil
+}
+
+// Wait polls the approval until it is decided or expires and deletes it, it returns whether the bind is approved
+func (approval *LdapBindApproval) Wait() (bool, error) {
+	defer func() {
+		_, _ = ormer.Engine.ID(approval.Id).Delete(&LdapBindApproval{})
+	}()
+
+	ticker := time.NewTicker(ldapBindApprovalPollInterval)
+	defer ticker.Stop()
+
+	for time.Now().Unix() <= approval.ExpireTime {
+		state, err := getLdapBindApprovalState(approval.Id)
+		if err != nil {
+			return false, err
+		}
+		if state != LdapBindApprovalStatePending {
+			return state == LdapBindApprovalStateApproved, nil
+		}
+
+		<-ticker.C
+	}
+	return false, nil
+}
+
+func GetPendingLdapBindApprovals(userId string) ([]*LdapBindApproval, error) {
+	approvals := []*LdapBindApproval{}
+	err := ormer.Engine.Where("user_id = ? and state = ? and expire_time >= ?", userId, LdapBindApprovalStatePending, time.Now().Unix()).
+		Desc("created_time").Find(&approvals)
+	if err != nil {
+		return nil, err
+	}
+
+	return approvals, nil
+}
+
+// SetLdapBindApprovalState approves or denies a pending bind of the user
+func SetLdapBindApprovalState(id string, userId string, isApproved bool) error {
+	state := LdapBindApprovalStateDenied
+	if isApproved {
+		state = LdapBindApprovalStateApproved
+	}
+
+	affected, err := ormer.Engine.Where("id = ? and user_id = ? and state = ? and expire_time >= ?", id, userId, LdapBindApprovalStatePending, time.Now().Unix()).
+		Cols("state").Update(&LdapBindApproval{State: state})
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("the pending LDAP bind approval: %s is not found", id)
+	}
+	return nil
+}
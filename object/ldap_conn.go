@@ -15,9 +15,7 @@
 package object
 
 import (
-	"crypto/md5"
 	"crypto/tls"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
@@ -27,7 +25,6 @@ import (
 	"github.com/casdoor/casdoor/util"
 	goldap "github.com/go-ldap/ldap/v3"
 	"github.com/thanhpk/randstr"
-	"golang.org/x/text/encoding/unicode"
 )
 
 type LdapConn struct {
@@ -35,11 +32,6 @@ type LdapConn struct {
 	IsAD bool
 }
 
-//type ldapGroup struct {
-//	GidNumber string
-//	Cn        string
-//}
-
 type LdapUser struct {
 	UidNumber string `json:"uidNumber"`
 	Uid       string `json:"uid"`
@@ -58,9 +50,16 @@ type LdapUser struct {
 	RegisteredAddress     string
 	PostalAddress         string
 
-	GroupId  string `json:"groupId"`
-	Address  string `json:"address"`
-	MemberOf string `json:"memberOf"`
+	GroupId   string   `json:"groupId"`
+	Address   string   `json:"address"`
+	MemberOf  string   `json:"memberOf"`
+	MemberOfs []string `json:"memberOfs"`
+	Dn        string   `json:"dn"`
+
+	ModifyTimestamp    string `json:"modifyTimestamp"`
+	UsnChanged         string `json:"usnChanged"`
+	UserAccountControl string `json:"userAccountControl"`
+	IsDeleted          bool   `json:"isDeleted"`
 }
 
 func (ldap *Ldap) GetLdapConn() (c *LdapConn, err error) {
@@ -147,114 +146,107 @@ func isMicrosoftAD(Conn *goldap.Conn) (bool, error) {
 	return isMicrosoft, err
 }
 
-func (l *LdapConn) GetLdapUsers(ldapServer *Ldap) ([]LdapUser, error) {
+func (l *LdapConn) getLdapUserSearchAttributes() []string {
 	SearchAttributes := []string{
 		"uidNumber", "cn", "sn", "gidNumber", "entryUUID", "displayName", "mail", "email",
-		"emailAddress", "telephoneNumber", "mobile", "mobileTelephoneNumber", "registeredAddress", "postalAddress",
+		"emailAddress", "telephoneNumber", "mobile", "mobileTelephoneNumber", "registeredAddress", "postalAddress", "memberOf",
+		"modifyTimestamp",
 	}
 	if l.IsAD {
-		SearchAttributes = append(SearchAttributes, "sAMAccountName")
+		SearchAttributes = append(SearchAttributes, "sAMAccountName", "uSNChanged", "userAccountControl", "isDeleted")
 	} else {
 		SearchAttributes = append(SearchAttributes, "uid")
 	}
+	return SearchAttributes
+}
 
-	searchReq := goldap.NewSearchRequest(ldapServer.BaseDn, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
-		0, 0, false,
-		ldapServer.Filter, SearchAttributes, nil)
-	searchResult, err := l.Conn.SearchWithPaging(searchReq, 100)
+func (l *LdapConn) GetLdapUsers(ldapServer *Ldap) ([]LdapUser, error) {
+	ldapUsers, err := l.searchLdapUsers(ldapServer, ldapServer.Filter)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(searchResult.Entries) == 0 {
+	if len(ldapUsers) == 0 {
 		return nil, errors.New("no result")
 	}
 
+	return ldapUsers, nil
+}
+
+func (l *LdapConn) searchLdapUsers(ldapServer *Ldap, filter string) ([]LdapUser, error) {
+	searchReq := goldap.NewSearchRequest(ldapServer.BaseDn, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		0, 0, false,
+		filter, l.getLdapUserSearchAttributes(), nil)
+	searchResult, err := l.Conn.SearchWithPaging(searchReq, 100)
+	if err != nil {
+		return nil, err
+	}
+
 	var ldapUsers []LdapUser
 	for _, entry := range searchResult.Entries {
-		var user LdapUser
-		for _, attribute := range entry.Attributes {
-			switch attribute.Name {
-			case "uidNumber":
-				user.UidNumber = attribute.Values[0]
-			case "uid":
-				user.Uid = attribute.Values[0]
-			case "sAMAccountName":
-				user.Uid = attribute.Values[0]
-			case "cn":
-				user.Cn = attribute.Values[0]
-			case "gidNumber":
-				user.GidNumber = attribute.Values[0]
-			case "entryUUID":
-				user.Uuid = attribute.Values[0]
-			case "objectGUID":
-				user.Uuid = attribute.Values[0]
-			case "userPrincipalName":
-				user.UserPrincipalName = attribute.Values[0]
-			case "displayName":
-				user.DisplayName = attribute.Values[0]
-			case "mail":
-				user.Mail = attribute.Values[0]
-			case "email":
-				user.Email = attribute.Values[0]
-			case "emailAddress":
-				user.EmailAddress = attribute.Values[0]
-			case "telephoneNumber":
-				user.TelephoneNumber = attribute.Values[0]
-			case "mobile":
-				user.Mobile = attribute.Values[0]
-			case "mobileTelephoneNumber":
-				user.MobileTelephoneNumber = attribute.Values[0]
-			case "registeredAddress":
-				user.RegisteredAddress = attribute.Values[0]
-			case "postalAddress":
-				user.PostalAddress = attribute.Values[0]
-			case "memberOf":
-				user.MemberOf = attribute.Values[0]
-			}
-		}
-		ldapUsers = append(ldapUsers, user)
+		ldapUsers = append(ldapUsers, parseLdapUser(entry))
 	}
 
 	return ldapUsers, nil
 }
 
-// FIXME: The Base DN does not necessarily contain the Group
-//
-//	func (l *ldapConn) GetLdapGroups(baseDn string) (map[string]ldapGroup, error) {
-//		SearchFilter := "(objectClass=posixGroup)"
-//		SearchAttributes := []string{"cn", "gidNumber"}
-//		groupMap := make(map[string]ldapGroup)
-//
-//		searchReq := goldap.NewSearchRequest(baseDn,
-//			goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
-//			SearchFilter, SearchAttributes, nil)
-//		searchResult, err := l.Conn.Search(searchReq)
-//		if err != nil {
-//			return nil, err
-//		}
-//
-//		if len(searchResult.Entries) == 0 {
-//			return nil, errors.New("no result")
-//		}
-//
-//		for _, entry := range searchResult.Entries {
-//			var ldapGroupItem ldapGroup
-//			for _, attribute := range entry.Attributes {
-//				switch attribute.Name {
-//				case "gidNumber":
-//					ldapGroupItem.GidNumber = attribute.Values[0]
-//					break
-//				case "cn":
-//					ldapGroupItem.Cn = attribute.Values[0]
-//					break
-//				}
-//			}
-//			groupMap[ldapGroupItem.GidNumber] = ldapGroupItem
-//		}
-//
-//		return groupMap, nil
-//	}
+func parseLdapUser(entry *goldap.Entry) LdapUser {
+	user := LdapUser{Dn: entry.DN}
+	for _, attribute := range entry.Attributes {
+		if len(attribute.Values) == 0 {
+			continue
+		}
+
+		switch attribute.Name {
+		case "uidNumber":
+			user.UidNumber = attribute.Values[0]
+		case "uid":
+			user.Uid = attribute.Values[0]
+		case "sAMAccountName":
+			user.Uid = attribute.Values[0]
+		case "cn":
+			user.Cn = attribute.Values[0]
+		case "gidNumber":
+			user.GidNumber = attribute.Values[0]
+		case "entryUUID":
+			user.Uuid = attribute.Values[0]
+		case "objectGUID":
+			user.Uuid = attribute.Values[0]
+		case "userPrincipalName":
+			user.UserPrincipalName = attribute.Values[0]
+		case "displayName":
+			user.DisplayName = attribute.Values[0]
+		case "mail":
+			user.Mail = attribute.Values[0]
+		case "email":
+			user.Email = attribute.Values[0]
+		case "emailAddress":
+			user.EmailAddress = attribute.Values[0]
+		case "telephoneNumber":
+			user.TelephoneNumber = attribute.Values[0]
+		case "mobile":
+			user.Mobile = attribute.Values[0]
+		case "mobileTelephoneNumber":
+			user.MobileTelephoneNumber = attribute.Values[0]
+		case "registeredAddress":
+			user.RegisteredAddress = attribute.Values[0]
+		case "postalAddress":
+			user.PostalAddress = attribute.Values[0]
+		case "memberOf":
+			user.MemberOf = attribute.Values[0]
+			user.MemberOfs = attribute.Values
+		case "modifyTimestamp":
+			user.ModifyTimestamp = attribute.Values[0]
+		case "uSNChanged":
+			user.UsnChanged = attribute.Values[0]
+		case "userAccountControl":
+			user.UserAccountControl = attribute.Values[0]
+		case "isDeleted":
+			user.IsDeleted = strings.EqualFold(attribute.Values[0], "TRUE")
+		}
+	}
+	return user
+}
 
 func AutoAdjustLdapUser(users []LdapUser) []LdapUser {
 	res := make([]LdapUser, len(users))
@@ -347,6 +339,14 @@ func SyncLdapUsers(owner string, syncUsers []LdapUser, ldapId string) (existUser
 				Ldap:              syncUser.Uuid,
 			}
 
+			syncedValues := map[string]string{}
+			for column, value := range map[string]string{"display_name": newUser.DisplayName, "email": newUser.Email, "phone": newUser.Phone} {
+				if value != "" {
+					syncedValues[column] = value
+				}
+			}
+			setLdapSyncedValues(newUser, syncedValues)
+
 			if ldap.DefaultGroup != "" {
 				newUser.Groups = []string{ldap.DefaultGroup}
 			}
@@ -411,11 +411,9 @@ func ResetLdapPassword(user *User, oldPassword string, newPassword string, lang
 
 		userDn := searchResult.Entries[0].DN
 
-		var pwdEncoded string
 		modifyPasswordRequest := goldap.NewModifyRequest(userDn, nil)
 		if conn.IsAD {
-			utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
-			pwdEncoded, err := utf16.NewEncoder().String("\"" + newPassword + "\"")
+			pwdEncoded, err := ldapServer.encodeAdPassword(newPassword)
 			if err != nil {
 				conn.Close()
 				return err
@@ -432,21 +430,10 @@ func ResetLdapPassword(user *User, oldPassword string, newPassword string, lang
 			conn.Close()
 			return nil
 		} else {
-			switch ldapServer.PasswordType {
-			case "SSHA":
-				pwdEncoded, err = generateSSHA(newPassword)
-				break
-			case "MD5":
-				md5Byte := md5.Sum([]byte(newPassword))
-				md5Password := base64.StdEncoding.EncodeToString(md5Byte[:])
-				pwdEncoded = "{MD5}" + md5Password
-				break
-			case "Plain":
-				pwdEncoded = newPassword
-				break
-			default:
-				pwdEncoded = newPassword
-				break
+			pwdEncoded, err := ldapServer.encodeLdapPassword(newPassword)
+			if err != nil {
+				conn.Close()
+				return err
 			}
 			modifyPasswordRequest.Replace("userPassword", []string{pwdEncoded})
 		}
@@ -0,0 +1,547 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+const defaultLdapGroupFilter = "(|(objectClass=group)(objectClass=groupOfNames)(objectClass=groupOfUniqueNames)(objectClass=posixGroup))"
+
+type LdapGroup struct {
+	Dn          string   `json:"dn"`
+	Cn          string   `json:"cn"`
+	Description string   `json:"description"`
+	GidNumber   string   `json:"gidNumber"`
+	Members     []string `json:"members"`
+	MemberUids  []string `json:"memberUids"`
+	MemberOf    []string `json:"memberOf"`
+}
+
+// LdapRoleMapping grants a Casdoor role to the members of an LDAP group, the group is matched by its cn or DN
+type LdapRoleMapping struct {
+	Group string `json:"group"`
+	Role  string `json:"role"`
+}
+
+func (ldap *Ldap) getGroupBaseDn() string {
+	if ldap.GroupBaseDn != "" {
+		return ldap.GroupBaseDn
+	}
+	return ldap.BaseDn
+}
+
+func (ldap *Ldap) getGroupFilter() string {
+	if ldap.GroupFilter != "" {
+		return ldap.GroupFilter
+	}
+	return defaultLdapGroupFilter
+}
+
+func (l *LdapConn) GetLdapGroups(ldapServer *Ldap) ([]*LdapGroup, error) {
+	SearchAttributes := []string{"cn", "description", "gidNumber", "member", "uniqueMember", "memberUid", "memberOf"}
+
+	searchReq := goldap.NewSearchRequest(ldapServer.getGroupBaseDn(), goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		0, 0, false,
+		ldapServer.getGroupFilter(), SearchAttributes, nil)
+	searchResult, err := l.Conn.SearchWithPaging(searchReq, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	ldapGroups := []*LdapGroup{}
+	for _, entry := range searchResult.Entries {
+		group := &LdapGroup{Dn: entry.DN}
+		for _, attribute := range entry.Attributes {
+			switch attribute.Name {
+			case "cn":
+				group.Cn = attribute.Values[0]
+			case "description":
+				group.Description = attribute.Values[0]
+			case "gidNumber":
+				group.GidNumber = attribute.Values[0]
+			case "member", "uniqueMember":
+				group.Members = append(group.Members, attribute.Values...)
+			case "memberUid":
+				group.MemberUids = append(group.MemberUids, attribute.Values...)
+			case "memberOf":
+				group.MemberOf = append(group.MemberOf, attribute.Values...)
+			}
+		}
+		ldapGroups = append(ldapGroups, group)
+	}
+
+	return ldapGroups, nil
+}
+
+// normalizeLdapDn returns a DN which can be compared with other DNs, e.g. "CN=Dev, OU=Groups" becomes "cn=dev,ou=groups"
+func normalizeLdapDn(dn string) string {
+	parsedDn, err := goldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+
+	rdns := []string{}
+	for _, rdn := range parsedDn.RDNs {
+		attributes := []string{}
+		for _, attribute := range rdn.Attributes {
+			attributes = append(attributes, fmt.Sprintf("%s=%s", attribute.Type, attribute.Value))
+		}
+		rdns = append(rdns, strings.Join(attributes, "+"))
+	}
+	return strings.ToLower(strings.Join(rdns, ","))
+}
+
+// getLdapGroupParents returns the normalized DN of the parent of each nested group. A Casdoor group has only
+// one parent, so the first parent in DN order is kept when an AD group is nested in several groups.
+func getLdapGroupParents(ldapGroups []*LdapGroup) map[string]string {
+	groupDns := map[string]bool{}
+	for _, group := range ldapGroups {
+		groupDns[normalizeLdapDn(group.Dn)] = true
+	}
+
+	candidates := map[string][]string{}
+	for _, group := range ldapGroups {
+		groupDn := normalizeLdapDn(group.Dn)
+		for _, member := range group.Members {
+			memberDn := normalizeLdapDn(member)
+			if groupDns[memberDn] && memberDn != groupDn {
+				candidates[memberDn] = append(candidates[memberDn], groupDn)
+			}
+		}
+		for _, memberOf := range group.MemberOf {
+			parentDn := normalizeLdapDn(memberOf)
+			if groupDns[parentDn] && parentDn != groupDn {
+				candidates[groupDn] = append(candidates[groupDn], parentDn)
+			}
+		}
+	}
+
+	parents := map[string]string{}
+	for groupDn, parentDns := range candidates {
+		sort.Strings(parentDns)
+		parents[groupDn] = parentDns[0]
+	}
+
+	// cyclic nesting is allowed in AD, a cycle is broken at the group which comes first in DN order
+	groupDnList := []string{}
+	for groupDn := range parents {
+		groupDnList = append(groupDnList, groupDn)
+	}
+	sort.Strings(groupDnList)
+
+	for _, groupDn := range groupDnList {
+		visited := map[string]bool{groupDn: true}
+		for parentDn, ok := parents[groupDn]; ok; parentDn, ok = parents[parentDn] {
+			if visited[parentDn] {
+				delete(parents, groupDn)
+				break
+			}
+			visited[parentDn] = true
+		}
+	}
+	return parents
+}
+
+// getLdapUserGroupDns returns the normalized DNs of the groups each LDAP user is a direct member of, keyed by the
+// LDAP uuid of the user. Memberships are resolved from the member and memberUid attributes of the groups and from
+// the memberOf attribute of the users, which is the only complete source for large AD groups.
+func getLdapUserGroupDns(ldapGroups []*LdapGroup, ldapUsers []LdapUser) map[string][]string {
+	groupDns := map[string]bool{}
+	for _, group := range ldapGroups {
+		groupDns[normalizeLdapDn(group.Dn)] = true
+	}
+
+	uuidsByDn := map[string]string{}
+	uuidsByUid := map[string]string{}
+	for _, user := range ldapUsers {
+		if user.Dn != "" {
+			uuidsByDn[normalizeLdapDn(user.Dn)] = user.GetLdapUuid()
+		}
+		if user.Uid != "" {
+			uuidsByUid[user.Uid] = user.GetLdapUuid()
+		}
+	}
+
+	memberships := map[string]map[string]bool{}
+	addMembership := func(uuid string, groupDn string) {
+		if memberships[uuid] == nil {
+			memberships[uuid] = map[string]bool{}
+		}
+		memberships[uuid][groupDn] = true
+	}
+
+	for _, group := range ldapGroups {
+		groupDn := normalizeLdapDn(group.Dn)
+		for _, member := range group.Members {
+			if uuid, ok := uuidsByDn[normalizeLdapDn(member)]; ok {
+				addMembership(uuid, groupDn)
+			}
+		}
+		for _, memberUid := range group.MemberUids {
+			if uuid, ok := uuidsByUid[memberUid]; ok {
+				addMembership(uuid, groupDn)
+			}
+		}
+	}
+
+	for _, user := range ldapUsers {
+		for _, memberOf := range user.MemberOfs {
+			groupDn := normalizeLdapDn(memberOf)
+			if groupDns[groupDn] {
+				addMembership(user.GetLdapUuid(), groupDn)
+			}
+		}
+	}
+
+	res := map[string][]string{}
+	for uuid, dns := range memberships {
+		for dn := range dns {
+			res[uuid] = append(res[uuid], dn)
+		}
+		sort.Strings(res[uuid])
+	}
+	return res
+}
+
+func getLdapGroupBaseName(group *LdapGroup) string {
+	name := group.Cn
+	if name == "" {
+		parsedDn, err := goldap.ParseDN(group.Dn)
+		if err == nil && len(parsedDn.RDNs) > 0 && len(parsedDn.RDNs[0].Attributes) > 0 {
+			name = parsedDn.RDNs[0].Attributes[0].Value
+		}
+	}
+	return strings.ReplaceAll(name, "/", "_")
+}
+
+// getLdapGroupNames returns the Casdoor group name of each LDAP group keyed by the normalized DN. Group names are
+// unique across organizations, so a name taken by another group gets a suffix derived from the DN.
+func (ldap *Ldap) getLdapGroupNames(ldapGroups []*LdapGroup) (map[string]string, error) {
+	baseNames := []string{}
+	for _, group := range ldapGroups {
+		baseNames = append(baseNames, getLdapGroupBaseName(group))
+	}
+
+	existingGroups := []*Group{}
+	err := ormer.Engine.In("name", baseNames).Find(&existingGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	takenNames := map[string]bool{}
+	for _, group := range existingGroups {
+		if group.Owner != ldap.Owner || group.Ldap != ldap.Id {
+			takenNames[group.Name] = true
+		}
+	}
+
+	res := map[string]string{}
+	usedNames := map[string]bool{}
+	for _, group := range ldapGroups {
+		groupDn := normalizeLdapDn(group.Dn)
+		name := getLdapGroupBaseName(group)
+		if name == "" || takenNames[name] || usedNames[name] || checkGroupName(name) != nil {
+			name = fmt.Sprintf("%s_%s", name, util.GetMd5Hash(groupDn)[:6])
+		}
+		usedNames[name] = true
+		res[groupDn] = name
+	}
+	return res, nil
+}
+
+func SyncLdapGroups(ldapId string) error {
+	ldap, err := GetLdap(ldapId)
+	if err != nil {
+		return err
+	}
+	if ldap == nil {
+		return fmt.Errorf("the LDAP server: %s doesn't exist", ldapId)
+	}
+
+	conn, err := ldap.GetLdapConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ldapUsers, err := conn.GetLdapUsers(ldap)
+	if err != nil {
+		return err
+	}
+
+	return syncLdapGroups(ldap, conn, ldapUsers)
+}
+
+// syncLdapGroups mirrors the LDAP groups into the group tree of the organization, updates the groups of the
+// synced users and grants the mapped roles to the synced groups
+func syncLdapGroups(ldap *Ldap, conn *LdapConn, ldapUsers []LdapUser) error {
+	ldapGroups, err := conn.GetLdapGroups(ldap)
+	if err != nil {
+		return err
+	}
+
+	names, err := ldap.getLdapGroupNames(ldapGroups)
+	if err != nil {
+		return err
+	}
+	parents := getLdapGroupParents(ldapGroups)
+
+	groups, err := GetGroups(ldap.Owner)
+	if err != nil {
+		return err
+	}
+
+	syncedGroups := map[string]*Group{}
+	for _, group := range groups {
+		if group.Ldap == ldap.Id {
+			syncedGroups[group.Name] = group
+		}
+	}
+
+	syncedGroupIds := map[string]bool{}
+	for _, group := range syncedGroups {
+		syncedGroupIds[group.GetId()] = true
+	}
+
+	currentNames := map[string]bool{}
+	for _, ldapGroup := range ldapGroups {
+		groupDn := normalizeLdapDn(ldapGroup.Dn)
+		name := names[groupDn]
+		currentNames[name] = true
+		syncedGroupIds[util.GetId(ldap.Owner, name)] = true
+
+		parentId, isTopGroup := ldap.Owner, true
+		if parentDn, ok := parents[groupDn]; ok {
+			parentId, isTopGroup = names[parentDn], false
+		}
+
+		displayName := ldapGroup.Cn
+		if displayName == "" {
+			displayName = name
+		}
+
+		group, ok := syncedGroups[name]
+		if !ok {
+			_, err = AddGroup(&Group{
+				Owner:       ldap.Owner,
+				Name:        name,
+				CreatedTime: util.GetCurrentTime(),
+				UpdatedTime: util.GetCurrentTime(),
+				DisplayName: displayName,
+				Type:        "Virtual",
+				ParentId:    parentId,
+				IsTopGroup:  isTopGroup,
+				IsEnabled:   true,
+				Ldap:        ldap.Id,
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if group.DisplayName != displayName || group.ParentId != parentId || group.IsTopGroup != isTopGroup {
+			group.DisplayName, group.ParentId, group.IsTopGroup = displayName, parentId, isTopGroup
+			group.UpdatedTime = util.GetCurrentTime()
+			_, err = UpdateGroup(group.GetId(), group)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = syncLdapUserGroups(ldap, ldapGroups, ldapUsers, names, syncedGroupIds)
+	if err != nil {
+		return err
+	}
+
+	err = syncLdapRoleMappings(ldap, ldapGroups, names, parents)
+	if err != nil {
+		return err
+	}
+
+	// groups removed from LDAP are deleted once they have no members and no child groups left
+	for isDeleted := true; isDeleted; {
+		isDeleted = false
+		for name, group := range syncedGroups {
+			if currentNames[name] {
+				continue
+			}
+
+			affected, err := DeleteGroup(group)
+			if err != nil {
+				logs.Warning(fmt.Sprintf("failed to delete LDAP group: %s, error: %s", group.GetId(), err))
+				continue
+			}
+			if affected {
+				delete(syncedGroups, name)
+				isDeleted = true
+			}
+		}
+	}
+
+	return nil
+}
+
+func syncLdapUserGroups(ldap *Ldap, ldapGroups []*LdapGroup, ldapUsers []LdapUser, names map[string]string, syncedGroupIds map[string]bool) error {
+	userGroupDns := getLdapUserGroupDns(ldapGroups, ldapUsers)
+
+	uuids := []string{}
+	for _, ldapUser := range ldapUsers {
+		uuids = append(uuids, ldapUser.GetLdapUuid())
+	}
+	if len(uuids) == 0 {
+		return nil
+	}
+
+	users := []*User{}
+	err := ormer.Engine.Where("owner = ?", ldap.Owner).In("ldap", uuids).Find(&users)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		groupIds := []string{}
+		for _, groupId := range user.Groups {
+			if !syncedGroupIds[groupId] {
+				groupIds = append(groupIds, groupId)
+			}
+		}
+		for _, groupDn := range userGroupDns[user.Ldap] {
+			groupIds = append(groupIds, util.GetId(ldap.Owner, names[groupDn]))
+		}
+
+		if util.IsSameElements(groupIds, user.Groups) {
+			continue
+		}
+
+		user.Groups = groupIds
+		_, err = UpdateUser(user.GetId(), user, []string{"groups"}, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getLdapGroupDnByName(ldapGroups []*LdapGroup, name string) (string, bool) {
+	for _, group := range ldapGroups {
+		if strings.EqualFold(group.Cn, name) || normalizeLdapDn(group.Dn) == normalizeLdapDn(name) {
+			return normalizeLdapDn(group.Dn), true
+		}
+	}
+	return "", false
+}
+
+// syncLdapRoleMappings adds the mapped groups to the roles and removes the groups it added before that are no longer
+// mapped, e.g. after a mapping was deleted, a mapped group includes its nested groups because roles are only matched
+// against the direct groups of a user. The groups added by hand are kept.
+func syncLdapRoleMappings(ldap *Ldap, ldapGroups []*LdapGroup, names map[string]string, parents map[string]string) error {
+	roleGroupIds := map[string][]string{}
+	for roleId := range ldap.RoleMappingGroups {
+		roleGroupIds[roleId] = []string{}
+	}
+
+	for _, mapping := range ldap.RoleMappings {
+		if _, ok := roleGroupIds[mapping.Role]; !ok {
+			roleGroupIds[mapping.Role] = []string{}
+		}
+
+		mappedDn, ok := getLdapGroupDnByName(ldapGroups, mapping.Group)
+		if !ok {
+			logs.Warning(fmt.Sprintf("the LDAP group: %s of the role mapping doesn't exist", mapping.Group))
+			continue
+		}
+
+		for _, ldapGroup := range ldapGroups {
+			groupDn := normalizeLdapDn(ldapGroup.Dn)
+			for dn, ok := groupDn, true; ok; dn, ok = parents[dn] {
+				if dn == mappedDn {
+					roleGroupIds[mapping.Role] = append(roleGroupIds[mapping.Role], util.GetId(ldap.Owner, names[groupDn]))
+					break
+				}
+			}
+		}
+	}
+
+	roleMappingGroups := map[string][]string{}
+	for roleId, groupIds := range roleGroupIds {
+		role, err := GetRole(roleId)
+		if err != nil {
+			return err
+		}
+		if role == nil || role.Owner != ldap.Owner {
+			if _, ok := ldap.RoleMappingGroups[roleId]; ok && !isLdapRoleMapped(ldap, roleId) {
+				// the role of a deleted mapping was deleted too
+				continue
+			}
+			return fmt.Errorf("the role: %s of the LDAP role mapping doesn't exist", roleId)
+		}
+
+		addedGroupIds := []string{}
+		for _, groupId := range ldap.RoleMappingGroups[roleId] {
+			if util.InSlice(groupIds, groupId) && util.InSlice(role.Groups, groupId) {
+				addedGroupIds = append(addedGroupIds, groupId)
+			}
+		}
+
+		newGroupIds := []string{}
+		for _, groupId := range role.Groups {
+			if util.InSlice(groupIds, groupId) || !util.InSlice(ldap.RoleMappingGroups[roleId], groupId) {
+				newGroupIds = append(newGroupIds, groupId)
+			}
+		}
+		for _, groupId := range groupIds {
+			if !util.InSlice(newGroupIds, groupId) {
+				newGroupIds = append(newGroupIds, groupId)
+				addedGroupIds = append(addedGroupIds, groupId)
+			}
+		}
+
+		if len(addedGroupIds) != 0 {
+			roleMappingGroups[roleId] = addedGroupIds
+		}
+
+		if util.IsSameElements(newGroupIds, role.Groups) {
+			continue
+		}
+
+		role.Groups = newGroupIds
+		_, err = UpdateRole(roleId, role)
+		if err != nil {
+			return err
+		}
+	}
+
+	ldap.RoleMappingGroups = roleMappingGroups
+	_, err := ormer.Engine.ID(ldap.Id).Cols("role_mapping_groups").Update(ldap)
+	return err
+}
+
+func isLdapRoleMapped(ldap *Ldap, roleId string) bool {
+	for _, mapping := range ldap.RoleMappings {
+		if mapping.Role == roleId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLdapGroupParents(t *testing.T) {
+	groups := []*LdapGroup{
+		{Dn: "CN=Eng,OU=Groups,DC=example,DC=com", Members: []string{"CN=Dev, OU=Groups,DC=example,DC=com"}},
+		{Dn: "CN=Dev,OU=Groups,DC=example,DC=com", MemberOf: []string{"CN=Staff,OU=Groups,DC=example,DC=com"}},
+		{Dn: "CN=Staff,OU=Groups,DC=example,DC=com"},
+		{Dn: "cn=a,dc=example,dc=com", Members: []string{"cn=b,dc=example,dc=com"}},
+		{Dn: "cn=b,dc=example,dc=com", Members: []string{"cn=a,dc=example,dc=com"}},
+	}
+
+	parents := getLdapGroupParents(groups)
+	assert.Equal(t, map[string]string{
+		"cn=dev,ou=groups,dc=example,dc=com": "cn=eng,ou=groups,dc=example,dc=com",
+		"cn=b,dc=example,dc=com":             "cn=a,dc=example,dc=com",
+	}, parents)
+}
+
+func TestGetLdapUserGroupDns(t *testing.T) {
+	groups := []*LdapGroup{
+		{Dn: "cn=dev,dc=example,dc=com", Members: []string{"uid=alice,dc=example,dc=com"}},
+		{Dn: "cn=ops,dc=example,dc=com", MemberUids: []string{"bob"}},
+	}
+	users := []LdapUser{
+		{Uid: "alice", Uuid: "1", Dn: "UID=alice,DC=example,DC=com"},
+		{Uid: "bob", Uuid: "2", MemberOfs: []string{"cn=dev,dc=example,dc=com", "cn=unknown,dc=example,dc=com"}},
+	}
+
+	assert.Equal(t, map[string][]string{
+		"1": {"cn=dev,dc=example,dc=com"},
+		"2": {"cn=dev,dc=example,dc=com", "cn=ops,dc=example,dc=com"},
+	}, getLdapUserGroupDns(groups, users))
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/cred"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// LdapServiceAccount is a read-only account for binding to the LDAP server, e.g. by a legacy application
+// which looks up users. Its searches are limited to the organizations and base DNs, all are allowed when empty.
+type LdapServiceAccount struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Password      string   `xorm:"varchar(150)" json:"password"`
+	Organizations []string `xorm:"mediumtext" json:"organizations"`
+	BaseDns       []string `xorm:"mediumtext" json:"baseDns"`
+	IsEnabled     bool     `json:"isEnabled"`
+}
+
+func GetLdapServiceAccountCount(owner, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&LdapServiceAccount{})
+}
+
+func GetLdapServiceAccounts(owner string) ([]*LdapServiceAccount, error) {
+	accounts := []*LdapServiceAccount{}
+	err := ormer.Engine.Desc("created_time").Find(&accounts, &LdapServiceAccount{Owner: owner})
+	if err != nil {
+		return accounts, err
+	}
+
+	return accounts, nil
+}
+
+func GetPaginationLdapServiceAccounts(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*LdapServiceAccount, error) {
+	accounts := []*LdapServiceAccount{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&accounts)
+	if err != nil {
+		return accounts, err
+	}
+
+	return accounts, nil
+}
+
+func getLdapServiceAccount(owner string, name string) (*LdapServiceAccount, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	account := LdapServiceAccount{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&account)
+	if err != nil {
+		return &account, err
+	}
+
+	if existed {
+		return &account, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetLdapServiceAccount(id string) (*LdapServiceAccount, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getLdapServiceAccount(owner, name)
+}
+
+func GetMaskedLdapServiceAccount(account *LdapServiceAccount, errs ...error) (*LdapServiceAccount, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	if account == nil {
+		return nil, nil
+	}
+
+	if account.Password != "" {
+		account.Password = "***"
+	}
+	return account, nil
+}
+
+func GetMaskedLdapServiceAccounts(accounts []*LdapServiceAccount, errs ...error) ([]*LdapServiceAccount, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	var err error
+	for _, account := range accounts {
+		account, err = GetMaskedLdapServiceAccount(account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return accounts, nil
+}
+
+func UpdateLdapServiceAccount(id string, account *LdapServiceAccount) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	oldAccount, err := getLdapServiceAccount(owner, name)
+	if err != nil {
+		return false, err
+	} else if oldAccount == nil {
+		return false, nil
+	}
+
+	if account.Password == "***" {
+		account.Password = oldAccount.Password
+	} else {
+		account.hashPassword()
+	}
+
+	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(account)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func AddLdapServiceAccount(account *LdapServiceAccount) (bool, error) {
+	account.hashPassword()
+
+	affected, err := ormer.Engine.Insert(account)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteLdapServiceAccount(account *LdapServiceAccount) (bool, error) {
+	affected, err := ormer.Engine.ID(core.PK{account.Owner, account.Name}).Delete(&LdapServiceAccount{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (account *LdapServiceAccount) GetId() string {
+	return fmt.Sprintf("%s/%s", account.Owner, account.Name)
+}
+
+func (account *LdapServiceAccount) hashPassword() {
+	if account.Password == "" {
+		return
+	}
+	account.Password = cred.NewBcryptCredManager().GetHashedPassword(account.Password, "", "")
+}
+
+func (account *LdapServiceAccount) IsPasswordCorrect(password string) bool {
+	if account.Password == "" || password == "" {
+		return false
+	}
+	return cred.NewBcryptCredManager().IsPasswordCorrect(password, account.Password, "", "")
+}
+
+func (account *LdapServiceAccount) IsOrganizationAllowed(organization string) bool {
+	if len(account.Organizations) == 0 {
+		return true
+	}
+	return util.InSlice(account.Organizations, organization)
+}
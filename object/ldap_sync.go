@@ -0,0 +1,634 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+const (
+	LdapSyncModeFull        = "Full"
+	LdapSyncModeIncremental = "Incremental"
+	LdapSyncModeDirSync     = "DirSync"
+)
+
+const (
+	LdapUserActionDisable    = "Disable"
+	LdapUserActionSoftDelete = "SoftDelete"
+	LdapUserActionDelete     = "Delete"
+
+	// the user property marking the users forbidden by the sync, only they are enabled again by the sync
+	ldapSyncDisabledProperty = "ldapSyncDisabled"
+	// the user property with the values last copied from LDAP, a field is only overwritten when its LDAP value
+	// changed so that the edits made in Casdoor are kept
+	ldapSyncedValuesProperty = "ldapSyncedValues"
+
+	// the incremental syncs can't see the removed users, so the whole directory is checked for them at this interval
+	ldapRemovedUserCheckInterval = 24 * time.Hour
+)
+
+const (
+	ldapCursorModifyTimestamp = "modifyTimestamp"
+	ldapCursorUsnChanged      = "uSNChanged"
+	ldapCursorDirSync         = "dirSync"
+
+	// ACCOUNTDISABLE flag of the AD userAccountControl attribute
+	ldapAccountDisableFlag = 0x2
+)
+
+type LdapSyncFailure struct {
+	Uuid  string `json:"uuid"`
+	Error string `json:"error"`
+}
+
+type LdapSyncReport struct {
+	StartTime     string             `json:"startTime"`
+	EndTime       string             `json:"endTime"`
+	IsIncremental bool               `json:"isIncremental"`
+	Created       int                `json:"created"`
+	Updated       int                `json:"updated"`
+	Skipped       int                `json:"skipped"`
+	Disabled      int                `json:"disabled"`
+	Deleted       int                `json:"deleted"`
+	Failed        []*LdapSyncFailure `json:"failed"`
+	Message       string             `json:"message"`
+}
+
+func (report *LdapSyncReport) addFailure(uuid string, err error) {
+	report.Failed = append(report.Failed, &LdapSyncFailure{Uuid: uuid, Error: err.Error()})
+}
+
+func (report *LdapSyncReport) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d skipped, %d disabled, %d deleted, %d failed",
+		report.Created, report.Updated, report.Skipped, report.Disabled, report.Deleted, len(report.Failed))
+}
+
+func (ldapUser *LdapUser) IsDisabled() bool {
+	if ldapUser.UserAccountControl == "" {
+		return false
+	}
+
+	userAccountControl, err := strconv.ParseInt(ldapUser.UserAccountControl, 10, 64)
+	if err != nil {
+		return false
+	}
+	return userAccountControl&ldapAccountDisableFlag != 0
+}
+
+func getLdapCursor(kind string, value string) string {
+	return fmt.Sprintf("%s:%s", kind, value)
+}
+
+// parseLdapCursor returns the value of the cursor when it was saved for the given kind, a cursor of another
+// kind is ignored so that changing the sync mode starts over with a full sync
+func parseLdapCursor(cursor string, kind string) (string, bool) {
+	prefix := kind + ":"
+	if !strings.HasPrefix(cursor, prefix) || len(cursor) == len(prefix) {
+		return "", false
+	}
+	return cursor[len(prefix):], true
+}
+
+func (l *LdapConn) getLdapCursorKind() string {
+	if l.IsAD {
+		return ldapCursorUsnChanged
+	}
+	return ldapCursorModifyTimestamp
+}
+
+// getLdapUsersCursor returns the highest modifyTimestamp or uSNChanged of the users, or the given cursor
+// when no user has a higher one
+func getLdapUsersCursor(ldapUsers []LdapUser, kind string, cursor string) string {
+	value, _ := parseLdapCursor(cursor, kind)
+	for _, ldapUser := range ldapUsers {
+		switch kind {
+		case ldapCursorUsnChanged:
+			usnChanged, err := strconv.ParseInt(ldapUser.UsnChanged, 10, 64)
+			if err != nil {
+				continue
+			}
+			maxUsnChanged, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || usnChanged > maxUsnChanged {
+				value = ldapUser.UsnChanged
+			}
+		case ldapCursorModifyTimestamp:
+			// generalized times of the same server have the same format, so they compare as strings
+			if ldapUser.ModifyTimestamp > value {
+				value = ldapUser.ModifyTimestamp
+			}
+		}
+	}
+
+	if value == "" {
+		return ""
+	}
+	return getLdapCursor(kind, value)
+}
+
+func getIncrementalLdapFilter(filter string, kind string, value string) string {
+	if kind == ldapCursorUsnChanged {
+		usnChanged, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			value = strconv.FormatInt(usnChanged+1, 10)
+		}
+	}
+
+	if filter == "" {
+		return fmt.Sprintf("(%s>=%s)", kind, goldap.EscapeFilter(value))
+	}
+	return fmt.Sprintf("(&%s(%s>=%s))", filter, kind, goldap.EscapeFilter(value))
+}
+
+// getChangedLdapUsers returns the users changed since the last sync and the cursor of this sync. All users are
+// returned when the sync mode is full or there is no cursor yet. DirSync requires the base DN to be the root
+// of the AD domain.
+func (l *LdapConn) getChangedLdapUsers(ldapServer *Ldap) ([]LdapUser, string, bool, error) {
+	switch ldapServer.SyncMode {
+	case LdapSyncModeDirSync:
+		if !l.IsAD {
+			return nil, "", false, fmt.Errorf("DirSync is only supported by Active Directory")
+		}
+		return l.getDirSyncLdapUsers(ldapServer)
+	case LdapSyncModeIncremental:
+		kind := l.getLdapCursorKind()
+		value, ok := parseLdapCursor(ldapServer.SyncCursor, kind)
+		if ok {
+			ldapUsers, err := l.searchLdapUsers(ldapServer, getIncrementalLdapFilter(ldapServer.Filter, kind, value))
+			if err != nil {
+				return nil, "", false, err
+			}
+			return ldapUsers, getLdapUsersCursor(ldapUsers, kind, ldapServer.SyncCursor), true, nil
+		}
+	}
+
+	ldapUsers, err := l.GetLdapUsers(ldapServer)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return ldapUsers, getLdapUsersCursor(ldapUsers, l.getLdapCursorKind(), ""), false, nil
+}
+
+func (l *LdapConn) getDirSyncLdapUsers(ldapServer *Ldap) ([]LdapUser, string, bool, error) {
+	var cookie []byte
+	value, isIncremental := parseLdapCursor(ldapServer.SyncCursor, ldapCursorDirSync)
+	if isIncremental {
+		var err error
+		cookie, err = base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			cookie, isIncremental = nil, false
+		}
+	}
+
+	ldapUsers := []LdapUser{}
+	for {
+		searchReq := goldap.NewSearchRequest(ldapServer.BaseDn, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+			0, 0, false,
+			ldapServer.Filter, l.getLdapUserSearchAttributes(), nil)
+		searchResult, err := l.Conn.DirSync(searchReq, goldap.DirSyncObjectSecurity, 0, cookie)
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		for _, entry := range searchResult.Entries {
+			ldapUsers = append(ldapUsers, parseLdapUser(entry))
+		}
+
+		control := goldap.FindControl(searchResult.Controls, goldap.ControlTypeDirSync)
+		if control == nil {
+			return nil, "", false, fmt.Errorf("the LDAP server doesn't support DirSync")
+		}
+
+		dirSync := control.(*goldap.ControlDirSync)
+		cookie = dirSync.Cookie
+		if dirSync.Flags == 0 {
+			break
+		}
+	}
+
+	return ldapUsers, getLdapCursor(ldapCursorDirSync, base64.StdEncoding.EncodeToString(cookie)), isIncremental, nil
+}
+
+// applyLdapUserAction disables or deletes a user whose LDAP account was disabled or removed, the returned bool
+// is false when the user is already in that state
+func applyLdapUserAction(user *User, action string) (bool, error) {
+	if user.IsDeleted {
+		return false, nil
+	}
+
+	switch action {
+	case LdapUserActionDisable:
+		if user.IsForbidden {
+			return false, nil
+		}
+		user.IsForbidden = true
+		setLdapSyncDisabled(user, true)
+		return UpdateUser(user.GetId(), user, []string{"is_forbidden", "properties"}, false)
+	case LdapUserActionSoftDelete:
+		user.IsDeleted = true
+		user.DeletedTime = util.GetCurrentTime()
+		return UpdateUser(user.GetId(), user, []string{"is_deleted", "deleted_time"}, false)
+	case LdapUserActionDelete:
+		return DeleteUser(user)
+	default:
+		return false, nil
+	}
+}
+
+// isLdapSyncDisabled reports whether the user was forbidden by the LDAP sync because the LDAP account was disabled
+func isLdapSyncDisabled(user *User) bool {
+	return user.Properties[ldapSyncDisabledProperty] == "true"
+}
+
+func setLdapSyncDisabled(user *User, isDisabled bool) {
+	if !isDisabled {
+		delete(user.Properties, ldapSyncDisabledProperty)
+		return
+	}
+
+	if user.Properties == nil {
+		user.Properties = map[string]string{}
+	}
+	user.Properties[ldapSyncDisabledProperty] = "true"
+}
+
+func (report *LdapSyncReport) countLdapUserAction(action string) {
+	if action == LdapUserActionDisable {
+		report.Disabled++
+	} else {
+		report.Deleted++
+	}
+}
+
+func getLdapSyncedValues(user *User) map[string]string {
+	values := map[string]string{}
+	if user.Properties[ldapSyncedValuesProperty] != "" {
+		err := util.JsonToStruct(user.Properties[ldapSyncedValuesProperty], &values)
+		if err != nil {
+			return map[string]string{}
+		}
+	}
+	return values
+}
+
+func setLdapSyncedValues(user *User, values map[string]string) {
+	if user.Properties == nil {
+		user.Properties = map[string]string{}
+	}
+	user.Properties[ldapSyncedValuesProperty] = util.StructToJson(values)
+}
+
+// updateUserFromLdap copies the LDAP attributes changed since the last sync to the user, attributes missing from
+// the entry are kept because DirSync only returns the changed attributes. A user synced before the values were
+// recorded only gets its empty fields filled.
+func updateUserFromLdap(user *User, ldapUser LdapUser) (bool, error) {
+	adjustedUser := AutoAdjustLdapUser([]LdapUser{ldapUser})[0]
+	syncedValues := getLdapSyncedValues(user)
+
+	fields := []struct {
+		column string
+		field  *string
+		value  string
+	}{
+		{"display_name", &user.DisplayName, adjustedUser.buildLdapDisplayName()},
+		{"email", &user.Email, adjustedUser.Email},
+		{"phone", &user.Phone, adjustedUser.Mobile},
+	}
+
+	columns := []string{}
+	isSyncedValuesChanged := false
+	for _, field := range fields {
+		syncedValue, ok := syncedValues[field.column]
+		if field.value == "" || (ok && syncedValue == field.value) {
+			continue
+		}
+
+		syncedValues[field.column] = field.value
+		isSyncedValuesChanged = true
+		if (!ok && *field.field != "") || *field.field == field.value {
+			continue
+		}
+
+		*field.field = field.value
+		columns = append(columns, field.column)
+	}
+
+	if isSyncedValuesChanged {
+		setLdapSyncedValues(user, syncedValues)
+		columns = append(columns, "properties")
+	}
+
+	if len(columns) == 0 {
+		return false, nil
+	}
+	return UpdateUser(user.GetId(), user, columns, false)
+}
+
+func getUsersByLdapUuids(owner string, uuids []string) (map[string]*User, error) {
+	users := []*User{}
+	if len(uuids) > 0 {
+		err := ormer.Engine.Where("owner = ?", owner).In("ldap", uuids).Find(&users)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := map[string]*User{}
+	for _, user := range users {
+		res[user.Ldap] = user
+	}
+	return res, nil
+}
+
+func syncChangedLdapUsers(ldapServer *Ldap, ldapUsers []LdapUser, report *LdapSyncReport) error {
+	uuids := []string{}
+	for _, ldapUser := range ldapUsers {
+		uuids = append(uuids, ldapUser.GetLdapUuid())
+	}
+
+	users, err := getUsersByLdapUuids(ldapServer.Owner, uuids)
+	if err != nil {
+		return err
+	}
+
+	newUsers := []LdapUser{}
+	for _, ldapUser := range ldapUsers {
+		uuid := ldapUser.GetLdapUuid()
+		user, ok := users[uuid]
+
+		action := ""
+		if ldapUser.IsDeleted {
+			action = ldapServer.RemovedUserAction
+		} else if ldapUser.IsDisabled() {
+			action = ldapServer.DisabledUserAction
+		}
+
+		if ldapUser.IsDeleted || ldapUser.IsDisabled() {
+			if !ok || action == "" {
+				report.Skipped++
+				continue
+			}
+
+			affected, err := applyLdapUserAction(user, action)
+			if err != nil {
+				report.addFailure(uuid, err)
+			} else if affected {
+				report.countLdapUserAction(action)
+			} else {
+				report.Skipped++
+			}
+			continue
+		}
+
+		if !ok {
+			newUsers = append(newUsers, ldapUser)
+			continue
+		}
+
+		affected, err := updateUserFromLdap(user, ldapUser)
+		if err != nil {
+			report.addFailure(uuid, err)
+			continue
+		}
+
+		// users disabled by the sync are enabled again with their LDAP accounts, users forbidden by an admin are kept
+		if user.IsForbidden && isLdapSyncDisabled(user) {
+			user.IsForbidden = false
+			setLdapSyncDisabled(user, false)
+			affected, err = UpdateUser(user.GetId(), user, []string{"is_forbidden", "properties"}, false)
+			if err != nil {
+				report.addFailure(uuid, err)
+				continue
+			}
+		}
+
+		if affected {
+			report.Updated++
+		} else {
+			report.Skipped++
+		}
+	}
+
+	if len(newUsers) == 0 {
+		return nil
+	}
+
+	existUsers, failedUsers, err := SyncLdapUsers(ldapServer.Owner, AutoAdjustLdapUser(newUsers), ldapServer.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, failedUser := range failedUsers {
+		report.addFailure(failedUser.Uuid, fmt.Errorf("failed to add the user: %s", failedUser.Uid))
+	}
+	report.Skipped += len(existUsers)
+	report.Created += len(newUsers) - len(existUsers) - len(failedUsers)
+	return nil
+}
+
+// getOrganizationLdapUuids returns the uuids of the users in all LDAP servers of the organization, so that users
+// imported from another LDAP server aren't treated as removed. The users of this server are given by the caller, the
+// other servers are only read when some users are missing from this server.
+func getOrganizationLdapUuids(ldapServer *Ldap, ldapUsers []LdapUser, users []*User) (map[string]bool, error) {
+	res := map[string]bool{}
+	for _, ldapUser := range ldapUsers {
+		if !ldapUser.IsDeleted {
+			res[ldapUser.GetLdapUuid()] = true
+		}
+	}
+
+	isAllFound := true
+	for _, user := range users {
+		if !res[user.Ldap] {
+			isAllFound = false
+			break
+		}
+	}
+	if isAllFound {
+		return res, nil
+	}
+
+	ldaps, err := GetLdaps(ldapServer.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ldap := range ldaps {
+		if ldap.Id == ldapServer.Id {
+			continue
+		}
+
+		conn, err := ldap.GetLdapConn()
+		if err != nil {
+			return nil, err
+		}
+		otherLdapUsers, err := conn.GetLdapUsers(ldap)
+		conn.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ldapUser := range otherLdapUsers {
+			if !ldapUser.IsDeleted {
+				res[ldapUser.GetLdapUuid()] = true
+			}
+		}
+	}
+	return res, nil
+}
+
+// isLdapRemovedUserCheckDue reports whether the sync has to look for removed users. DirSync returns the removed
+// users with the changes, and a full sync has read the whole directory anyway, while an incremental sync only checks
+// for them once per ldapRemovedUserCheckInterval because it has to read the whole directory.
+func isLdapRemovedUserCheckDue(ldapServer *Ldap, isIncremental bool, now time.Time) bool {
+	if !isIncremental {
+		return true
+	}
+	if ldapServer.SyncMode == LdapSyncModeDirSync {
+		return false
+	}
+
+	lastCheckTime, err := time.Parse(time.RFC3339, ldapServer.LastRemovedUserCheck)
+	if err != nil {
+		return true
+	}
+	return !now.Before(lastCheckTime.Add(ldapRemovedUserCheckInterval))
+}
+
+// syncRemovedLdapUsers applies the removed user action to the users that are in none of the LDAP servers of the
+// organization, ldapUsers are all the users of this server when they were read by a full sync, otherwise nil
+func syncRemovedLdapUsers(ldapServer *Ldap, conn *LdapConn, ldapUsers []LdapUser, report *LdapSyncReport) error {
+	users := []*User{}
+	err := ormer.Engine.Where("owner = ? and ldap != ?", ldapServer.Owner, "").Find(&users)
+	if err != nil {
+		return err
+	}
+
+	if ldapUsers == nil {
+		ldapUsers, err = conn.GetLdapUsers(ldapServer)
+	}
+	var uuids map[string]bool
+	if err == nil {
+		uuids, err = getOrganizationLdapUuids(ldapServer, ldapUsers, users)
+	}
+	if err != nil {
+		// an unreachable or empty directory must not remove all the users
+		report.Message = fmt.Sprintf("removed users are not checked: %s", err.Error())
+		return nil
+	}
+
+	for _, user := range users {
+		if uuids[user.Ldap] {
+			continue
+		}
+
+		affected, err := applyLdapUserAction(user, ldapServer.RemovedUserAction)
+		if err != nil {
+			report.addFailure(user.Ldap, err)
+		} else if affected {
+			report.countLdapUserAction(ldapServer.RemovedUserAction)
+		}
+	}
+
+	ldapServer.LastRemovedUserCheck = util.GetCurrentTime()
+	return nil
+}
+
+// SyncLdap runs a sync of the LDAP server: changed users are created or updated, disabled and removed users
+// are handled by the configured actions, and the cursor and report of the run are saved
+func SyncLdap(ldapServer *Ldap, conn *LdapConn) (*LdapSyncReport, error) {
+	report := &LdapSyncReport{StartTime: util.GetCurrentTime(), Failed: []*LdapSyncFailure{}}
+
+	ldapUsers, cursor, isIncremental, err := conn.getChangedLdapUsers(ldapServer)
+	if err != nil {
+		return nil, err
+	}
+	report.IsIncremental = isIncremental
+
+	err = syncChangedLdapUsers(ldapServer, ldapUsers, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if ldapServer.RemovedUserAction != "" && isLdapRemovedUserCheckDue(ldapServer, isIncremental, time.Now()) {
+		allLdapUsers := ldapUsers
+		if isIncremental {
+			allLdapUsers = nil
+		}
+
+		err = syncRemovedLdapUsers(ldapServer, conn, allLdapUsers, report)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report.EndTime = util.GetCurrentTime()
+	ldapServer.SyncCursor = cursor
+	ldapServer.LastSyncReport = report
+	_, err = ormer.Engine.ID(ldapServer.Id).Cols("sync_cursor", "last_sync_report", "last_removed_user_check").Update(ldapServer)
+	if err != nil {
+		return nil, err
+	}
+
+	logs.Info(fmt.Sprintf("ldap sync for %s: %s", ldapServer.Id, report))
+	return report, nil
+}
+
+func RunLdapSync(ldapId string) (*LdapSyncReport, error) {
+	ldap, err := GetLdap(ldapId)
+	if err != nil {
+		return nil, err
+	}
+	if ldap == nil {
+		return nil, fmt.Errorf("the LDAP server: %s doesn't exist", ldapId)
+	}
+
+	err = UpdateLdapSyncTime(ldapId)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := ldap.GetLdapConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	report, err := SyncLdap(ldap, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if ldap.EnableGroupSync {
+		ldapUsers, err := conn.GetLdapUsers(ldap)
+		if err != nil {
+			return nil, err
+		}
+
+		err = syncLdapGroups(ldap, conn, ldapUsers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
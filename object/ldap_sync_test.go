@@ -0,0 +1,69 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLdapUsersCursor(t *testing.T) {
+	users := []LdapUser{{UsnChanged: "99"}, {UsnChanged: "120"}, {UsnChanged: ""}}
+	assert.Equal(t, "uSNChanged:120", getLdapUsersCursor(users, ldapCursorUsnChanged, "uSNChanged:100"))
+	assert.Equal(t, "uSNChanged:200", getLdapUsersCursor(users, ldapCursorUsnChanged, "uSNChanged:200"))
+	assert.Equal(t, "", getLdapUsersCursor(nil, ldapCursorModifyTimestamp, "uSNChanged:200"))
+
+	users = []LdapUser{{ModifyTimestamp: "20250102000000Z"}, {ModifyTimestamp: "20250101000000Z"}}
+	assert.Equal(t, "modifyTimestamp:20250102000000Z", getLdapUsersCursor(users, ldapCursorModifyTimestamp, ""))
+
+	assert.Equal(t, "(&(objectClass=person)(uSNChanged>=121))", getIncrementalLdapFilter("(objectClass=person)", ldapCursorUsnChanged, "120"))
+	assert.Equal(t, "(modifyTimestamp>=20250102000000Z)", getIncrementalLdapFilter("", ldapCursorModifyTimestamp, "20250102000000Z"))
+}
+
+func TestLdapUserIsDisabled(t *testing.T) {
+	assert.True(t, (&LdapUser{UserAccountControl: "514"}).IsDisabled())
+	assert.False(t, (&LdapUser{UserAccountControl: "512"}).IsDisabled())
+	assert.False(t, (&LdapUser{}).IsDisabled())
+}
+
+func TestSetLdapSyncDisabled(t *testing.T) {
+	user := &User{}
+	assert.False(t, isLdapSyncDisabled(user))
+
+	setLdapSyncDisabled(user, true)
+	assert.True(t, isLdapSyncDisabled(user))
+
+	setLdapSyncDisabled(user, false)
+	assert.False(t, isLdapSyncDisabled(user))
+}
+
+func TestIsLdapRemovedUserCheckDue(t *testing.T) {
+	now := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	ldap := &Ldap{SyncMode: LdapSyncModeIncremental}
+	assert.True(t, isLdapRemovedUserCheckDue(ldap, false, now))
+	assert.True(t, isLdapRemovedUserCheckDue(ldap, true, now))
+
+	ldap.LastRemovedUserCheck = now.Add(-time.Hour).Format(time.RFC3339)
+	assert.True(t, isLdapRemovedUserCheckDue(ldap, false, now))
+	assert.False(t, isLdapRemovedUserCheckDue(ldap, true, now))
+
+	ldap.LastRemovedUserCheck = now.Add(-ldapRemovedUserCheckInterval).Format(time.RFC3339)
+	assert.True(t, isLdapRemovedUserCheckDue(ldap, true, now))
+
+	ldap.SyncMode = LdapSyncModeDirSync
+	assert.False(t, isLdapRemovedUserCheckDue(ldap, true, now))
+}
@@ -0,0 +1,456 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/xorm-io/core"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const (
+	LdapConflictPolicySkip      = "Skip"
+	LdapConflictPolicyOverwrite = "Overwrite"
+)
+
+const (
+	ldapWriteBackAttempts      = 3
+	ldapWriteBackRetryInterval = time.Second
+
+	// userAccountControl values of a normal AD account, the account is disabled until it has a password
+	ldapAdNormalAccount   = "512"
+	ldapAdDisabledAccount = "514"
+)
+
+// LdapAttributeMapping maps a user field of Casdoor to an LDAP attribute for write-back
+type LdapAttributeMapping struct {
+	Field     string `json:"field"`
+	Attribute string `json:"attribute"`
+}
+
+var defaultLdapWriteBackAttributes = []*LdapAttributeMapping{
+	{Field: "displayName", Attribute: "displayName"},
+	{Field: "firstName", Attribute: "givenName"},
+	{Field: "lastName", Attribute: "sn"},
+	{Field: "email", Attribute: "mail"},
+	{Field: "phone", Attribute: "mobile"},
+	{Field: "title", Attribute: "title"},
+}
+
+func (ldap *Ldap) getWriteBackAttributes() []*LdapAttributeMapping {
+	if len(ldap.WriteBackAttributes) > 0 {
+		return ldap.WriteBackAttributes
+	}
+	return defaultLdapWriteBackAttributes
+}
+
+func (ldap *Ldap) getWriteBackBaseDn() string {
+	if ldap.WriteBackBaseDn != "" {
+		return ldap.WriteBackBaseDn
+	}
+	return ldap.BaseDn
+}
+
+// getLdapWriteBackField returns the value and the column of the user field
+// ldapWriteBackColumns are the columns of the fields that can be written back, an update of all the fields of a user
+// changes all of them
+var ldapWriteBackColumns = []string{"display_name", "first_name", "last_name", "email", "phone", "title", "affiliation", "location"}
+
+func getLdapWriteBackField(user *User, field string) (string, string, bool) {
+	switch field {
+	case "displayName":
+		return user.DisplayName, "display_name", true
+	case "firstName":
+		return user.FirstName, "first_name", true
+	case "lastName":
+		return user.LastName, "last_name", true
+	case "email":
+		return user.Email, "email", true
+	case "phone":
+		return user.Phone, "phone", true
+	case "title":
+		return user.Title, "title", true
+	case "affiliation":
+		return user.Affiliation, "affiliation", true
+	case "location":
+		return user.Location, "location", true
+	default:
+		return "", "", false
+	}
+}
+
+func (ldap *Ldap) encodeLdapPassword(password string) (string, error) {
+	switch ldap.PasswordType {
+	case "SSHA":
+		return generateSSHA(password)
+	case "MD5":
+		md5Byte := md5.Sum([]byte(password))
+		return "{MD5}" + base64.StdEncoding.EncodeToString(md5Byte[:]), nil
+	default:
+		return password, nil
+	}
+}
+
+// encodeAdPassword encodes the password for the unicodePwd attribute
+func (ldap *Ldap) encodeAdPassword(password string) (string, error) {
+	utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	return utf16.NewEncoder().String("\"" + password + "\"")
+}
+
+// requireLdapTls starts TLS on a plain connection, AD only accepts the unicodePwd attribute over LDAPS or StartTLS
+func (ldap *Ldap) requireLdapTls(conn *LdapConn) error {
+	if _, ok := conn.Conn.TLSConnectionState(); ok {
+		return nil
+	}
+
+	tlsConfig := tls.Config{
+		InsecureSkipVerify: ldap.AllowSelfSignedCert,
+		ServerName:         ldap.Host,
+	}
+	err := conn.Conn.StartTLS(&tlsConfig)
+	if err != nil {
+		return fmt.Errorf("the password of an Active Directory user can only be set over LDAPS or StartTLS, StartTLS to the LDAP server: %s failed: %w", ldap.ServerName, err)
+	}
+	return nil
+}
+
+func isRetryableLdapError(err error) bool {
+	return goldap.IsErrorAnyOf(err, goldap.ErrorNetwork, goldap.LDAPResultBusy, goldap.LDAPResultUnavailable)
+}
+
+// withLdapRetry runs the operation on a new connection, and runs it again when the server is busy or unreachable
+func (ldap *Ldap) withLdapRetry(operation func(conn *LdapConn) error) error {
+	var err error
+	for attempt := 1; attempt <= ldapWriteBackAttempts; attempt++ {
+		var conn *LdapConn
+		conn, err = ldap.GetLdapConn()
+		if err == nil {
+			err = operation(conn)
+			conn.Conn.Close()
+		}
+
+		if err == nil || !isRetryableLdapError(err) {
+			return err
+		}
+		if attempt < ldapWriteBackAttempts {
+			time.Sleep(time.Duration(attempt) * ldapWriteBackRetryInterval)
+		}
+	}
+	return err
+}
+
+// findLdapUserEntry returns the entry of the user with the LDAP uuid, nil if the user isn't in the directory
+func (l *LdapConn) findLdapUserEntry(ldapServer *Ldap, uuid string, attributes []string) (*goldap.Entry, error) {
+	escapedUuid := goldap.EscapeFilter(uuid)
+	filter := fmt.Sprintf("(|(entryUUID=%s)(uid=%s)(cn=%s))", escapedUuid, escapedUuid, escapedUuid)
+	if l.IsAD {
+		filter = fmt.Sprintf("(|(sAMAccountName=%s)(cn=%s))", escapedUuid, escapedUuid)
+	}
+	if ldapServer.Filter != "" {
+		filter = fmt.Sprintf("(&%s%s)", ldapServer.Filter, filter)
+	}
+
+	searchReq := goldap.NewSearchRequest(ldapServer.BaseDn, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		0, 0, false, filter, attributes, nil)
+	searchResult, err := l.Conn.Search(searchReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(searchResult.Entries) == 0 {
+		return nil, nil
+	}
+	if len(searchResult.Entries) > 1 {
+		return nil, fmt.Errorf("multiple LDAP entries are found for the user: %s", uuid)
+	}
+	return searchResult.Entries[0], nil
+}
+
+func getWriteBackLdaps(owner string) ([]*Ldap, error) {
+	ldaps, err := GetLdaps(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*Ldap{}
+	for _, ldap := range ldaps {
+		if ldap.EnableWriteBack {
+			res = append(res, ldap)
+		}
+	}
+	return res, nil
+}
+
+// getChangedLdapAttributes returns the old and new values of the mapped attributes whose user fields are updated
+func (ldap *Ldap) getChangedLdapAttributes(oldUser *User, user *User, columns []string) (map[string]string, map[string]string) {
+	oldValues, newValues := map[string]string{}, map[string]string{}
+	for _, mapping := range ldap.getWriteBackAttributes() {
+		value, column, ok := getLdapWriteBackField(user, mapping.Field)
+		if !ok || !util.InSlice(columns, column) {
+			continue
+		}
+
+		oldValue, _, _ := getLdapWriteBackField(oldUser, mapping.Field)
+		if value != oldValue {
+			oldValues[mapping.Attribute], newValues[mapping.Attribute] = oldValue, value
+		}
+	}
+	return oldValues, newValues
+}
+
+// buildLdapModifyRequest returns the modify request of the changed attributes. An attribute changed in LDAP since
+// the last sync is a conflict, it's only overwritten when the conflict policy is Overwrite.
+func (ldap *Ldap) buildLdapModifyRequest(entry *goldap.Entry, oldValues map[string]string, newValues map[string]string) *goldap.ModifyRequest {
+	attributes := []string{}
+	for attribute := range newValues {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	req := goldap.NewModifyRequest(entry.DN, nil)
+	for _, attribute := range attributes {
+		value := newValues[attribute]
+		currentValue := entry.GetEqualFoldAttributeValue(attribute)
+		if currentValue == value {
+			continue
+		}
+
+		if currentValue != oldValues[attribute] && ldap.WriteBackConflictPolicy != LdapConflictPolicyOverwrite {
+			logs.Warning(fmt.Sprintf("ldap write-back of %s is skipped for %s, it was changed in LDAP to: %s", attribute, entry.DN, currentValue))
+			continue
+		}
+
+		if value == "" {
+			req.Delete(attribute, nil)
+		} else {
+			req.Replace(attribute, []string{value})
+		}
+	}
+	return req
+}
+
+func (ldap *Ldap) writeBackUserAttributes(uuid string, oldValues map[string]string, newValues map[string]string) error {
+	attributes := []string{}
+	for attribute := range newValues {
+		attributes = append(attributes, attribute)
+	}
+
+	return ldap.withLdapRetry(func(conn *LdapConn) error {
+		entry, err := conn.findLdapUserEntry(ldap, uuid, attributes)
+		if err != nil || entry == nil {
+			return err
+		}
+
+		req := ldap.buildLdapModifyRequest(entry, oldValues, newValues)
+		if len(req.Changes) == 0 {
+			return nil
+		}
+		return conn.Conn.Modify(req)
+	})
+}
+
+// writeBackUpdatedUser propagates the profile changes of an LDAP user to the LDAP servers with write-back enabled,
+// it runs in the background so that a slow or unreachable directory doesn't block the update
+func writeBackUpdatedUser(oldUser *User, user *User, columns []string) {
+	if user.Ldap == "" {
+		return
+	}
+
+	ldaps, err := getWriteBackLdaps(user.Owner)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("ldap write-back failed for %s, error %s", user.GetId(), err))
+		return
+	}
+
+	for _, ldap := range ldaps {
+		oldValues, newValues := ldap.getChangedLdapAttributes(oldUser, user, columns)
+		if len(newValues) == 0 {
+			continue
+		}
+
+		ldap, uuid := ldap, user.Ldap
+		util.SafeGoroutine(func() {
+			err := ldap.writeBackUserAttributes(uuid, oldValues, newValues)
+			if err != nil {
+				logs.Warning(fmt.Sprintf("ldap write-back to %s failed for %s, error %s", ldap.Id, uuid, err))
+			}
+		})
+	}
+}
+
+func (ldap *Ldap) buildLdapAddRequest(user *User, password string, isAD bool) (*goldap.AddRequest, error) {
+	rdnAttribute := "uid"
+	values := map[string]string{"uid": user.Name, "cn": user.Name, "sn": user.Name}
+	objectClasses := []string{"top", "person", "organizationalPerson", "inetOrgPerson"}
+	if isAD {
+		rdnAttribute = "cn"
+		values = map[string]string{"sAMAccountName": user.Name, "cn": user.Name, "sn": user.Name, "userAccountControl": ldapAdDisabledAccount}
+		objectClasses = []string{"top", "person", "organizationalPerson", "user"}
+	}
+
+	for _, mapping := range ldap.getWriteBackAttributes() {
+		value, _, ok := getLdapWriteBackField(user, mapping.Field)
+		if ok && value != "" {
+			values[mapping.Attribute] = value
+		}
+	}
+
+	if !isAD && password != "" {
+		encodedPassword, err := ldap.encodeLdapPassword(password)
+		if err != nil {
+			return nil, err
+		}
+		values["userPassword"] = encodedPassword
+	}
+
+	dn := fmt.Sprintf("%s=%s,%s", rdnAttribute, goldap.EscapeDN(user.Name), ldap.getWriteBackBaseDn())
+	req := goldap.NewAddRequest(dn, nil)
+	req.Attribute("objectClass", objectClasses)
+
+	attributes := []string{}
+	for attribute := range values {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+	for _, attribute := range attributes {
+		req.Attribute(attribute, []string{values[attribute]})
+	}
+	return req, nil
+}
+
+// addLdapUser creates the user in LDAP and returns the LDAP uuid of the new entry. An existing entry with the same
+// name is a conflict, it's only taken over by the user when the conflict policy is Overwrite.
+func (ldap *Ldap) addLdapUser(user *User, password string) (string, error) {
+	dn := ""
+	isAD := false
+	err := ldap.withLdapRetry(func(conn *LdapConn) error {
+		isAD = conn.IsAD
+
+		entry, err := conn.findLdapUserEntry(ldap, user.Name, []string{"*"})
+		if err != nil {
+			return err
+		}
+
+		if entry != nil {
+			if ldap.WriteBackConflictPolicy != LdapConflictPolicyOverwrite {
+				return fmt.Errorf("the LDAP entry: %s already exists", entry.DN)
+			}
+
+			dn = entry.DN
+			oldValues, newValues := map[string]string{}, map[string]string{}
+			for _, mapping := range ldap.getWriteBackAttributes() {
+				if value, _, ok := getLdapWriteBackField(user, mapping.Field); ok && value != "" {
+					oldValues[mapping.Attribute], newValues[mapping.Attribute] = entry.GetEqualFoldAttributeValue(mapping.Attribute), value
+				}
+			}
+
+			req := ldap.buildLdapModifyRequest(entry, oldValues, newValues)
+			if len(req.Changes) == 0 {
+				return nil
+			}
+			return conn.Conn.Modify(req)
+		}
+
+		req, err := ldap.buildLdapAddRequest(user, password, conn.IsAD)
+		if err != nil {
+			return err
+		}
+
+		dn = req.DN
+		return conn.Conn.Add(req)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if isAD && password != "" {
+		encodedPassword, err := ldap.encodeAdPassword(password)
+		if err != nil {
+			return "", err
+		}
+
+		err = ldap.withLdapRetry(func(conn *LdapConn) error {
+			err := ldap.requireLdapTls(conn)
+			if err != nil {
+				return err
+			}
+
+			req := goldap.NewModifyRequest(dn, nil)
+			req.Replace("unicodePwd", []string{encodedPassword})
+			req.Replace("userAccountControl", []string{ldapAdNormalAccount})
+			return conn.Conn.Modify(req)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	uuid := ""
+	err = ldap.withLdapRetry(func(conn *LdapConn) error {
+		entry, err := conn.findLdapUserEntry(ldap, user.Name, conn.getLdapUserSearchAttributes())
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return fmt.Errorf("the LDAP entry: %s is not found", dn)
+		}
+
+		ldapUser := parseLdapUser(entry)
+		uuid = ldapUser.GetLdapUuid()
+		return nil
+	})
+	return uuid, err
+}
+
+// writeBackNewUser creates a user added in Casdoor in the first LDAP server with write-back enabled and links
+// the user to the new entry, users imported from LDAP are already linked and skipped
+func writeBackNewUser(user *User, password string) {
+	if user.Ldap != "" {
+		return
+	}
+
+	ldaps, err := getWriteBackLdaps(user.Owner)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("ldap write-back failed for %s, error %s", user.GetId(), err))
+		return
+	}
+	if len(ldaps) == 0 {
+		return
+	}
+
+	ldap, newUser := ldaps[0], *user
+	owner, name := newUser.Owner, newUser.Name
+	util.SafeGoroutine(func() {
+		uuid, err := ldap.addLdapUser(&newUser, password)
+		if err != nil {
+			logs.Warning(fmt.Sprintf("ldap write-back to %s failed for new user %s/%s, error %s", ldap.Id, owner, name, err))
+			return
+		}
+
+		_, err = ormer.Engine.ID(core.PK{owner, name}).Cols("ldap").Update(&User{Ldap: uuid})
+		if err != nil {
+			logs.Warning(fmt.Sprintf("failed to link the user %s/%s to LDAP, error %s", owner, name, err))
+		}
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildLdapModifyRequest(t *testing.T) {
+	oldUser := &User{DisplayName: "Alice", Email: "alice@example.com", Phone: "123"}
+	user := &User{DisplayName: "Alice Liddell", Email: "alice@example.org", Phone: "456"}
+
+	ldap := &Ldap{}
+	oldValues, newValues := ldap.getChangedLdapAttributes(oldUser, user, []string{"display_name", "email"})
+	assert.Equal(t, map[string]string{"displayName": "Alice Liddell", "mail": "alice@example.org"}, newValues)
+
+	// mail was changed in LDAP since the last sync
+	entry := goldap.NewEntry("uid=alice,dc=example,dc=com", map[string][]string{
+		"displayName": {"Alice"},
+		"mail":        {"alice@corp.example.com"},
+	})
+
+	req := ldap.buildLdapModifyRequest(entry, oldValues, newValues)
+	assert.Equal(t, 1, len(req.Changes))
+	assert.Equal(t, "displayName", req.Changes[0].Modification.Type)
+
+	ldap.WriteBackConflictPolicy = LdapConflictPolicyOverwrite
+	req = ldap.buildLdapModifyRequest(entry, oldValues, newValues)
+	assert.Equal(t, 2, len(req.Changes))
+}
+
+func TestBuildLdapAddRequest(t *testing.T) {
+	ldap := &Ldap{BaseDn: "dc=example,dc=com", WriteBackBaseDn: "ou=people,dc=example,dc=com"}
+	user := &User{Name: "bob", DisplayName: "Bob", Email: "bob@example.com"}
+
+	req, err := ldap.buildLdapAddRequest(user, "123", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "uid=bob,ou=people,dc=example,dc=com", req.DN)
+
+	values := map[string][]string{}
+	for _, attribute := range req.Attributes {
+		values[attribute.Type] = attribute.Vals
+	}
+	assert.Equal(t, []string{"bob@example.com"}, values["mail"])
+	assert.Equal(t, []string{"123"}, values["userPassword"])
+
+	req, err = ldap.buildLdapAddRequest(user, "123", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "cn=bob,ou=people,dc=example,dc=com", req.DN)
+	for _, attribute := range req.Attributes {
+		assert.NotEqual(t, "userPassword", attribute.Type)
+	}
+
+	encodedPassword, err := ldap.encodeAdPassword("123")
+	assert.Nil(t, err)
+	assert.Equal(t, "\"\x001\x002\x003\x00\"\x00", encodedPassword)
+}
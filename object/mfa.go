@@ -44,9 +44,10 @@ const (
 )
 
 const (
-	MfaSessionUserId = "MfaSessionUserId"
-	NextMfa          = "NextMfa"
-	RequiredMfa      = "RequiredMfa"
+	MfaSessionUserId         = "MfaSessionUserId"
+	MfaVerifiedSessionUserId = "MfaVerifiedSessionUserId"
+	NextMfa                  = "NextMfa"
+	RequiredMfa              = "RequiredMfa"
 )
 
 func GetMfaUtil(mfaType string, config *MfaProps) MfaInterface {
@@ -183,3 +184,90 @@ func SetPreferredMultiFactorAuth(user *User, mfaType string) error {
 	}
 	return nil
 }
+
+// SendMfaCode sends the one-time code of an SMS or email MFA through the providers of the user's default
+// application, e.g. for the RADIUS and TACACS+ servers which have no application, and returns the masked destination
+func SendMfaCode(user *User, mfaType string, remoteAddr string) (string, error) {
+	organization, err := GetOrganizationByUser(user)
+	if err != nil {
+		return "", err
+	}
+	if organization == nil {
+		return "", fmt.Errorf("the organization: %s doesn't exist", user.Owner)
+	}
+
+	application, err := GetDefaultApplication(util.GetId("admin", user.Owner))
+	if err != nil {
+		return "", err
+	}
+
+	switch mfaType {
+	case EmailType:
+		provider, err := application.GetEmailProvider("mfaAuth")
+		if err != nil {
+			return "", err
+		}
+		if provider == nil {
+			return "", fmt.Errorf("please add an Email provider to the application: %s", application.Name)
+		}
+
+		err = SendVerificationCodeToEmail(organization, user, provider, remoteAddr, user.Email)
+		if err != nil {
+			return "", err
+		}
+		return util.GetMaskedEmail(user.Email), nil
+	case SmsType:
+		provider, err := application.GetSmsProvider("mfaAuth", user.CountryCode)
+		if err != nil {
+			return "", err
+		}
+		if provider == nil {
+			return "", fmt.Errorf("please add a SMS provider to the application: %s", application.Name)
+		}
+
+		phone, ok := util.GetE164Number(user.Phone, user.CountryCode)
+		if !ok {
+			return "", fmt.Errorf("the phone number of user: %s is invalid", user.GetId())
+		}
+
+		err = SendVerificationCodeToPhone(organization, user, provider, remoteAddr, phone)
+		if err != nil {
+			return "", err
+		}
+		return util.GetMaskedPhone(user.Phone), nil
+	default:
+		return "", fmt.Errorf("the MFA type: %s has no code to send", mfaType)
+	}
+}
+
+// GetApprovalProvider returns the email provider of the user's default application which asks the user to approve
+// a sign-in out of band, e.g. a RADIUS push or an LDAP bind, it returns nil when the user can't be reached by email
+func GetApprovalProvider(user *User) (*Provider, error) {
+	if user.Email == "" {
+		return nil, nil
+	}
+
+	application, err := GetDefaultApplication(util.GetId("admin", user.Owner))
+	if err != nil {
+		return nil, err
+	}
+	if application == nil {
+		return nil, nil
+	}
+
+	return application.GetEmailProvider("mfaAuth")
+}
+
+// SendApprovalRequest emails the user to approve or deny a sign-in in Casdoor, the content must not contain
+// anything which would complete the sign-in
+func SendApprovalRequest(user *User, provider *Provider, content string) error {
+	organization, err := GetOrganizationByUser(user)
+	if err != nil {
+		return err
+	}
+	if organization == nil {
+		return fmt.Errorf("the organization: %s doesn't exist", user.Owner)
+	}
+
+	return SendEmail(provider, "Sign-in approval request", content, user.Email, organization.DisplayName)
+}
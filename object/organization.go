@@ -85,6 +85,8 @@ type Organization struct {
 
 	MfaItems     []*MfaItem     `xorm:"varchar(300)" json:"mfaItems"`
 	AccountItems []*AccountItem `xorm:"varchar(5000)" json:"accountItems"`
+
+	ScimExtensions []*ScimExtension `xorm:"mediumtext" json:"scimExtensions"`
 }
 
 func GetOrganizationCount(owner, name, field, value string) (int64, error) {
@@ -226,6 +228,11 @@ func UpdateOrganization(id string, organization *Organization, isGlobalAdmin boo
 		}
 	}
 
+	err = checkScimExtensions(organization.ScimExtensions)
+	if err != nil {
+		return false, err
+	}
+
 	if !isGlobalAdmin {
 		organization.NavItems = org.NavItems
 		organization.WidgetItems = org.WidgetItems
@@ -252,6 +259,11 @@ func UpdateOrganization(id string, organization *Organization, isGlobalAdmin boo
 }
 
 func AddOrganization(organization *Organization) (bool, error) {
+	err := checkScimExtensions(organization.ScimExtensions)
+	if err != nil {
+		return false, err
+	}
+
 	affected, err := ormer.Engine.Insert(organization)
 	if err != nil {
 		return false, err
@@ -398,6 +398,11 @@ func (a *Ormer) createTable() {
 		panic(err)
 	}
 
+	err = a.Engine.Sync2(new(SyncerRun))
+	if err != nil {
+		panic(err)
+	}
+
 	err = a.Engine.Sync2(new(casvisorsdk.Record))
 	if err != nil {
 		panic(err)
@@ -408,6 +413,26 @@ func (a *Ormer) createTable() {
 		panic(err)
 	}
 
+	err = a.Engine.Sync2(new(WebhookDelivery))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(EventHook))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(DomainEvent))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(EventSink))
+	if err != nil {
+		panic(err)
+	}
+
 	err = a.Engine.Sync2(new(VerificationRecord))
 	if err != nil {
 		panic(err)
@@ -423,6 +448,46 @@ func (a *Ormer) createTable() {
 		panic(err)
 	}
 
+	err = a.Engine.Sync2(new(CasTicket))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(LdapServiceAccount))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(RadiusClient))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(RadiusPolicy))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(RadiusChallenge))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(LdapBindApproval))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(TacacsAccounting))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(ScimProvisioningRecord))
+	if err != nil {
+		panic(err)
+	}
+
 	err = a.Engine.Sync2(new(xormadapter.CasbinRule))
 	if err != nil {
 		panic(err)
@@ -19,6 +19,7 @@ import (
 
 	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/builder"
 	"github.com/xorm-io/xorm"
 )
 
@@ -94,3 +95,14 @@ func GetSessionForUser(owner string, offset, limit int, field, value, sortField,
 
 	return session
 }
+
+func getFilterSession(owner string, cond builder.Cond) *xorm.Session {
+	session := ormer.Engine.Prepare()
+	if owner != "" {
+		session = session.And("owner = ?", owner)
+	}
+	if cond != nil {
+		session = session.And(cond)
+	}
+	return session
+}
@@ -211,6 +211,7 @@ func notifyPayment(body []byte, owner string, paymentName string) (*Payment, *pp
 func NotifyPayment(body []byte, owner string, paymentName string) (*Payment, error) {
 	payment, notifyResult, err := notifyPayment(body, owner, paymentName)
 	if payment != nil {
+		oldState := payment.State
 		if err != nil {
 			payment.State = pp.PaymentStateError
 			payment.Message = err.Error()
@@ -223,6 +224,10 @@ func NotifyPayment(body []byte, owner string, paymentName string) (*Payment, err
 			return nil, err
 		}
 
+		if payment.State == pp.PaymentStatePaid && oldState != pp.PaymentStatePaid {
+			addDomainEvent(DomainEventPaymentPaid, payment.Owner, "payment", payment.GetId(), payment)
+		}
+
 		transaction, err := GetTransaction(payment.GetId())
 		if err != nil {
 			return nil, err
@@ -0,0 +1,127 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const RadiusPushType = "push"
+
+const (
+	RadiusApprovalStatePending  = "Pending"
+	RadiusApprovalStateApproved = "Approved"
+	RadiusApprovalStateDenied   = "Denied"
+)
+
+// RadiusChallenge is the state of a RADIUS Access-Challenge waiting for the second factor, it is kept in the
+// database so that any instance behind the NAS can continue the exchange
+type RadiusChallenge struct {
+	State       string `xorm:"varchar(100) notnull pk" json:"state"`
+	UserId      string `xorm:"varchar(100) index" json:"userId"`
+	ClientIp    string `xorm:"varchar(100)" json:"clientIp"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	ExpireTime  int64  `xorm:"index" json:"expireTime"`
+
+	MfaType       string `xorm:"varchar(100)" json:"mfaType"`
+	ApprovalState string `xorm:"varchar(100)" json:"approvalState"`
+}
+
+func NewRadiusChallenge(userId string, clientIp string, mfaType string, timeout time.Duration) *RadiusChallenge {
+	return &RadiusChallenge{
+		State:       util.GenerateId(),
+		UserId:      userId,
+		ClientIp:    clientIp,
+		CreatedTime: util.GetCurrentTime(),
+		ExpireTime:  time.Now().Add(timeout).Unix(),
+		MfaType:     mfaType,
+	}
+}
+
+func AddRadiusChallenge(challenge *RadiusChallenge) error {
+	_, err := ormer.Engine.Where("expire_time < ?", time.Now().Unix()).Delete(&RadiusChallenge{})
+	if err != nil {
+		return err
+	}
+
+	_, err = ormer.Engine.Insert(challenge)
+	return err
+}
+
+func GetRadiusChallenge(state string) (*RadiusChallenge, error) {
+	if state == "" {
+		return nil, nil
+	}
+
+	challenge := RadiusChallenge{}
+	existed, err := ormer.Engine.Where("state = ? and expire_time >= ?", state, time.Now().Unix()).Get(&challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	if existed {
+		return &challenge, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func UpdateRadiusChallenge(challenge *RadiusChallenge) error {
+	_, err := ormer.Engine.ID(challenge.State).AllCols().Update(challenge)
+	return err
+}
+
+// ConsumeRadiusChallenge deletes the challenge, it returns false if another request has already used it
+func ConsumeRadiusChallenge(state string) (bool, error) {
+	affected, err := ormer.Engine.ID(state).Delete(&RadiusChallenge{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func GetPendingRadiusChallenges(userId string) ([]*RadiusChallenge, error) {
+	challenges := []*RadiusChallenge{}
+	err := ormer.Engine.Where("user_id = ? and approval_state = ? and expire_time >= ?", userId, RadiusApprovalStatePending, time.Now().Unix()).
+		Desc("created_time").Find(&challenges)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenges, nil
+}
+
+// SetRadiusChallengeApprovalState approves or denies a pending push challenge of the user
+func SetRadiusChallengeApprovalState(state string, userId string, isApproved bool) error {
+	approvalState := RadiusApprovalStateDenied
+	if isApproved {
+		approvalState = RadiusApprovalStateApproved
+	}
+
+	affected, err := ormer.Engine.Where("state = ? and user_id = ? and approval_state = ? and expire_time >= ?", state, userId, RadiusApprovalStatePending, time.Now().Unix()).
+		Cols("approval_state").Update(&RadiusChallenge{ApprovalState: approvalState})
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("the pending RADIUS challenge: %s is not found", state)
+	}
+	return nil
+}
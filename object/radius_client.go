@@ -0,0 +1,236 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// RadiusClient is a NAS (e.g. a Wi-Fi controller or a VPN gateway) allowed to send requests to the RADIUS server.
+// IpAddress is either a single IP or a CIDR, users of the organizations can authenticate through it, only the
+// owner's users are allowed when empty.
+type RadiusClient struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	IpAddress     string   `xorm:"varchar(100)" json:"ipAddress"`
+	Secret        string   `xorm:"varchar(100)" json:"secret"`
+	Organizations []string `xorm:"mediumtext" json:"organizations"`
+	IsEnabled     bool     `json:"isEnabled"`
+}
+
+func GetRadiusClientCount(owner, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&RadiusClient{})
+}
+
+func GetRadiusClients(owner string) ([]*RadiusClient, error) {
+	clients := []*RadiusClient{}
+	err := ormer.Engine.Desc("created_time").Find(&clients, &RadiusClient{Owner: owner})
+	if err != nil {
+		return clients, err
+	}
+
+	return clients, nil
+}
+
+func GetPaginationRadiusClients(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*RadiusClient, error) {
+	clients := []*RadiusClient{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&clients)
+	if err != nil {
+		return clients, err
+	}
+
+	return clients, nil
+}
+
+func getRadiusClient(owner string, name string) (*RadiusClient, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	client := RadiusClient{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&client)
+	if err != nil {
+		return &client, err
+	}
+
+	if existed {
+		return &client, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetRadiusClient(id string) (*RadiusClient, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getRadiusClient(owner, name)
+}
+
+// GetRadiusClientByIp returns the enabled client matching the NAS address, a single IP takes precedence over
+// a CIDR and a longer prefix over a shorter one.
+func GetRadiusClientByIp(ip string) (*RadiusClient, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, nil
+	}
+
+	clients := []*RadiusClient{}
+	err := ormer.Engine.Where("is_enabled = ?", true).Find(&clients)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *RadiusClient
+	bestPrefix := -1
+	for _, client := range clients {
+		prefix := client.matchIp(addr)
+		if prefix > bestPrefix {
+			res = client
+			bestPrefix = prefix
+		}
+	}
+	return res, nil
+}
+
+func GetMaskedRadiusClient(client *RadiusClient, errs ...error) (*RadiusClient, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	if client == nil {
+		return nil, nil
+	}
+
+	if client.Secret != "" {
+		client.Secret = "***"
+	}
+	return client, nil
+}
+
+func GetMaskedRadiusClients(clients []*RadiusClient, errs ...error) ([]*RadiusClient, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	var err error
+	for _, client := range clients {
+		client, err = GetMaskedRadiusClient(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return clients, nil
+}
+
+func UpdateRadiusClient(id string, client *RadiusClient) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	oldClient, err := getRadiusClient(owner, name)
+	if err != nil {
+		return false, err
+	} else if oldClient == nil {
+		return false, nil
+	}
+
+	if client.Secret == "***" {
+		client.Secret = oldClient.Secret
+	}
+
+	err = client.checkIpAddress()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(client)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func AddRadiusClient(client *RadiusClient) (bool, error) {
+	err := client.checkIpAddress()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.Insert(client)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteRadiusClient(client *RadiusClient) (bool, error) {
+	affected, err := ormer.Engine.ID(core.PK{client.Owner, client.Name}).Delete(&RadiusClient{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (client *RadiusClient) GetId() string {
+	return fmt.Sprintf("%s/%s", client.Owner, client.Name)
+}
+
+func (client *RadiusClient) checkIpAddress() error {
+	if strings.Contains(client.IpAddress, "/") {
+		_, _, err := net.ParseCIDR(client.IpAddress)
+		return err
+	}
+
+	if net.ParseIP(client.IpAddress) == nil {
+		return fmt.Errorf("invalid IP address: %s", client.IpAddress)
+	}
+	return nil
+}
+
+// matchIp returns the prefix length the client matches the address with, or -1 if it does not match.
+func (client *RadiusClient) matchIp(ip net.IP) int {
+	if !strings.Contains(client.IpAddress, "/") {
+		addr := net.ParseIP(client.IpAddress)
+		if addr != nil && addr.Equal(ip) {
+			return 129
+		}
+		return -1
+	}
+
+	_, ipNet, err := net.ParseCIDR(client.IpAddress)
+	if err != nil || !ipNet.Contains(ip) {
+		return -1
+	}
+
+	prefix, _ := ipNet.Mask.Size()
+	return prefix
+}
+
+func (client *RadiusClient) IsOrganizationAllowed(organization string) bool {
+	if len(client.Organizations) == 0 {
+		return organization == client.Owner
+	}
+	return util.InSlice(client.Organizations, organization)
+}
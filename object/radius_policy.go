@@ -0,0 +1,195 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+var ErrRadiusPolicyNotMatched = errors.New("no RADIUS policy matches the user")
+
+// RadiusReplyAttribute is returned in the Access-Accept, Name is a RADIUS attribute name like "Filter-Id" or
+// "Vendor-Specific", in which case VendorId and VendorType identify the vendor attribute.
+type RadiusReplyAttribute struct {
+	Name       string `json:"name"`
+	VendorId   int    `json:"vendorId"`
+	VendorType int    `json:"vendorType"`
+	Value      string `json:"value"`
+}
+
+// RadiusPolicy authorizes the users of its organization who are in one of the groups or have one of the roles,
+// a policy without groups and roles matches every user. Policies are tried by ascending priority.
+type RadiusPolicy struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Priority   int                     `json:"priority"`
+	Groups     []string                `xorm:"mediumtext" json:"groups"`
+	Roles      []string                `xorm:"mediumtext" json:"roles"`
+	Attributes []*RadiusReplyAttribute `xorm:"mediumtext" json:"attributes"`
+	IsEnabled  bool                    `json:"isEnabled"`
+}
+
+func GetRadiusPolicyCount(owner, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&RadiusPolicy{})
+}
+
+func GetRadiusPolicies(owner string) ([]*RadiusPolicy, error) {
+	policies := []*RadiusPolicy{}
+	err := ormer.Engine.Desc("created_time").Find(&policies, &RadiusPolicy{Owner: owner})
+	if err != nil {
+		return policies, err
+	}
+
+	return policies, nil
+}
+
+func GetPaginationRadiusPolicies(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*RadiusPolicy, error) {
+	policies := []*RadiusPolicy{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&policies)
+	if err != nil {
+		return policies, err
+	}
+
+	return policies, nil
+}
+
+func getRadiusPolicy(owner string, name string) (*RadiusPolicy, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	policy := RadiusPolicy{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&policy)
+	if err != nil {
+		return &policy, err
+	}
+
+	if existed {
+		return &policy, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetRadiusPolicy(id string) (*RadiusPolicy, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getRadiusPolicy(owner, name)
+}
+
+func UpdateRadiusPolicy(id string, policy *RadiusPolicy) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if p, err := getRadiusPolicy(owner, name); err != nil {
+		return false, err
+	} else if p == nil {
+		return false, nil
+	}
+
+	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(policy)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func AddRadiusPolicy(policy *RadiusPolicy) (bool, error) {
+	affected, err := ormer.Engine.Insert(policy)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteRadiusPolicy(policy *RadiusPolicy) (bool, error) {
+	affected, err := ormer.Engine.ID(core.PK{policy.Owner, policy.Name}).Delete(&RadiusPolicy{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (policy *RadiusPolicy) GetId() string {
+	return fmt.Sprintf("%s/%s", policy.Owner, policy.Name)
+}
+
+func (policy *RadiusPolicy) isMatched(groups []string, roles []string) bool {
+	if len(policy.Groups) == 0 && len(policy.Roles) == 0 {
+		return true
+	}
+	return util.HaveIntersection(policy.Groups, groups) || util.HaveIntersection(policy.Roles, roles)
+}
+
+func getMatchedRadiusPolicy(policies []*RadiusPolicy, groups []string, roles []string) *RadiusPolicy {
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].Priority != policies[j].Priority {
+			return policies[i].Priority < policies[j].Priority
+		}
+		return policies[i].Name < policies[j].Name
+	})
+
+	for _, policy := range policies {
+		if policy.isMatched(groups, roles) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// GetRadiusPolicyByUser returns the first policy matching the user's groups and roles, and ErrRadiusPolicyNotMatched
+// when none of them matches. An organization without enabled policies rejects every user, unless
+// radiusAllowWithoutPolicy is set, in which case nil is returned.
+func GetRadiusPolicyByUser(user *User) (*RadiusPolicy, error) {
+	policies := []*RadiusPolicy{}
+	err := ormer.Engine.Where("owner = ? and is_enabled = ?", user.Owner, true).Find(&policies)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(policies) == 0 {
+		if conf.GetConfigBool("radiusAllowWithoutPolicy") {
+			return nil, nil
+		}
+		return nil, ErrRadiusPolicyNotMatched
+	}
+
+	roles, err := getRolesByUser(user.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	roleIds := []string{}
+	for _, role := range roles {
+		roleIds = append(roleIds, role.GetId())
+	}
+
+	policy := getMatchedRadiusPolicy(policies, user.Groups, roleIds)
+	if policy == nil {
+		return nil, ErrRadiusPolicyNotMatched
+	}
+	return policy, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMatchedRadiusPolicy(t *testing.T) {
+	policies := []*RadiusPolicy{
+		{Name: "default", Priority: 100},
+		{Name: "staff", Priority: 10, Groups: []string{"org/staff"}},
+		{Name: "admin", Priority: 1, Roles: []string{"org/admin"}},
+	}
+
+	assert.Equal(t, "admin", getMatchedRadiusPolicy(policies, []string{"org/staff"}, []string{"org/admin"}).Name)
+	assert.Equal(t, "staff", getMatchedRadiusPolicy(policies, []string{"org/staff"}, nil).Name)
+	assert.Equal(t, "default", getMatchedRadiusPolicy(policies, nil, nil).Name)
+	assert.Nil(t, getMatchedRadiusPolicy([]*RadiusPolicy{{Name: "staff", Groups: []string{"org/staff"}}}, []string{"org/guest"}, nil))
+}
+
+func TestRadiusClientMatchIp(t *testing.T) {
+	ip := net.ParseIP("10.0.1.5")
+	assert.Equal(t, 129, (&RadiusClient{IpAddress: "10.0.1.5"}).matchIp(ip))
+	assert.Equal(t, 24, (&RadiusClient{IpAddress: "10.0.1.0/24"}).matchIp(ip))
+	assert.Equal(t, -1, (&RadiusClient{IpAddress: "10.0.2.0/24"}).matchIp(ip))
+	assert.Equal(t, -1, (&RadiusClient{IpAddress: "10.0.1.6"}).matchIp(ip))
+}
@@ -229,40 +229,6 @@ func getFilteredWebhooks(webhooks []*Webhook, organization string, action string
 	return res
 }
 
-func addWebhookRecord(webhook *Webhook, record *casvisorsdk.Record, statusCode int, respBody string, sendError error) error {
-	if statusCode == 200 {
-		return nil
-	}
-
-	if len(respBody) > 300 {
-		respBody = respBody[0:300]
-	}
-
-	webhookRecord := &casvisorsdk.Record{
-		Owner:        record.Owner,
-		Name:         util.GenerateId(),
-		CreatedTime:  util.GetCurrentTime(),
-		Organization: record.Organization,
-		User:         record.User,
-
-		Method:      webhook.Method,
-		Action:      "send-webhook",
-		RequestUri:  webhook.Url,
-		StatusCode:  statusCode,
-		Response:    respBody,
-		Language:    record.Language,
-		IsTriggered: false,
-	}
-
-	if sendError != nil {
-		webhookRecord.Response = sendError.Error()
-	}
-
-	_, err := addRecord(webhookRecord)
-
-	return err
-}
-
 func filterRecordObject(object string, objectFields []string) string {
 	var rawObject map[string]interface{}
 	_ = json.Unmarshal([]byte(object), &rawObject)
@@ -284,6 +250,8 @@ func filterRecordObject(object string, objectFields []string) string {
 	return util.StructToJson(filteredObject)
 }
 
+// SendWebhooks queues a delivery of the record to each webhook that subscribes to the action, the deliveries are sent
+// by the webhook delivery worker
 func SendWebhooks(record *casvisorsdk.Record) error {
 	webhooks, err := getWebhooksByOrganization("")
 	if err != nil {
@@ -315,16 +283,20 @@ func SendWebhooks(record *casvisorsdk.Record) error {
 			}
 		}
 
-		statusCode, respBody, err := sendWebhook(webhook, &record2, user)
+		body, err := getWebhookBody(webhook, &record2, user)
 		if err != nil {
 			errs = append(errs, err)
+			continue
 		}
 
-		err = addWebhookRecord(webhook, &record2, statusCode, respBody, err)
+		err = addWebhookDelivery(webhook, record2.Action, body, "")
 		if err != nil {
 			errs = append(errs, err)
 		}
+	}
 
+	if len(webhooks) != 0 {
+		signalWebhookDeliveryWorker()
 	}
 
 	if len(errs) > 0 {
@@ -188,6 +188,10 @@ func UpdateRole(id string, role *Role) (bool, error) {
 		}
 	}
 
+	if affected != 0 {
+		addRoleDomainEvent("updated", role)
+	}
+
 	return affected != 0, nil
 }
 
@@ -197,6 +201,10 @@ func AddRole(role *Role) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		addRoleDomainEvent("created", role)
+	}
+
 	return affected != 0, nil
 }
 
@@ -244,6 +252,10 @@ func deleteRole(role *Role) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		addRoleDomainEvent("deleted", role)
+	}
+
 	return affected != 0, nil
 }
 
@@ -405,8 +405,9 @@ func GetSamlResponse(application *Application, user *User, samlRequest string, h
 	return res, authnRequest.AssertionConsumerServiceURL, method, err
 }
 
-// NewSamlResponse11 return a saml1.1 response(not 2.0)
-func NewSamlResponse11(application *Application, user *User, requestID string, host string) (*etree.Element, error) {
+// NewSamlResponse11 creates the SAML 1.1 response of the CAS validation, the attributes are filtered by the release
+// policy of the CAS service if it is not nil
+func NewSamlResponse11(application *Application, user *User, requestID string, host string, casService *CasService) (*etree.Element, error) {
 	samlResponse := &etree.Element{
 		Space: "samlp",
 		Tag:   "Response",
@@ -484,6 +485,13 @@ func NewSamlResponse11(application *Application, user *User, requestID string, h
 	}
 
 	for k, v := range tmp {
+		if casService != nil {
+			if !casService.IsAttributeReleased(k) {
+				continue
+			}
+			k = casService.GetAttributeAlias(k)
+		}
+
 		if v != "" {
 			attr := attributeStatement.CreateElement("saml:Attribute")
 			attr.CreateAttr("saml:AttributeName", k)
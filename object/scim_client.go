@@ -0,0 +1,338 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/casdoor/casdoor/proxy"
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+)
+
+var scimMultiValuedAttributes = []string{"emails", "phoneNumbers", "photos", "addresses"}
+
+var defaultScimAttributeMappings = []*ScimAttributeMapping{
+	{ScimAttribute: "userName", UserField: "name"},
+	{ScimAttribute: "displayName", UserField: "displayName"},
+	{ScimAttribute: "name.givenName", UserField: "firstName"},
+	{ScimAttribute: "name.familyName", UserField: "lastName"},
+	{ScimAttribute: "emails.value", UserField: "email"},
+	{ScimAttribute: "phoneNumbers.value", UserField: "phone"},
+}
+
+type scimError struct {
+	status int
+	detail string
+}
+
+func (e *scimError) Error() string {
+	return fmt.Sprintf("the SCIM service returned status code: %d, %s", e.status, e.detail)
+}
+
+func isScimNotFound(err error) bool {
+	e, ok := err.(*scimError)
+	return ok && e.status == http.StatusNotFound
+}
+
+func (p *ScimProvisioning) doRequest(method string, path string, body interface{}) (map[string]interface{}, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(p.BaseUrl, "/")+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/scim+json")
+	req.Header.Set("Accept", "application/scim+json")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	resp, err := proxy.DefaultHttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &scimError{status: resp.StatusCode, detail: string(data)}
+	}
+
+	res := map[string]interface{}{}
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &res)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// findResourceId looks up a resource that already exists downstream, so that a reconciliation adopts it instead of
+// creating a duplicate
+func (p *ScimProvisioning) findResourceId(endpoint string, attribute string, value string) (string, error) {
+	query := url.Values{}
+	query.Set("filter", fmt.Sprintf("%s eq %q", attribute, value))
+	res, err := p.doRequest(http.MethodGet, fmt.Sprintf("%s?%s", endpoint, query.Encode()), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resources, _ := res["Resources"].([]interface{})
+	if len(resources) == 0 {
+		return "", nil
+	}
+	resource, _ := resources[0].(map[string]interface{})
+	id, _ := resource["id"].(string)
+	return id, nil
+}
+
+// upsertResource replaces the resource if it's known, otherwise it creates the resource and returns the new id
+func (p *ScimProvisioning) upsertResource(endpoint string, remoteId string, resource map[string]interface{}, attribute string) (string, error) {
+	var err error
+	if remoteId == "" {
+		value, _ := resource[attribute].(string)
+		remoteId, err = p.findResourceId(endpoint, attribute, value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if remoteId != "" {
+		_, err = p.doRequest(http.MethodPut, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(remoteId)), resource)
+		if !isScimNotFound(err) {
+			return remoteId, err
+		}
+	}
+
+	res, err := p.doRequest(http.MethodPost, endpoint, resource)
+	if err != nil {
+		return "", err
+	}
+	id, _ := res["id"].(string)
+	if id == "" {
+		return "", fmt.Errorf("the SCIM service didn't return the id of the created resource")
+	}
+	return id, nil
+}
+
+func (p *ScimProvisioning) deleteResource(endpoint string, remoteId string) error {
+	if remoteId == "" {
+		return nil
+	}
+
+	_, err := p.doRequest(http.MethodDelete, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(remoteId)), nil)
+	if isScimNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func getScimUserFieldValue(user *User, field string) string {
+	if strings.HasPrefix(field, "properties.") {
+		return getUserProperty(user, strings.TrimPrefix(field, "properties."))
+	}
+	if field == "" {
+		return ""
+	}
+
+	_, value, err := GetUserFieldStringValue(user, strings.ToUpper(field[:1])+field[1:])
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// setScimAttribute sets the value at the attribute path, the multi-valued attributes get a single primary value
+func setScimAttribute(resource map[string]interface{}, attribute string, value string) {
+	if strings.HasPrefix(attribute, "urn:") {
+		i := strings.LastIndex(attribute, ":")
+		schema := attribute[:i]
+		extension, ok := resource[schema].(map[string]interface{})
+		if !ok {
+			extension = map[string]interface{}{}
+			resource[schema] = extension
+			resource["schemas"] = append(resource["schemas"].([]string), schema)
+		}
+		setScimAttribute(extension, attribute[i+1:], value)
+		return
+	}
+
+	name, subAttribute, ok := strings.Cut(attribute, ".")
+	if !ok {
+		resource[name] = value
+		return
+	}
+
+	if util.InSlice(scimMultiValuedAttributes, name) {
+		values, ok := resource[name].([]interface{})
+		if !ok {
+			values = []interface{}{map[string]interface{}{"primary": true}}
+			resource[name] = values
+		}
+		values[0].(map[string]interface{})[subAttribute] = value
+		return
+	}
+
+	complexValue, ok := resource[name].(map[string]interface{})
+	if !ok {
+		complexValue = map[string]interface{}{}
+		resource[name] = complexValue
+	}
+	complexValue[subAttribute] = value
+}
+
+func (p *ScimProvisioning) getUserResource(user *User) map[string]interface{} {
+	mappings := p.Attributes
+	if len(mappings) == 0 {
+		mappings = defaultScimAttributeMappings
+	}
+
+	resource := map[string]interface{}{
+		"schemas":    []string{scimUserSchema},
+		"externalId": user.Id,
+		"active":     !user.IsForbidden && !user.IsDeleted,
+	}
+	for _, mapping := range mappings {
+		value := getScimUserFieldValue(user, mapping.UserField)
+		if value == "" || mapping.ScimAttribute == "" {
+			continue
+		}
+		setScimAttribute(resource, mapping.ScimAttribute, value)
+	}
+	return resource
+}
+
+func (p *ScimProvisioning) getGroupResource(application *Application, group *Group) (map[string]interface{}, error) {
+	users, err := GetGroupUsers(group.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	members := []map[string]interface{}{}
+	for _, user := range users {
+		name := getScimProvisioningRecordName(application.GetId(), ScimProvisioningUser, user.Name)
+		record, err := getScimProvisioningRecord(group.Owner, name)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil && record.RemoteId != "" {
+			members = append(members, map[string]interface{}{"value": record.RemoteId})
+		}
+	}
+
+	displayName := group.DisplayName
+	if displayName == "" {
+		displayName = group.Name
+	}
+	return map[string]interface{}{
+		"schemas":     []string{scimGroupSchema},
+		"externalId":  group.GetId(),
+		"displayName": displayName,
+		"members":     members,
+	}, nil
+}
+
+func (p *ScimProvisioning) provision(application *Application, record *ScimProvisioningRecord) error {
+	switch record.ObjectType {
+	case ScimProvisioningUser:
+		return p.provisionUser(application, record)
+	case ScimProvisioningGroup:
+		return p.provisionGroup(application, record)
+	default:
+		return fmt.Errorf("unknown SCIM provisioning object type: %s", record.ObjectType)
+	}
+}
+
+func (p *ScimProvisioning) provisionUser(application *Application, record *ScimProvisioningRecord) error {
+	user, err := getUser(record.Owner, record.ObjectName)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		record.Action = ScimProvisioningActionDelete
+	}
+	if record.Action == ScimProvisioningActionDelete {
+		return p.deleteResource("/Users", record.RemoteId)
+	}
+
+	resource := p.getUserResource(user)
+	remoteId, err := p.upsertResource("/Users", record.RemoteId, resource, "userName")
+	if err != nil {
+		return err
+	}
+
+	isNew := record.RemoteId != remoteId
+	record.RemoteId = remoteId
+	record.State = ScimProvisioningStateProvisioned
+	if !resource["active"].(bool) {
+		record.State = ScimProvisioningStateDeprovisioned
+	}
+
+	// The groups that were provisioned before the user didn't include the user as a member
+	if isNew {
+		provisionScimGroupsById(user.Owner, user.Groups)
+	}
+	return nil
+}
+
+func (p *ScimProvisioning) provisionGroup(application *Application, record *ScimProvisioningRecord) error {
+	group, err := getGroup(record.Owner, record.ObjectName)
+	if err != nil {
+		return err
+	}
+
+	if group == nil {
+		record.Action = ScimProvisioningActionDelete
+	}
+	if record.Action == ScimProvisioningActionDelete {
+		return p.deleteResource("/Groups", record.RemoteId)
+	}
+
+	resource, err := p.getGroupResource(application, group)
+	if err != nil {
+		return err
+	}
+	remoteId, err := p.upsertResource("/Groups", record.RemoteId, resource, "displayName")
+	if err != nil {
+		return err
+	}
+
+	record.RemoteId = remoteId
+	record.State = ScimProvisioningStateProvisioned
+	return nil
+}
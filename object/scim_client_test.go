@@ -0,0 +1,56 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScimUserResource(t *testing.T) {
+	provisioning := &ScimProvisioning{
+		Attributes: []*ScimAttributeMapping{
+			{ScimAttribute: "userName", UserField: "name"},
+			{ScimAttribute: "name.givenName", UserField: "firstName"},
+			{ScimAttribute: "emails.value", UserField: "email"},
+			{ScimAttribute: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", UserField: "properties.department"},
+		},
+	}
+	user := &User{
+		Id:          "id",
+		Name:        "alice",
+		FirstName:   "Alice",
+		Email:       "alice@example.com",
+		IsForbidden: true,
+		Properties:  map[string]string{"department": "R&D"},
+	}
+
+	resource := provisioning.getUserResource(user)
+	assert.Equal(t, "alice", resource["userName"])
+	assert.Equal(t, "id", resource["externalId"])
+	assert.Equal(t, false, resource["active"])
+	assert.Equal(t, map[string]interface{}{"givenName": "Alice"}, resource["name"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"primary": true, "value": "alice@example.com"}}, resource["emails"])
+	assert.Equal(t, map[string]interface{}{"department": "R&D"}, resource["urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"])
+	assert.Equal(t, []string{scimUserSchema, "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"}, resource["schemas"])
+}
+
+func TestGetScimProvisioningRetryDelay(t *testing.T) {
+	assert.Equal(t, 2*time.Minute, getScimProvisioningRetryDelay(1))
+	assert.Equal(t, 32*time.Minute, getScimProvisioningRetryDelay(5))
+	assert.Equal(t, time.Hour, getScimProvisioningRetryDelay(9))
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+var ScimExtensionAttributeTypes = []string{"string", "boolean", "integer", "decimal", "dateTime"}
+
+var reservedScimSchemas = []string{
+	"urn:ietf:params:scim:schemas:core:2.0:User",
+	"urn:ietf:params:scim:schemas:core:2.0:Group",
+	"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User",
+	"urn:ietf:params:scim:schemas:extension:casdoor:2.0:Group",
+}
+
+// ScimExtension is a SCIM schema extension of the User resource defined by an organization, the values of its
+// attributes are stored in the properties of the users
+type ScimExtension struct {
+	Id          string                    `json:"id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Attributes  []*ScimExtensionAttribute `json:"attributes"`
+}
+
+type ScimExtensionAttribute struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+	Property    string `json:"property"`
+}
+
+// GetProperty returns the key of the user property that stores the attribute, which defaults to the attribute name
+func (attribute *ScimExtensionAttribute) GetProperty() string {
+	if attribute.Property != "" {
+		return attribute.Property
+	}
+	return attribute.Name
+}
+
+func checkScimExtensions(extensions []*ScimExtension) error {
+	ids := []string{}
+	for _, extension := range extensions {
+		if !strings.HasPrefix(extension.Id, "urn:") {
+			return fmt.Errorf("the id of the SCIM extension: %s should be a URN", extension.Id)
+		}
+		for _, id := range append(ids, reservedScimSchemas...) {
+			if strings.EqualFold(id, extension.Id) {
+				return fmt.Errorf("the id of the SCIM extension: %s is already used", extension.Id)
+			}
+		}
+		ids = append(ids, extension.Id)
+
+		names := []string{}
+		for _, attribute := range extension.Attributes {
+			if attribute.Name == "" || strings.ContainsAny(attribute.Name, ".:[] ") {
+				return fmt.Errorf("the attribute name: %q of the SCIM extension: %s is invalid", attribute.Name, extension.Id)
+			}
+			if util.InSlice(names, strings.ToLower(attribute.Name)) {
+				return fmt.Errorf("the attribute: %s of the SCIM extension: %s is duplicated", attribute.Name, extension.Id)
+			}
+			if !util.InSlice(ScimExtensionAttributeTypes, attribute.Type) {
+				return fmt.Errorf("the type: %s of the SCIM extension attribute: %s is not supported", attribute.Type, attribute.Name)
+			}
+			names = append(names, strings.ToLower(attribute.Name))
+		}
+	}
+	return nil
+}
+
+// GetScimExtensions returns the SCIM extensions by organization, the extensions of all organizations are returned if
+// the owner is empty
+func GetScimExtensions(owner string) (map[string][]*ScimExtension, error) {
+	organizations := []*Organization{}
+	session := ormer.Engine.Cols("name", "scim_extensions")
+	if owner != "" {
+		session = session.Where("name = ?", owner)
+	}
+	err := session.Find(&organizations, &Organization{Owner: "admin"})
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string][]*ScimExtension{}
+	for _, organization := range organizations {
+		if len(organization.ScimExtensions) != 0 {
+			res[organization.Name] = organization.ScimExtensions
+		}
+	}
+	return res, nil
+}
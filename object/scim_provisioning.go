@@ -0,0 +1,495 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	ScimProvisioningUser  = "User"
+	ScimProvisioningGroup = "Group"
+
+	ScimProvisioningActionUpsert = "Upsert"
+	ScimProvisioningActionDelete = "Delete"
+
+	ScimProvisioningStatePending       = "Pending"
+	ScimProvisioningStateProcessing    = "Processing"
+	ScimProvisioningStateProvisioned   = "Provisioned"
+	ScimProvisioningStateDeprovisioned = "Deprovisioned"
+	ScimProvisioningStateFailed        = "Failed"
+
+	scimProvisioningMaxAttempts = 10
+	scimProvisioningBatchSize   = 100
+
+	// a claimed record is processed again after the lease when the worker stopped before writing it back
+	scimProvisioningLease = 5 * time.Minute
+	// the applications of another instance are reloaded after the TTL, the changes of this instance clear the cache
+	scimProvisioningApplicationCacheTtl = time.Minute
+)
+
+// ScimProvisioning is the outbound SCIM 2.0 connector of an application, the users and groups of the organization of
+// the application are pushed to the downstream service whenever they change
+type ScimProvisioning struct {
+	Enabled    bool                    `json:"enabled"`
+	BaseUrl    string                  `json:"baseUrl"`
+	Token      string                  `json:"token"`
+	Attributes []*ScimAttributeMapping `json:"attributes"`
+}
+
+// ScimAttributeMapping maps a user field, e.g. "displayName" or "properties.department", to a SCIM attribute path,
+// e.g. "name.givenName", "emails.value" or "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department"
+type ScimAttributeMapping struct {
+	ScimAttribute string `json:"scimAttribute"`
+	UserField     string `json:"userField"`
+}
+
+// ScimProvisioningRecord is the provisioning status of a user or a group in an application, the pending and failed
+// records form the retry queue of the connector
+type ScimProvisioningRecord struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Application   string `xorm:"varchar(100) index" json:"application"`
+	ObjectType    string `xorm:"varchar(100)" json:"objectType"`
+	ObjectName    string `xorm:"varchar(100)" json:"objectName"`
+	RemoteId      string `xorm:"varchar(100)" json:"remoteId"`
+	Action        string `xorm:"varchar(100)" json:"action"`
+	State         string `xorm:"varchar(100) index" json:"state"`
+	Attempts      int    `json:"attempts"`
+	NextRetryTime string `xorm:"varchar(100) index" json:"nextRetryTime"`
+	LastSyncTime  string `xorm:"varchar(100)" json:"lastSyncTime"`
+	Error         string `xorm:"mediumtext" json:"error"`
+}
+
+var scimProvisioningSignal = make(chan struct{}, 1)
+
+var (
+	scimProvisioningApplications         map[string][]*Application
+	scimProvisioningApplicationCacheTime time.Time
+	scimProvisioningApplicationMutex     sync.Mutex
+)
+
+func GetScimProvisioningRecordCount(owner, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&ScimProvisioningRecord{})
+}
+
+func GetPaginationScimProvisioningRecords(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*ScimProvisioningRecord, error) {
+	records := []*ScimProvisioningRecord{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func getScimProvisioningRecord(owner string, name string) (*ScimProvisioningRecord, error) {
+	record := ScimProvisioningRecord{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&record)
+	if err != nil {
+		return nil, err
+	}
+
+	if existed {
+		return &record, nil
+	}
+	return nil, nil
+}
+
+func getScimProvisioningRecords(applicationId string, objectType string) ([]*ScimProvisioningRecord, error) {
+	records := []*ScimProvisioningRecord{}
+	err := ormer.Engine.Where("application = ? and object_type = ?", applicationId, objectType).Find(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// claimScimProvisioningRecord marks the record as processing if it wasn't changed since it was read, it returns
+// false when another worker claimed it or the object was queued again
+func claimScimProvisioningRecord(record *ScimProvisioningRecord) (bool, error) {
+	updatedTime, nextRetryTime := record.UpdatedTime, record.NextRetryTime
+	record.State = ScimProvisioningStateProcessing
+	record.UpdatedTime = util.GetCurrentTime()
+	record.NextRetryTime = time.Now().Add(scimProvisioningLease).Format(time.RFC3339)
+
+	affected, err := ormer.Engine.ID(core.PK{record.Owner, record.Name}).
+		Where("updated_time = ? and next_retry_time = ?", updatedTime, nextRetryTime).
+		In("state", ScimProvisioningStatePending, ScimProvisioningStateFailed, ScimProvisioningStateProcessing).
+		Cols("state", "updated_time", "next_retry_time").Update(record)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+// finishScimProvisioningRecord writes the result of a claimed record back, the result is dropped when the object
+// was queued again meanwhile so that the queued change is provisioned, except for the remote id of the object
+func finishScimProvisioningRecord(record *ScimProvisioningRecord, claimedTime string) error {
+	record.UpdatedTime = util.GetCurrentTime()
+	affected, err := ormer.Engine.ID(core.PK{record.Owner, record.Name}).
+		Where("state = ? and updated_time = ?", ScimProvisioningStateProcessing, claimedTime).
+		Cols("updated_time", "remote_id", "action", "state", "attempts", "next_retry_time", "last_sync_time", "error").Update(record)
+	if err != nil || affected != 0 {
+		return err
+	}
+
+	_, err = ormer.Engine.ID(core.PK{record.Owner, record.Name}).Cols("remote_id").Update(record)
+	return err
+}
+
+func deleteScimProvisioningRecord(record *ScimProvisioningRecord, claimedTime string) error {
+	_, err := ormer.Engine.ID(core.PK{record.Owner, record.Name}).
+		Where("state = ? and updated_time = ?", ScimProvisioningStateProcessing, claimedTime).Delete(&ScimProvisioningRecord{})
+	return err
+}
+
+func getScimProvisioningRecordName(applicationId string, objectType string, objectName string) string {
+	return util.GetMd5Hash(fmt.Sprintf("%s/%s/%s", applicationId, objectType, objectName))
+}
+
+// getScimProvisioningApplications returns the applications of the organization with SCIM provisioning enabled, the
+// applications are cached because the organization's users are queued on every change
+func getScimProvisioningApplications(owner string) ([]*Application, error) {
+	scimProvisioningApplicationMutex.Lock()
+	defer scimProvisioningApplicationMutex.Unlock()
+
+	if scimProvisioningApplications == nil || time.Since(scimProvisioningApplicationCacheTime) > scimProvisioningApplicationCacheTtl {
+		applications := []*Application{}
+		err := ormer.Engine.Find(&applications)
+		if err != nil {
+			return nil, err
+		}
+
+		scimProvisioningApplications = map[string][]*Application{}
+		for _, application := range applications {
+			if application.ScimProvisioning != nil && application.ScimProvisioning.Enabled && application.ScimProvisioning.BaseUrl != "" {
+				scimProvisioningApplications[application.Organization] = append(scimProvisioningApplications[application.Organization], application)
+			}
+		}
+		scimProvisioningApplicationCacheTime = time.Now()
+	}
+
+	return scimProvisioningApplications[owner], nil
+}
+
+func clearScimProvisioningApplicationCache() {
+	scimProvisioningApplicationMutex.Lock()
+	defer scimProvisioningApplicationMutex.Unlock()
+	scimProvisioningApplications = nil
+}
+
+// addScimProvisioningTask queues the action for the object, a pending action of the object is replaced because only
+// the latest state of the object is pushed
+func addScimProvisioningTask(application *Application, objectType string, objectName string, action string) error {
+	name := getScimProvisioningRecordName(application.GetId(), objectType, objectName)
+	record, err := getScimProvisioningRecord(application.Organization, name)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		if action == ScimProvisioningActionDelete {
+			return nil
+		}
+
+		record = &ScimProvisioningRecord{
+			Owner:         application.Organization,
+			Name:          name,
+			CreatedTime:   util.GetCurrentTime(),
+			UpdatedTime:   util.GetCurrentTime(),
+			Application:   application.GetId(),
+			ObjectType:    objectType,
+			ObjectName:    objectName,
+			Action:        action,
+			State:         ScimProvisioningStatePending,
+			NextRetryTime: util.GetCurrentTime(),
+		}
+		_, err = ormer.Engine.Insert(record)
+		return err
+	}
+
+	record.Action = action
+	record.State = ScimProvisioningStatePending
+	record.Attempts = 0
+	record.NextRetryTime = util.GetCurrentTime()
+	record.UpdatedTime = util.GetCurrentTime()
+	record.Error = ""
+	_, err = ormer.Engine.ID(core.PK{record.Owner, record.Name}).
+		Cols("action", "state", "attempts", "next_retry_time", "updated_time", "error").Update(record)
+	return err
+}
+
+func enqueueScimProvisioning(owner string, objectType string, objectNames []string, action string) {
+	if len(objectNames) == 0 {
+		return
+	}
+
+	applications, err := getScimProvisioningApplications(owner)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("SCIM provisioning failed for organization: %s, error: %s", owner, err))
+		return
+	}
+	if len(applications) == 0 {
+		return
+	}
+
+	for _, application := range applications {
+		for _, objectName := range objectNames {
+			err = addScimProvisioningTask(application, objectType, objectName, action)
+			if err != nil {
+				logs.Warning(fmt.Sprintf("SCIM provisioning to %s failed for %s: %s/%s, error: %s", application.GetId(), objectType, owner, objectName, err))
+			}
+		}
+	}
+
+	select {
+	case scimProvisioningSignal <- struct{}{}:
+	default:
+	}
+}
+
+// provisionScimUser queues the user and the groups whose members changed, a forbidden or soft-deleted user is
+// deactivated in the downstream applications
+func provisionScimUser(oldUser *User, user *User) {
+	enqueueScimProvisioning(user.Owner, ScimProvisioningUser, []string{user.Name}, ScimProvisioningActionUpsert)
+
+	changedGroups := []string{}
+	oldGroups := []string{}
+	if oldUser != nil {
+		oldGroups = oldUser.Groups
+	}
+	for _, groupId := range append(oldGroups, user.Groups...) {
+		if util.InSlice(oldGroups, groupId) != util.InSlice(user.Groups, groupId) {
+			changedGroups = append(changedGroups, groupId)
+		}
+	}
+	provisionScimGroupsById(user.Owner, changedGroups)
+}
+
+func deprovisionScimUser(user *User) {
+	enqueueScimProvisioning(user.Owner, ScimProvisioningUser, []string{user.Name}, ScimProvisioningActionDelete)
+	provisionScimGroupsById(user.Owner, user.Groups)
+}
+
+func provisionScimGroupsById(owner string, groupIds []string) {
+	names := []string{}
+	for _, groupId := range groupIds {
+		groupOwner, name, err := util.GetOwnerAndNameFromIdWithError(groupId)
+		if err == nil && groupOwner == owner && !util.InSlice(names, name) {
+			names = append(names, name)
+		}
+	}
+	enqueueScimProvisioning(owner, ScimProvisioningGroup, names, ScimProvisioningActionUpsert)
+}
+
+func provisionScimGroup(group *Group) {
+	enqueueScimProvisioning(group.Owner, ScimProvisioningGroup, []string{group.Name}, ScimProvisioningActionUpsert)
+}
+
+func deprovisionScimGroup(group *Group) {
+	enqueueScimProvisioning(group.Owner, ScimProvisioningGroup, []string{group.Name}, ScimProvisioningActionDelete)
+}
+
+// renameScimProvisioningObject moves the records of a renamed object to its new name, so that the downstream resource
+// is updated instead of being duplicated
+func renameScimProvisioningObject(owner string, objectType string, oldName string, newName string) {
+	records := []*ScimProvisioningRecord{}
+	err := ormer.Engine.Where("owner = ? and object_type = ? and object_name = ?", owner, objectType, oldName).Find(&records)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to rename the SCIM provisioning records of %s: %s/%s, error: %s", objectType, owner, oldName, err))
+		return
+	}
+
+	for _, record := range records {
+		name := record.Name
+		record.Name = getScimProvisioningRecordName(record.Application, objectType, newName)
+		record.ObjectName = newName
+		_, err = ormer.Engine.ID(core.PK{owner, name}).Cols("name", "object_name").Update(record)
+		if err != nil {
+			logs.Warning(fmt.Sprintf("failed to rename the SCIM provisioning record: %s, error: %s", name, err))
+		}
+	}
+}
+
+// ReconcileScimProvisioning queues all the users and groups of the organization of the application, the objects that
+// were provisioned but no longer exist are deleted downstream
+func ReconcileScimProvisioning(application *Application) error {
+	if application.ScimProvisioning == nil || !application.ScimProvisioning.Enabled {
+		return fmt.Errorf("the SCIM provisioning of the application: %s is not enabled", application.GetId())
+	}
+
+	users, err := GetUsers(application.Organization)
+	if err != nil {
+		return err
+	}
+	groups, err := GetGroups(application.Organization)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]map[string]bool{
+		ScimProvisioningUser:  {},
+		ScimProvisioningGroup: {},
+	}
+	for _, user := range users {
+		names[ScimProvisioningUser][user.Name] = true
+	}
+	for _, group := range groups {
+		names[ScimProvisioningGroup][group.Name] = true
+	}
+
+	for _, objectType := range []string{ScimProvisioningUser, ScimProvisioningGroup} {
+		for name := range names[objectType] {
+			err = addScimProvisioningTask(application, objectType, name, ScimProvisioningActionUpsert)
+			if err != nil {
+				return err
+			}
+		}
+
+		records, err := getScimProvisioningRecords(application.GetId(), objectType)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if !names[objectType][record.ObjectName] {
+				err = addScimProvisioningTask(application, objectType, record.ObjectName, ScimProvisioningActionDelete)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	select {
+	case scimProvisioningSignal <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func getScimProvisioningRetryDelay(attempts int) time.Duration {
+	minutes := 1 << min(attempts, 6)
+	return time.Duration(min(minutes, 60)) * time.Minute
+}
+
+func processScimProvisioningRecord(application *Application, record *ScimProvisioningRecord) {
+	claimedTime := record.UpdatedTime
+	err := application.ScimProvisioning.provision(application, record)
+	if err == nil {
+		if record.Action == ScimProvisioningActionDelete {
+			err = deleteScimProvisioningRecord(record, claimedTime)
+			if err != nil {
+				logs.Warning(fmt.Sprintf("failed to delete the SCIM provisioning record: %s, error: %s", record.Name, err))
+			}
+			return
+		}
+
+		record.Attempts = 0
+		record.NextRetryTime = ""
+		record.LastSyncTime = util.GetCurrentTime()
+		record.Error = ""
+	} else {
+		record.Attempts++
+		record.State = ScimProvisioningStateFailed
+		record.Error = err.Error()
+		record.NextRetryTime = ""
+		if record.Attempts < scimProvisioningMaxAttempts {
+			record.NextRetryTime = time.Now().Add(getScimProvisioningRetryDelay(record.Attempts)).Format(time.RFC3339)
+		}
+	}
+
+	err = finishScimProvisioningRecord(record, claimedTime)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to update the SCIM provisioning record: %s, error: %s", record.Name, err))
+	}
+}
+
+func processScimProvisioningRecords() error {
+	records := []*ScimProvisioningRecord{}
+	err := ormer.Engine.Where("next_retry_time != ? and next_retry_time <= ?", "", util.GetCurrentTime()).
+		In("state", ScimProvisioningStatePending, ScimProvisioningStateFailed, ScimProvisioningStateProcessing).
+		Asc("next_retry_time").Limit(scimProvisioningBatchSize).Find(&records)
+	if err != nil {
+		return err
+	}
+
+	applications := map[string]*Application{}
+	for _, record := range records {
+		claimed, err := claimScimProvisioningRecord(record)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		application, ok := applications[record.Application]
+		if !ok {
+			application, err = GetApplication(record.Application)
+			if err != nil {
+				return err
+			}
+			applications[record.Application] = application
+		}
+
+		if application == nil || application.ScimProvisioning == nil || !application.ScimProvisioning.Enabled {
+			claimedTime := record.UpdatedTime
+			record.State = ScimProvisioningStateFailed
+			record.NextRetryTime = ""
+			record.Error = fmt.Sprintf("the SCIM provisioning of the application: %s is not enabled", record.Application)
+			err = finishScimProvisioningRecord(record, claimedTime)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		processScimProvisioningRecord(application, record)
+	}
+	return nil
+}
+
+// RunScimProvisioningWorker processes the queued provisioning records when an object changes and retries the failed
+// records periodically
+func RunScimProvisioningWorker() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-scimProvisioningSignal:
+		}
+
+		err := processScimProvisioningRecords()
+		if err != nil {
+			logs.Warning(fmt.Sprintf("SCIM provisioning failed, error: %s", err))
+		}
+	}
+}
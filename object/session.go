@@ -139,13 +139,20 @@ func AddSession(session *Session) (bool, error) {
 
 func DeleteSession(id string) (bool, error) {
 	owner, name, application := util.GetOwnerAndNameAndOtherFromId(id)
-	if owner == CasdoorOrganization && application == CasdoorApplication {
-		session, err := GetSingleSession(id)
-		if err != nil {
-			return false, err
+	session, err := GetSingleSession(id)
+	if err != nil {
+		return false, err
+	}
+
+	if session != nil {
+		for _, sessionId := range session.SessionId {
+			err = SendCasLogoutRequests(sessionId)
+			if err != nil {
+				return false, err
+			}
 		}
 
-		if session != nil {
+		if owner == CasdoorOrganization && application == CasdoorApplication {
 			DeleteBeegoSession(session.SessionId)
 		}
 	}
@@ -159,6 +166,11 @@ func DeleteSession(id string) (bool, error) {
 }
 
 func DeleteSessionId(id string, sessionId string) (bool, error) {
+	err := SendCasLogoutRequests(sessionId)
+	if err != nil {
+		return false, err
+	}
+
 	session, err := GetSingleSession(id)
 	if err != nil {
 		return false, err
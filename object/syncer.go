@@ -16,11 +16,24 @@ package object
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/casdoor/casdoor/util"
 	"github.com/xorm-io/core"
 )
 
+// The conflict policies decide which side wins when a user is changed both in Casdoor and in the source since the
+// last sync. With the field ownership policy, each column is only written from the side that owns it.
+const (
+	SyncerConflictPolicySource  = "Source"
+	SyncerConflictPolicyCasdoor = "Casdoor"
+	SyncerConflictPolicyNewest  = "Newest"
+	SyncerConflictPolicyField   = "Field"
+
+	SyncerSideSource  = "Source"
+	SyncerSideCasdoor = "Casdoor"
+)
+
 type TableColumn struct {
 	Name        string   `json:"name"`
 	Type        string   `json:"type"`
@@ -28,6 +41,7 @@ type TableColumn struct {
 	IsKey       bool     `json:"isKey"`
 	IsHashed    bool     `json:"isHashed"`
 	Values      []string `json:"values"`
+	Owner       string   `json:"owner"`
 }
 
 type Syncer struct {
@@ -60,6 +74,21 @@ type Syncer struct {
 	IsReadOnly       bool           `json:"isReadOnly"`
 	IsEnabled        bool           `json:"isEnabled"`
 
+	ConflictPolicy string `xorm:"varchar(100)" json:"conflictPolicy"`
+
+	Format        string `xorm:"varchar(100)" json:"format"`
+	Provider      string `xorm:"varchar(100)" json:"provider"`
+	Path          string `xorm:"varchar(500)" json:"path"`
+	RecordsPath   string `xorm:"varchar(100)" json:"recordsPath"`
+	NextPath      string `xorm:"varchar(100)" json:"nextPath"`
+	PageParam     string `xorm:"varchar(100)" json:"pageParam"`
+	PageSizeParam string `xorm:"varchar(100)" json:"pageSizeParam"`
+	PageSize      int    `json:"pageSize"`
+
+	ReplicationSlot string `xorm:"varchar(100)" json:"replicationSlot"`
+	Publication     string `xorm:"varchar(100)" json:"publication"`
+	ReplicationLsn  string `xorm:"varchar(100)" json:"replicationLsn"`
+
 	Ormer *Ormer `xorm:"-" json:"-"`
 }
 
@@ -166,12 +195,25 @@ func UpdateSyncer(id string, syncer *Syncer) (bool, error) {
 	if syncer.Password == "***" {
 		syncer.Password = s.Password
 	}
+	// the checkpoint is written by the change capture only and starts over with another slot
+	syncer.ReplicationLsn = ""
+	isSameReplication := syncer.IsEnabled && syncer.isReplicationEnabled() && syncer.ReplicationSlot == s.ReplicationSlot &&
+		syncer.Publication == s.Publication && syncer.Host == s.Host && syncer.Port == s.Port && syncer.Database == s.Database
+	if isSameReplication {
+		syncer.ReplicationLsn = s.ReplicationLsn
+	}
 	affected, err := session.Update(syncer)
 	if err != nil {
 		return false, err
 	}
 
 	if affected == 1 {
+		if s.IsEnabled && !isSameReplication {
+			dropSyncerReplication(s)
+		} else {
+			stopSyncerReplication(s)
+		}
+
 		err = addSyncerJob(syncer)
 		if err != nil {
 			return false, err
@@ -181,24 +223,11 @@ func UpdateSyncer(id string, syncer *Syncer) (bool, error) {
 	return affected != 0, nil
 }
 
-func updateSyncerErrorText(syncer *Syncer, line string) (bool, error) {
-	s, err := getSyncer(syncer.Owner, syncer.Name)
-	if err != nil {
-		return false, err
-	}
-
-	if s == nil {
-		return false, nil
-	}
-
-	s.ErrorText = s.ErrorText + line
-
-	affected, err := ormer.Engine.ID(core.PK{s.Owner, s.Name}).Cols("error_text").Update(s)
-	if err != nil {
-		return false, err
-	}
-
-	return affected != 0, nil
+// updateErrorText keeps the error of the last sync on the syncer, it is cleared by a successful sync
+func (syncer *Syncer) updateErrorText(errorText string) error {
+	syncer.ErrorText = errorText
+	_, err := ormer.Engine.ID(core.PK{syncer.Owner, syncer.Name}).Cols("error_text").Update(syncer)
+	return err
 }
 
 func AddSyncer(syncer *Syncer) (bool, error) {
@@ -218,6 +247,11 @@ func AddSyncer(syncer *Syncer) (bool, error) {
 }
 
 func DeleteSyncer(syncer *Syncer) (bool, error) {
+	s, err := getSyncer(syncer.Owner, syncer.Name)
+	if err != nil {
+		return false, err
+	}
+
 	affected, err := ormer.Engine.ID(core.PK{syncer.Owner, syncer.Name}).Delete(&Syncer{})
 	if err != nil {
 		return false, err
@@ -225,6 +259,14 @@ func DeleteSyncer(syncer *Syncer) (bool, error) {
 
 	if affected == 1 {
 		deleteSyncerJob(syncer)
+		if s != nil && s.IsEnabled {
+			dropSyncerReplication(s)
+		}
+
+		err = deleteSyncerRuns(syncer)
+		if err != nil {
+			return false, err
+		}
 	}
 
 	return affected != 0, nil
@@ -278,13 +320,62 @@ func (syncer *Syncer) getKey() string {
 	return util.CamelToSnakeCase(column.CasdoorName)
 }
 
-func RunSyncer(syncer *Syncer) error {
+func (syncer *Syncer) getColumnOwner(tableColumn *TableColumn) string {
+	if tableColumn.Owner == SyncerSideCasdoor {
+		return SyncerSideCasdoor
+	}
+	return SyncerSideSource
+}
+
+// isColumnWritable returns whether the column is written to the target side
+func (syncer *Syncer) isColumnWritable(tableColumn *TableColumn, target string) bool {
+	if syncer.ConflictPolicy != SyncerConflictPolicyField {
+		return true
+	}
+	return syncer.getColumnOwner(tableColumn) != target
+}
+
+// getConflictWinner returns the side whose changes are kept for a user changed on both sides, the newest policy
+// falls back to the source when the updated time of either side is unknown
+func (syncer *Syncer) getConflictWinner(user *User, originalUser *OriginalUser) string {
+	switch syncer.ConflictPolicy {
+	case SyncerConflictPolicyCasdoor:
+		return SyncerSideCasdoor
+	case SyncerConflictPolicyNewest:
+		updatedTime, err := time.Parse(time.RFC3339, user.UpdatedTime)
+		if err != nil {
+			return SyncerSideSource
+		}
+		originalUpdatedTime, err := time.Parse(time.RFC3339, originalUser.UpdatedTime)
+		if err != nil {
+			return SyncerSideSource
+		}
+		if updatedTime.After(originalUpdatedTime) {
+			return SyncerSideCasdoor
+		}
+		return SyncerSideSource
+	default:
+		return SyncerSideSource
+	}
+}
+
+func RunSyncer(syncer *Syncer) (*SyncerRun, error) {
 	err := syncer.initAdapter()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return syncer.runSync(false)
+}
+
+// DryRunSyncer computes the changes that the syncer would make without applying them
+func DryRunSyncer(syncer *Syncer) (*SyncerRun, error) {
+	err := syncer.initAdapter()
+	if err != nil {
+		return nil, err
 	}
 
-	return syncer.syncUsers()
+	return syncer.runSync(true)
 }
 
 func TestSyncerDb(syncer Syncer) error {
@@ -297,6 +388,11 @@ func TestSyncerDb(syncer Syncer) error {
 		syncer.Password = oldSyncer.Password
 	}
 
+	if !syncer.isDatabase() {
+		_, err = syncer.getSourceRecords()
+		return err
+	}
+
 	err = syncer.initAdapter()
 	if err != nil {
 		return err
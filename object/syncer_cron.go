@@ -68,9 +68,14 @@ func addSyncerJob(syncer *Syncer) error {
 	}
 
 	cron.Start()
+
+	if syncer.isReplicationEnabled() {
+		startSyncerReplication(syncer)
+	}
 	return nil
 }
 
 func deleteSyncerJob(syncer *Syncer) {
 	clearCron(syncer.Name)
+	stopSyncerReplication(syncer)
 }
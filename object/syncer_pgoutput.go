@@ -0,0 +1,237 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The messages of the pgoutput plugin of PostgreSQL logical replication, protocol version 1:
+// https://www.postgresql.org/docs/current/protocol-logicalrep-message-formats.html
+
+const (
+	pgoutputMessageBegin    = 'B'
+	pgoutputMessageCommit   = 'C'
+	pgoutputMessageOrigin   = 'O'
+	pgoutputMessageRelation = 'R'
+	pgoutputMessageType     = 'Y'
+	pgoutputMessageInsert   = 'I'
+	pgoutputMessageUpdate   = 'U'
+	pgoutputMessageDelete   = 'D'
+	pgoutputMessageTruncate = 'T'
+
+	pgoutputTupleNull      = 'n'
+	pgoutputTupleUnchanged = 'u'
+	pgoutputTupleText      = 't'
+)
+
+type pgoutputRelation struct {
+	Id        uint32
+	Namespace string
+	Name      string
+	Columns   []string
+}
+
+// pgoutputTuple is the values of a row by column name, the unchanged TOASTed values are not sent by PostgreSQL
+type pgoutputTuple struct {
+	Values    map[string]sql.NullString
+	Unchanged []string
+}
+
+// pgoutputMessage is a parsed message, the LSN of a begin or commit message is the commit LSN of the transaction
+type pgoutputMessage struct {
+	Type     byte
+	Lsn      uint64
+	EndLsn   uint64
+	Relation *pgoutputRelation
+	OldTuple *pgoutputTuple
+	NewTuple *pgoutputTuple
+}
+
+type pgoutputReader struct {
+	data []byte
+	err  error
+}
+
+func (r *pgoutputReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = fmt.Errorf("the pgoutput message is truncated")
+		return nil
+	}
+
+	res := r.data[:n]
+	r.data = r.data[n:]
+	return res
+}
+
+func (r *pgoutputReader) readByte() byte {
+	b := r.read(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *pgoutputReader) readInt16() int {
+	b := r.read(2)
+	if b == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(b))
+}
+
+func (r *pgoutputReader) readInt32() uint32 {
+	b := r.read(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *pgoutputReader) readInt64() uint64 {
+	b := r.read(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (r *pgoutputReader) readString() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.data, 0)
+	if i < 0 {
+		r.err = fmt.Errorf("the pgoutput string is not terminated")
+		return ""
+	}
+
+	res := string(r.data[:i])
+	r.data = r.data[i+1:]
+	return res
+}
+
+func (r *pgoutputReader) readTuple(relation *pgoutputRelation) *pgoutputTuple {
+	tuple := &pgoutputTuple{Values: map[string]sql.NullString{}, Unchanged: []string{}}
+	count := r.readInt16()
+	for i := 0; i < count && r.err == nil; i++ {
+		name := ""
+		if i < len(relation.Columns) {
+			name = relation.Columns[i]
+		}
+
+		switch kind := r.readByte(); kind {
+		case pgoutputTupleNull:
+			tuple.Values[name] = sql.NullString{}
+		case pgoutputTupleUnchanged:
+			tuple.Unchanged = append(tuple.Unchanged, name)
+		case pgoutputTupleText:
+			value := r.read(int(r.readInt32()))
+			tuple.Values[name] = sql.NullString{String: string(value), Valid: true}
+		default:
+			if r.err == nil {
+				r.err = fmt.Errorf("the pgoutput tuple kind: %c is not supported", kind)
+			}
+		}
+	}
+	return tuple
+}
+
+// parsePgoutputMessage parses a message, the relations are the relation messages received before, which describe
+// the columns of the rows of the following insert, update and delete messages
+func parsePgoutputMessage(data []byte, relations map[uint32]*pgoutputRelation) (*pgoutputMessage, error) {
+	r := &pgoutputReader{data: data}
+	message := &pgoutputMessage{Type: r.readByte()}
+
+	switch message.Type {
+	case pgoutputMessageRelation:
+		relation := &pgoutputRelation{Id: r.readInt32(), Namespace: r.readString(), Name: r.readString()}
+		r.readByte() // replica identity
+		count := r.readInt16()
+		for i := 0; i < count && r.err == nil; i++ {
+			r.readByte() // flags
+			relation.Columns = append(relation.Columns, r.readString())
+			r.read(8) // type oid and modifier
+		}
+		if r.err == nil {
+			relations[relation.Id] = relation
+		}
+		message.Relation = relation
+	case pgoutputMessageInsert, pgoutputMessageUpdate, pgoutputMessageDelete:
+		id := r.readInt32()
+		relation, ok := relations[id]
+		if r.err == nil && !ok {
+			return nil, fmt.Errorf("the pgoutput relation: %d is unknown", id)
+		}
+		message.Relation = relation
+
+		for r.err == nil && len(r.data) != 0 {
+			switch kind := r.readByte(); kind {
+			case 'K', 'O':
+				message.OldTuple = r.readTuple(relation)
+			case 'N':
+				message.NewTuple = r.readTuple(relation)
+			default:
+				r.err = fmt.Errorf("the pgoutput tuple type: %c is not supported", kind)
+			}
+		}
+	case pgoutputMessageBegin:
+		message.Lsn = r.readInt64()
+	case pgoutputMessageCommit:
+		r.readByte() // flags
+		message.Lsn = r.readInt64()
+		message.EndLsn = r.readInt64()
+	case pgoutputMessageOrigin, pgoutputMessageType, pgoutputMessageTruncate:
+	default:
+		if r.err == nil {
+			return nil, fmt.Errorf("the pgoutput message type: %c is not supported", message.Type)
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return message, nil
+}
+
+// parseLsn parses a log sequence number in the "X/X" text format of PostgreSQL
+func parseLsn(lsn string) (uint64, error) {
+	tokens := strings.Split(lsn, "/")
+	if len(tokens) != 2 {
+		return 0, fmt.Errorf("the LSN: %s is invalid", lsn)
+	}
+
+	high, err := strconv.ParseUint(tokens[0], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("the LSN: %s is invalid", lsn)
+	}
+	low, err := strconv.ParseUint(tokens[1], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("the LSN: %s is invalid", lsn)
+	}
+	return high<<32 | low, nil
+}
+
+func formatLsn(lsn uint64) string {
+	return fmt.Sprintf("%X/%X", lsn>>32, uint32(lsn))
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pgoutputWriter []byte
+
+func (w pgoutputWriter) int16(v int) pgoutputWriter {
+	return binary.BigEndian.AppendUint16(w, uint16(v))
+}
+
+func (w pgoutputWriter) int32(v int) pgoutputWriter {
+	return binary.BigEndian.AppendUint32(w, uint32(v))
+}
+
+func (w pgoutputWriter) int64(v uint64) pgoutputWriter {
+	return binary.BigEndian.AppendUint64(w, v)
+}
+
+func (w pgoutputWriter) str(s string) pgoutputWriter {
+	return append(append(w, s...), 0)
+}
+
+func (w pgoutputWriter) text(s string) pgoutputWriter {
+	return append(append(w, 't').int32(len(s)), s...)
+}
+
+func TestParsePgoutputMessage(t *testing.T) {
+	relations := map[uint32]*pgoutputRelation{}
+
+	relation := append(pgoutputWriter{'R'}.int32(16384).str("public").str("users"), 'd').int16(3)
+	for _, column := range []string{"id", "name", "bio"} {
+		relation = append(relation, 1)
+		relation = relation.str(column).int32(25).int32(-1)
+	}
+	message, err := parsePgoutputMessage(relation, relations)
+	assert.Nil(t, err)
+	assert.Equal(t, "users", message.Relation.Name)
+	assert.Equal(t, []string{"id", "name", "bio"}, relations[16384].Columns)
+
+	update := pgoutputWriter{'U'}.int32(16384)
+	update = append(update, 'N')
+	update = append(update.int16(3).text("1").text("alice"), 'u')
+	message, err = parsePgoutputMessage(update, relations)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]sql.NullString{"id": {String: "1", Valid: true}, "name": {String: "alice", Valid: true}}, message.NewTuple.Values)
+	assert.Equal(t, []string{"bio"}, message.NewTuple.Unchanged)
+
+	commit := append(pgoutputWriter{'C'}, 0).int64(0x16B3748).int64(0x16B3778).int64(0)
+	message, err = parsePgoutputMessage(commit, relations)
+	assert.Nil(t, err)
+	assert.Equal(t, "0/16B3748", formatLsn(message.Lsn))
+
+	_, err = parsePgoutputMessage(pgoutputWriter{'I'}.int32(1), relations)
+	assert.NotNil(t, err)
+
+	lsn, err := parseLsn("1/16B3778")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0x1016B3778), lsn)
+}
@@ -0,0 +1,368 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xorm-io/core"
+)
+
+// A PostgreSQL syncer with a replication slot captures the changes of the table from the logical replication slot
+// with the pgoutput plugin and syncs the changed users between the scheduled full syncs. The changes are confirmed
+// to the slot after they are applied, the commit LSN of the last applied transaction is saved in the syncer so
+// that a transaction confirmed to Casdoor but not to the slot is not applied twice.
+
+const (
+	syncerReplicationInterval   = time.Second
+	syncerReplicationMaxChanges = 1000
+)
+
+var (
+	replicationMap      = map[string]chan struct{}{}
+	replicationMapMutex sync.Mutex
+)
+
+type syncerReplicationChange struct {
+	Lsn  string
+	Data []byte
+}
+
+func (syncer *Syncer) isReplicationEnabled() bool {
+	return syncer.isDatabase() && syncer.DatabaseType == "postgres" && syncer.ReplicationSlot != ""
+}
+
+func (syncer *Syncer) getPublication() string {
+	if syncer.Publication != "" {
+		return syncer.Publication
+	}
+	return syncer.ReplicationSlot
+}
+
+func (syncer *Syncer) isReplicationTable(relation *pgoutputRelation) bool {
+	if strings.Contains(syncer.Table, ".") {
+		return syncer.Table == fmt.Sprintf("%s.%s", relation.Namespace, relation.Name)
+	}
+	return syncer.Table == relation.Name
+}
+
+func startSyncerReplication(syncer *Syncer) {
+	stopSyncerReplication(syncer)
+
+	stop := make(chan struct{})
+	replicationMapMutex.Lock()
+	replicationMap[syncer.GetId()] = stop
+	replicationMapMutex.Unlock()
+
+	go syncer.runReplication(stop)
+}
+
+func stopSyncerReplication(syncer *Syncer) {
+	replicationMapMutex.Lock()
+	defer replicationMapMutex.Unlock()
+
+	stop, ok := replicationMap[syncer.GetId()]
+	if ok {
+		close(stop)
+		delete(replicationMap, syncer.GetId())
+	}
+}
+
+// dropSyncerReplication stops the change capture and drops the replication slot, and the publication if Casdoor
+// created it, because an unused slot keeps the WAL of the source database forever
+func dropSyncerReplication(syncer *Syncer) {
+	stopSyncerReplication(syncer)
+	if !syncer.isReplicationEnabled() {
+		return
+	}
+
+	err := syncer.dropReplication()
+	if err != nil {
+		fmt.Printf("Syncer %s failed to drop the replication slot: %s, please drop it manually, error: %s\n", syncer.GetId(), syncer.ReplicationSlot, err.Error())
+	}
+}
+
+func (syncer *Syncer) dropReplication() error {
+	if syncer.Ormer == nil {
+		err := syncer.initAdapter()
+		if err != nil {
+			return err
+		}
+	}
+
+	db := syncer.Ormer.Engine.DB()
+	_, err := db.Exec("SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = $1", syncer.ReplicationSlot)
+	if err != nil {
+		return err
+	}
+
+	if syncer.Publication == "" {
+		_, err = db.Exec(fmt.Sprintf("DROP PUBLICATION IF EXISTS %s", quotePgIdentifier(syncer.ReplicationSlot)))
+	}
+	return err
+}
+
+func (syncer *Syncer) runReplication(stop chan struct{}) {
+	ticker := time.NewTicker(syncerReplicationInterval)
+	defer ticker.Stop()
+
+	isInitialized := false
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		var err error
+		if !isInitialized {
+			err = syncer.initReplication()
+			isInitialized = err == nil
+		}
+		if err == nil {
+			err = syncer.syncReplicationChanges()
+		}
+		if err != nil {
+			fmt.Printf("Syncer %s replication error: %s\n", syncer.GetId(), err.Error())
+		}
+	}
+}
+
+// initReplication creates the replication slot if it doesn't exist, and the publication of the table named after the
+// slot when no publication is set. A publication set in the syncer is managed by the user and must exist.
+func (syncer *Syncer) initReplication() error {
+	db := syncer.Ormer.Engine.DB()
+
+	var count int
+	err := db.QueryRow("SELECT count(*) FROM pg_publication WHERE pubname = $1", syncer.getPublication()).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 && syncer.Publication != "" {
+		return fmt.Errorf("the publication: %s doesn't exist", syncer.Publication)
+	}
+	if count == 0 {
+		_, err = db.Exec(fmt.Sprintf("CREATE PUBLICATION %s FOR TABLE %s", quotePgIdentifier(syncer.getPublication()), quotePgIdentifier(syncer.Table)))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = db.QueryRow("SELECT count(*) FROM pg_replication_slots WHERE slot_name = $1", syncer.ReplicationSlot).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		_, err = db.Exec("SELECT pg_create_logical_replication_slot($1, 'pgoutput')", syncer.ReplicationSlot)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func quotePgIdentifier(name string) string {
+	tokens := strings.Split(name, ".")
+	for i, token := range tokens {
+		tokens[i] = fmt.Sprintf(`"%s"`, strings.ReplaceAll(token, `"`, `""`))
+	}
+	return strings.Join(tokens, ".")
+}
+
+func (syncer *Syncer) getReplicationChanges() ([]*syncerReplicationChange, error) {
+	rows, err := syncer.Ormer.Engine.DB().Query("SELECT lsn::text, data FROM pg_logical_slot_peek_binary_changes($1, NULL, $2, 'proto_version', '1', 'publication_names', $3)",
+		syncer.ReplicationSlot, syncerReplicationMaxChanges, syncer.getPublication())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*syncerReplicationChange{}
+	for rows.Next() {
+		change := &syncerReplicationChange{}
+		err = rows.Scan(&change.Lsn, &change.Data)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// syncReplicationChanges applies the committed transactions of the slot and confirms them, the run is recorded in
+// the history of the syncer only if a user is changed
+func (syncer *Syncer) syncReplicationChanges() error {
+	changes, err := syncer.getReplicationChanges()
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	var checkpoint uint64
+	if syncer.ReplicationLsn != "" {
+		checkpoint, err = parseLsn(syncer.ReplicationLsn)
+		if err != nil {
+			return err
+		}
+	}
+
+	run := newSyncerRun(syncer, false)
+	replication := &syncerReplication{syncer: syncer, run: run, key: syncer.getKey()}
+	relations := map[uint32]*pgoutputRelation{}
+	isSkipped := false
+	var commitLsn, endLsn uint64
+	for _, change := range changes {
+		message, err := parsePgoutputMessage(change.Data, relations)
+		if err != nil {
+			return err
+		}
+
+		switch message.Type {
+		case pgoutputMessageBegin:
+			isSkipped = message.Lsn <= checkpoint
+		case pgoutputMessageCommit:
+			commitLsn, endLsn = message.Lsn, message.EndLsn
+		case pgoutputMessageInsert, pgoutputMessageUpdate, pgoutputMessageDelete:
+			if isSkipped || !syncer.isReplicationTable(message.Relation) {
+				continue
+			}
+
+			err = replication.apply(message)
+			if err != nil {
+				run.finish(err)
+				_ = addSyncerRun(run)
+				return err
+			}
+		}
+	}
+
+	if len(run.Diffs) != 0 {
+		run.finish(nil)
+		err = addSyncerRun(run)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Syncer %s replication: %s\n", syncer.GetId(), run)
+	}
+
+	if endLsn == 0 {
+		return nil
+	}
+
+	if commitLsn > checkpoint {
+		syncer.ReplicationLsn = formatLsn(commitLsn)
+		_, err = ormer.Engine.ID(core.PK{syncer.Owner, syncer.Name}).Cols("replication_lsn").Update(syncer)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = syncer.Ormer.Engine.DB().Exec("SELECT pg_replication_slot_advance($1, $2::pg_lsn)", syncer.ReplicationSlot, formatLsn(endLsn))
+	return err
+}
+
+// syncerReplication applies the row changes of a batch, the Casdoor users are loaded at the first change
+type syncerReplication struct {
+	syncer         *Syncer
+	run            *SyncerRun
+	key            string
+	users          map[string]*User
+	affiliationMap map[int]string
+}
+
+func (r *syncerReplication) init() error {
+	if r.users != nil {
+		return nil
+	}
+
+	users, err := GetUsers(r.syncer.Organization)
+	if err != nil {
+		return err
+	}
+
+	r.users = map[string]*User{}
+	for _, user := range users {
+		r.users[r.syncer.getUserValue(user, r.key)] = user
+	}
+
+	if r.syncer.AffiliationTable != "" {
+		_, r.affiliationMap, err = r.syncer.getAffiliationMap()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *syncerReplication) apply(message *pgoutputMessage) error {
+	err := r.init()
+	if err != nil {
+		return err
+	}
+
+	if message.Type == pgoutputMessageDelete {
+		// a user deleted from the source is kept in Casdoor like in a full sync, a writable source gets it back
+		// by the next full sync
+		return nil
+	}
+
+	if message.NewTuple == nil {
+		return nil
+	}
+
+	record := message.NewTuple.Values
+	if len(message.NewTuple.Unchanged) != 0 {
+		record, err = r.syncer.getReplicationRecord(record)
+		if err != nil || record == nil {
+			return err
+		}
+	}
+
+	oUser := r.syncer.getOriginalUsersFromMap([]map[string]sql.NullString{record})[0]
+	primary := r.syncer.getUserValue(oUser, r.key)
+	user, ok := r.users[primary]
+	if ok {
+		return r.syncer.syncUser(r.run, user, oUser, r.affiliationMap, r.key)
+	}
+
+	newUser := r.syncer.createUserFromOriginalUser(oUser, r.affiliationMap)
+	r.run.addDiff(primary, SyncerActionCreate, SyncerSideCasdoor, false, r.syncer.getFieldDiffs(nil, newUser, SyncerSideCasdoor))
+	_, err = AddUsersInBatch([]*User{newUser})
+	if err != nil {
+		return err
+	}
+
+	r.users[primary] = newUser
+	return nil
+}
+
+// getReplicationRecord reads the row of a change whose unchanged TOASTed values are not in the change
+func (syncer *Syncer) getReplicationRecord(record map[string]sql.NullString) (map[string]sql.NullString, error) {
+	keyColumn := syncer.getKeyColumn()
+	value, ok := record[keyColumn.Name]
+	if !ok {
+		return nil, fmt.Errorf("the key column: %s is not in the replication change", keyColumn.Name)
+	}
+
+	var results []map[string]sql.NullString
+	err := syncer.Ormer.Engine.Table(syncer.getTable()).Where(fmt.Sprintf("%s = ?", keyColumn.Name), value.String).Find(&results)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[0], nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	SyncerActionCreate = "Create"
+	SyncerActionUpdate = "Update"
+	SyncerActionSkip   = "Skip"
+
+	SyncerRunStateSucceeded = "Succeeded"
+	SyncerRunStateFailed    = "Failed"
+
+	// the diffs beyond the limit are only counted, a dry run returns all of them
+	syncerRunMaxDiffs = 1000
+	syncerRunMaxCount = 100
+)
+
+var syncerSensitiveFields = []string{"Password", "PasswordSalt", "TotpSecret", "RecoveryCodes"}
+
+type SyncerFieldDiff struct {
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+// SyncerDiff is a change of a user, the target is the side where the change is applied: "Casdoor" or "Source". A sync
+// never deletes users, a user missing on one side is created there, so there is no delete action.
+type SyncerDiff struct {
+	Key      string             `json:"key"`
+	Action   string             `json:"action"`
+	Target   string             `json:"target"`
+	Conflict bool               `json:"conflict"`
+	Fields   []*SyncerFieldDiff `json:"fields"`
+}
+
+// SyncerRun is the history record of a sync, the changes of a dry run are computed but not applied
+type SyncerRun struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Syncer      string        `xorm:"varchar(100) index" json:"syncer"`
+	IsDryRun    bool          `json:"isDryRun"`
+	EndTime     string        `xorm:"varchar(100)" json:"endTime"`
+	State       string        `xorm:"varchar(100)" json:"state"`
+	Created     int           `json:"created"`
+	Updated     int           `json:"updated"`
+	Skipped     int           `json:"skipped"`
+	Conflicts   int           `json:"conflicts"`
+	Diffs       []*SyncerDiff `xorm:"mediumtext" json:"diffs"`
+	IsTruncated bool          `json:"isTruncated"`
+	Error       string        `xorm:"mediumtext" json:"error"`
+}
+
+func newSyncerRun(syncer *Syncer, isDryRun bool) *SyncerRun {
+	return &SyncerRun{
+		Owner:       syncer.Owner,
+		Name:        util.GenerateTimeId(),
+		CreatedTime: util.GetCurrentTime(),
+		Syncer:      syncer.GetId(),
+		IsDryRun:    isDryRun,
+		Diffs:       []*SyncerDiff{},
+	}
+}
+
+func (run *SyncerRun) addDiff(key string, action string, target string, conflict bool, fields []*SyncerFieldDiff) {
+	switch action {
+	case SyncerActionCreate:
+		run.Created++
+	case SyncerActionUpdate:
+		run.Updated++
+	case SyncerActionSkip:
+		run.Skipped++
+	}
+	if conflict {
+		run.Conflicts++
+	}
+
+	run.Diffs = append(run.Diffs, &SyncerDiff{Key: key, Action: action, Target: target, Conflict: conflict, Fields: fields})
+}
+
+func (run *SyncerRun) finish(err error) {
+	run.EndTime = util.GetCurrentTime()
+	run.State = SyncerRunStateSucceeded
+	if err != nil {
+		run.State = SyncerRunStateFailed
+		run.Error = err.Error()
+	}
+}
+
+func (run *SyncerRun) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d skipped, %d conflicts",
+		run.Created, run.Updated, run.Skipped, run.Conflicts)
+}
+
+// getFieldDiffs returns the differences of the synced columns that are written to the target, the values of the
+// sensitive fields are masked
+func (syncer *Syncer) getFieldDiffs(oldUser *User, newUser *User, target string) []*SyncerFieldDiff {
+	oldMap := map[string]string{}
+	if oldUser != nil {
+		oldMap = syncer.getMapFromOriginalUser(oldUser)
+	}
+	newMap := syncer.getMapFromOriginalUser(newUser)
+
+	res := []*SyncerFieldDiff{}
+	for _, tableColumn := range syncer.TableColumns {
+		if !syncer.isColumnWritable(tableColumn, target) {
+			continue
+		}
+
+		oldValue := oldMap[tableColumn.Name]
+		newValue := newMap[tableColumn.Name]
+		if oldValue == newValue {
+			continue
+		}
+
+		if util.InSlice(syncerSensitiveFields, tableColumn.CasdoorName) {
+			if oldValue != "" {
+				oldValue = "***"
+			}
+			if newValue != "" {
+				newValue = "***"
+			}
+		}
+		res = append(res, &SyncerFieldDiff{Field: tableColumn.CasdoorName, OldValue: oldValue, NewValue: newValue})
+	}
+	return res
+}
+
+// addSyncerRun records the run and removes the oldest runs of the syncer beyond the retention limit
+func addSyncerRun(run *SyncerRun) error {
+	record := *run
+	if len(record.Diffs) > syncerRunMaxDiffs {
+		record.Diffs = record.Diffs[:syncerRunMaxDiffs]
+		record.IsTruncated = true
+	}
+
+	_, err := ormer.Engine.Insert(&record)
+	if err != nil {
+		return err
+	}
+
+	runs := []*SyncerRun{}
+	err = ormer.Engine.Cols("owner", "name").Where("syncer = ?", run.Syncer).Desc("created_time").Limit(1000, syncerRunMaxCount).Find(&runs)
+	if err != nil {
+		return err
+	}
+	for _, r := range runs {
+		_, err = ormer.Engine.Delete(&SyncerRun{Owner: r.Owner, Name: r.Name})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetSyncerRunCount(owner, syncer, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&SyncerRun{Syncer: syncer})
+}
+
+func GetPaginationSyncerRuns(owner, syncer string, offset, limit int, field, value, sortField, sortOrder string) ([]*SyncerRun, error) {
+	runs := []*SyncerRun{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&runs, &SyncerRun{Syncer: syncer})
+	if err != nil {
+		return runs, err
+	}
+
+	return runs, nil
+}
+
+func deleteSyncerRuns(syncer *Syncer) error {
+	_, err := ormer.Engine.Where("syncer = ?", syncer.GetId()).Delete(&SyncerRun{})
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSyncerFieldDiffs(t *testing.T) {
+	syncer := &Syncer{
+		ConflictPolicy: SyncerConflictPolicyField,
+		TableColumns: []*TableColumn{
+			{Name: "id", CasdoorName: "Id", IsKey: true},
+			{Name: "mail", CasdoorName: "Email"},
+			{Name: "phone", CasdoorName: "Phone", Owner: SyncerSideCasdoor},
+			{Name: "pwd", CasdoorName: "Password"},
+		},
+	}
+	user := &User{Id: "1", Email: "old@example.com", Phone: "123", Password: "a"}
+	oUser := &OriginalUser{Id: "1", Email: "new@example.com", Phone: "456", Password: "b"}
+
+	assert.Equal(t, []*SyncerFieldDiff{
+		{Field: "Email", OldValue: "old@example.com", NewValue: "new@example.com"},
+		{Field: "Password", OldValue: "***", NewValue: "***"},
+	}, syncer.getFieldDiffs(user, oUser, SyncerSideCasdoor))
+	assert.Equal(t, []*SyncerFieldDiff{
+		{Field: "Phone", OldValue: "456", NewValue: "123"},
+	}, syncer.getFieldDiffs(oUser, user, SyncerSideSource))
+
+	run := &SyncerRun{}
+	run.addDiff("1", SyncerActionUpdate, SyncerSideCasdoor, true, nil)
+	run.addDiff("2", SyncerActionCreate, SyncerSideSource, false, nil)
+	assert.Equal(t, "1 created, 1 updated, 0 skipped, 1 conflicts", run.String())
+}
+
+func TestGetSyncerConflictWinner(t *testing.T) {
+	user := &User{UpdatedTime: "2025-03-01T10:00:00+08:00"}
+	oUser := &OriginalUser{UpdatedTime: "2025-03-01T01:00:00Z"}
+
+	syncer := &Syncer{}
+	assert.Equal(t, SyncerSideSource, syncer.getConflictWinner(user, oUser))
+	syncer.ConflictPolicy = SyncerConflictPolicyCasdoor
+	assert.Equal(t, SyncerSideCasdoor, syncer.getConflictWinner(user, oUser))
+	syncer.ConflictPolicy = SyncerConflictPolicyNewest
+	assert.Equal(t, SyncerSideCasdoor, syncer.getConflictWinner(user, oUser))
+	oUser.UpdatedTime = "2025-03-01T03:00:00Z"
+	assert.Equal(t, SyncerSideSource, syncer.getConflictWinner(user, oUser))
+	oUser.UpdatedTime = ""
+	assert.Equal(t, SyncerSideSource, syncer.getConflictWinner(user, oUser))
+}
@@ -0,0 +1,398 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/proxy"
+	"github.com/casdoor/casdoor/util"
+)
+
+// The file and HTTP syncers read the records of a CSV, JSON or JSONL file and of a paginated REST API, the name of a
+// table column is the CSV header or the JSONPath of the value in a JSON record. Both sources are read-only.
+
+const (
+	SyncerTypeDatabase = "Database"
+	SyncerTypeKeycloak = "Keycloak"
+	SyncerTypeFile     = "File"
+	SyncerTypeHttp     = "HTTP"
+
+	SyncerFormatCsv   = "CSV"
+	SyncerFormatJson  = "JSON"
+	SyncerFormatJsonl = "JSONL"
+
+	syncerHttpMaxPages = 10000
+)
+
+func (syncer *Syncer) isDatabase() bool {
+	return syncer.Type != SyncerTypeFile && syncer.Type != SyncerTypeHttp
+}
+
+func (syncer *Syncer) isReadOnly() bool {
+	return syncer.IsReadOnly || !syncer.isDatabase()
+}
+
+func (syncer *Syncer) getSourceRecords() ([]map[string]sql.NullString, error) {
+	switch syncer.Type {
+	case SyncerTypeFile:
+		return syncer.getFileRecords()
+	case SyncerTypeHttp:
+		return syncer.getHttpRecords()
+	default:
+		return nil, fmt.Errorf("the syncer type: %s is not a file or HTTP syncer", syncer.Type)
+	}
+}
+
+// getSyncerLocalFilePath resolves the path of a local source file, which must be inside syncerFileBaseDir.
+// Local files are disabled when syncerFileBaseDir is empty.
+func getSyncerLocalFilePath(path string) (string, error) {
+	baseDir := conf.GetConfigString("syncerFileBaseDir")
+	if baseDir == "" {
+		return "", fmt.Errorf("local files are disabled, please set a storage provider for the syncer or set syncerFileBaseDir")
+	}
+
+	baseDir, err := filepath.EvalSymlinks(baseDir)
+	if err != nil {
+		return "", err
+	}
+	baseDir, err = filepath.Abs(baseDir)
+	if err != nil {
+		return "", err
+	}
+
+	fullPath, err := filepath.EvalSymlinks(filepath.Join(baseDir, path))
+	if err != nil {
+		return "", err
+	}
+
+	relPath, err := filepath.Rel(baseDir, fullPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the path: %s is outside syncerFileBaseDir", path)
+	}
+	return fullPath, nil
+}
+
+// IsLocalFile returns whether the syncer reads a local file instead of an object of a storage provider
+func (syncer *Syncer) IsLocalFile() bool {
+	return syncer.Type == SyncerTypeFile && syncer.Provider == ""
+}
+
+// openFile opens the local file relative to syncerFileBaseDir, or the object in the storage provider if the
+// provider is set
+func (syncer *Syncer) openFile() (io.ReadCloser, error) {
+	if syncer.Provider == "" {
+		path, err := getSyncerLocalFilePath(syncer.Path)
+		if err != nil {
+			return nil, err
+		}
+		return os.Open(path)
+	}
+
+	provider, err := GetProvider(util.GetId(syncer.Owner, syncer.Provider))
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("the provider: %s is not found", syncer.Provider)
+	}
+
+	storageProvider, err := getStorageProvider(provider, "en")
+	if err != nil {
+		return nil, err
+	}
+
+	_, objectKey := GetUploadFileUrl(provider, syncer.Path, false)
+	return storageProvider.GetStream(objectKey)
+}
+
+func (syncer *Syncer) getFileRecords() ([]map[string]sql.NullString, error) {
+	file, err := syncer.openFile()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch syncer.Format {
+	case SyncerFormatCsv:
+		return getCsvRecords(file)
+	case SyncerFormatJsonl:
+		return syncer.getJsonlRecords(file)
+	case SyncerFormatJson:
+		data, err := decodeJson(file)
+		if err != nil {
+			return nil, err
+		}
+		return syncer.getJsonRecords(data)
+	default:
+		return nil, fmt.Errorf("the syncer file format: %s is not supported", syncer.Format)
+	}
+}
+
+func getCsvRecords(reader io.Reader) ([]map[string]sql.NullString, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []map[string]sql.NullString{}
+	if len(rows) == 0 {
+		return records, nil
+	}
+
+	header := rows[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\uFEFF")
+	}
+	for _, row := range rows[1:] {
+		record := map[string]sql.NullString{}
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = sql.NullString{String: value, Valid: true}
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (syncer *Syncer) getJsonlRecords(reader io.Reader) ([]map[string]sql.NullString, error) {
+	records := []map[string]sql.NullString{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for i := 1; scanner.Scan(); i++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		data, err := decodeJson(bytes.NewReader(line))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i, err.Error())
+		}
+		records = append(records, syncer.getRecordFromJson(data))
+	}
+	return records, scanner.Err()
+}
+
+// getJsonRecords returns the records of the array at the records path, which defaults to the root
+func (syncer *Syncer) getJsonRecords(data interface{}) ([]map[string]sql.NullString, error) {
+	recordsPath := syncer.RecordsPath
+	if recordsPath == "" {
+		recordsPath = "$"
+	}
+
+	value, ok := util.GetJsonPathValue(data, recordsPath)
+	if !ok || value == nil {
+		return []map[string]sql.NullString{}, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the value at the records path: %s is not an array", recordsPath)
+	}
+
+	records := []map[string]sql.NullString{}
+	for _, item := range items {
+		records = append(records, syncer.getRecordFromJson(item))
+	}
+	return records, nil
+}
+
+// getRecordFromJson evaluates the JSONPath of each table column, a column joining several values with "+" has a
+// JSONPath for each value
+func (syncer *Syncer) getRecordFromJson(data interface{}) map[string]sql.NullString {
+	record := map[string]sql.NullString{}
+	for _, tableColumn := range syncer.TableColumns {
+		names := []string{tableColumn.Name}
+		if strings.Contains(tableColumn.Name, "+") {
+			names = strings.Split(tableColumn.Name, "+")
+		}
+
+		for _, name := range names {
+			name = strings.Trim(name, " ")
+			value, ok := util.GetJsonPathValue(data, name)
+			if ok {
+				record[name] = sql.NullString{String: getJsonString(value), Valid: value != nil}
+			}
+		}
+	}
+	return record
+}
+
+func decodeJson(reader io.Reader) (interface{}, error) {
+	var data interface{}
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func getJsonString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
+
+func (syncer *Syncer) doHttpRequest(requestUrl string) (interface{}, error) {
+	req, err := http.NewRequest(http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if syncer.User != "" {
+		req.SetBasicAuth(syncer.User, syncer.Password)
+	} else if syncer.Password != "" {
+		req.Header.Set("Authorization", "Bearer "+syncer.Password)
+	}
+
+	resp, err := proxy.DefaultHttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("the syncer request: %s returned status code: %d, %s", requestUrl, resp.StatusCode, string(body))
+	}
+	return decodeJson(resp.Body)
+}
+
+// getPageUrl sets the page parameter, which is a page number or a cursor, and the page size parameter of the URL
+func (syncer *Syncer) getPageUrl(page string) (string, error) {
+	u, err := url.Parse(syncer.Path)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	if page != "" {
+		query.Set(syncer.PageParam, page)
+	}
+	if syncer.PageSizeParam != "" && syncer.PageSize > 0 {
+		query.Set(syncer.PageSizeParam, strconv.Itoa(syncer.PageSize))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// getHttpRecords reads all the pages of the API. With a next path, the value at the path is the URL of the next
+// page, or the cursor of the next page if the page parameter is set too. With only the page parameter, the pages
+// are numbered from 1 until a page is empty or not full.
+func (syncer *Syncer) getHttpRecords() ([]map[string]sql.NullString, error) {
+	records := []map[string]sql.NullString{}
+
+	requestUrl := syncer.Path
+	var err error
+	if syncer.PageParam != "" {
+		firstPage := ""
+		if syncer.NextPath == "" {
+			firstPage = "1"
+		}
+		requestUrl, err = syncer.getPageUrl(firstPage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for page := 1; requestUrl != ""; page++ {
+		if page > syncerHttpMaxPages {
+			return nil, fmt.Errorf("the syncer API has more than %d pages", syncerHttpMaxPages)
+		}
+
+		data, err := syncer.doHttpRequest(requestUrl)
+		if err != nil {
+			return nil, err
+		}
+		pageRecords, err := syncer.getJsonRecords(data)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, pageRecords...)
+
+		nextUrl := ""
+		if syncer.NextPath != "" {
+			value, _ := util.GetJsonPathValue(data, syncer.NextPath)
+			next := getJsonString(value)
+			if next != "" && len(pageRecords) != 0 {
+				if syncer.PageParam != "" {
+					nextUrl, err = syncer.getPageUrl(next)
+				} else {
+					nextUrl, err = resolveUrl(requestUrl, next)
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else if syncer.PageParam != "" {
+			if len(pageRecords) != 0 && (syncer.PageSize == 0 || len(pageRecords) >= syncer.PageSize) {
+				nextUrl, err = syncer.getPageUrl(strconv.Itoa(page + 1))
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if nextUrl == requestUrl {
+			break
+		}
+		requestUrl = nextUrl
+	}
+
+	return records, nil
+}
+
+func resolveUrl(base string, ref string) (string, error) {
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refUrl, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return baseUrl.ResolveReference(refUrl).String(), nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/casdoor/casdoor/proxy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCsvRecords(t *testing.T) {
+	records, err := getCsvRecords(strings.NewReader("\uFEFFid,name\n1,alice\n2,bob\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "alice", records[0]["name"].String)
+	assert.Equal(t, "2", records[1]["id"].String)
+}
+
+func TestGetHttpRecords(t *testing.T) {
+	proxy.InitHttpClient()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprint(w, `{"data": [{"id": 1, "profile": {"first": "Alice", "last": "Smith"}}], "next": "c2"}`)
+		} else {
+			fmt.Fprint(w, `{"data": [{"id": 2, "profile": {"first": "Bob", "last": "Lee"}}], "next": null}`)
+		}
+	}))
+	defer server.Close()
+
+	syncer := &Syncer{
+		Type:        SyncerTypeHttp,
+		Path:        server.URL + "/users",
+		Password:    "token",
+		RecordsPath: "$.data",
+		NextPath:    "$.next",
+		PageParam:   "cursor",
+		TableColumns: []*TableColumn{
+			{Name: "$.id", CasdoorName: "Id", IsKey: true},
+			{Name: "$.profile.first + $.profile.last", CasdoorName: "DisplayName"},
+		},
+	}
+
+	users, err := syncer.getOriginalUsers()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, "1", users[0].Id)
+	assert.Equal(t, "Bob Lee", users[1].DisplayName)
+}
+
+func TestGetSyncerLocalFilePath(t *testing.T) {
+	t.Setenv("syncerFileBaseDir", "")
+	_, err := getSyncerLocalFilePath("users.csv")
+	assert.NotNil(t, err)
+
+	baseDir := t.TempDir()
+	err = os.WriteFile(filepath.Join(baseDir, "users.csv"), []byte("id\n"), 0o600)
+	assert.Nil(t, err)
+	t.Setenv("syncerFileBaseDir", baseDir)
+
+	path, err := getSyncerLocalFilePath("/users.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, "users.csv", filepath.Base(path))
+
+	_, err = getSyncerLocalFilePath("../users.csv")
+	assert.NotNil(t, err)
+}
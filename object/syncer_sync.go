@@ -16,11 +16,36 @@ package object
 
 import (
 	"fmt"
-
-	"github.com/casdoor/casdoor/util"
 )
 
 func (syncer *Syncer) syncUsers() error {
+	_, err := syncer.runSync(false)
+	return err
+}
+
+// runSync syncs the users and records the run in the history of the syncer
+func (syncer *Syncer) runSync(isDryRun bool) (*SyncerRun, error) {
+	run := newSyncerRun(syncer, isDryRun)
+	err := syncer.syncUsersWithRun(run)
+	run.finish(err)
+
+	err2 := addSyncerRun(run)
+	if err2 != nil {
+		fmt.Printf("addSyncerRun() error: %s\n", err2.Error())
+	}
+
+	if !isDryRun {
+		err2 = syncer.updateErrorText(run.Error)
+		if err2 != nil {
+			fmt.Printf("updateErrorText() error: %s\n", err2.Error())
+		}
+	}
+
+	fmt.Printf("Syncer %s: %s\n", syncer.GetId(), run)
+	return run, err
+}
+
+func (syncer *Syncer) syncUsersWithRun(run *SyncerRun) error {
 	if len(syncer.TableColumns) == 0 {
 		return fmt.Errorf("The syncer table columns should not be empty")
 	}
@@ -29,38 +54,20 @@ func (syncer *Syncer) syncUsers() error {
 
 	users, err := GetUsers(syncer.Organization)
 	if err != nil {
-		line := fmt.Sprintf("[%s] %s\n", util.GetCurrentTime(), err.Error())
-		_, err2 := updateSyncerErrorText(syncer, line)
-		if err2 != nil {
-			panic(err2)
-		}
-
 		return err
 	}
 
 	oUsers, err := syncer.getOriginalUsers()
 	if err != nil {
-		line := fmt.Sprintf("[%s] %s\n", util.GetCurrentTime(), err.Error())
-		_, err2 := updateSyncerErrorText(syncer, line)
-		if err2 != nil {
-			panic(err2)
-		}
-
 		return err
 	}
 
 	fmt.Printf("Users: %d, oUsers: %d\n", len(users), len(oUsers))
 
 	var affiliationMap map[int]string
-	if syncer.AffiliationTable != "" {
+	if syncer.AffiliationTable != "" && syncer.isDatabase() {
 		_, affiliationMap, err = syncer.getAffiliationMap()
 		if err != nil {
-			line := fmt.Sprintf("[%s] %s\n", util.GetCurrentTime(), err.Error())
-			_, err2 := updateSyncerErrorText(syncer, line)
-			if err2 != nil {
-				panic(err2)
-			}
-
 			return err
 		}
 	}
@@ -81,81 +88,37 @@ func (syncer *Syncer) syncUsers() error {
 	for _, oUser := range oUsers {
 		primary := syncer.getUserValue(oUser, key)
 
-		if _, ok := myUsers[primary]; !ok {
+		user, ok := myUsers[primary]
+		if !ok {
 			newUser := syncer.createUserFromOriginalUser(oUser, affiliationMap)
-			fmt.Printf("New user: %v\n", newUser)
+			run.addDiff(primary, SyncerActionCreate, SyncerSideCasdoor, false, syncer.getFieldDiffs(nil, newUser, SyncerSideCasdoor))
 			newUsers = append(newUsers, newUser)
-		} else {
-			user := myUsers[primary]
-			oHash := syncer.calculateHash(oUser)
-			if user.Hash == user.PreHash {
-				if user.Hash != oHash {
-					updatedUser := syncer.createUserFromOriginalUser(oUser, affiliationMap)
-					updatedUser.Hash = oHash
-					updatedUser.PreHash = oHash
-
-					fmt.Printf("Update from oUser to user: %v\n", updatedUser)
-					_, err = syncer.updateUserForOriginalFields(updatedUser, key)
-					if err != nil {
-						return err
-					}
-				}
-			} else {
-				if user.PreHash == oHash {
-					if !syncer.IsReadOnly {
-						updatedOUser := syncer.createOriginalUserFromUser(user)
-
-						fmt.Printf("Update from user to oUser: %v\n", updatedOUser)
-						_, err = syncer.updateUser(updatedOUser)
-						if err != nil {
-							return err
-						}
-					}
-
-					// update preHash
-					user.PreHash = user.Hash
-					_, err = SetUserField(user, "pre_hash", user.PreHash)
-					if err != nil {
-						return err
-					}
-				} else {
-					if user.Hash == oHash {
-						// update preHash
-						user.PreHash = user.Hash
-						_, err = SetUserField(user, "pre_hash", user.PreHash)
-						if err != nil {
-							return err
-						}
-					} else {
-						updatedUser := syncer.createUserFromOriginalUser(oUser, affiliationMap)
-						updatedUser.Hash = oHash
-						updatedUser.PreHash = oHash
-
-						fmt.Printf("Update from oUser to user (2nd condition): %v\n", updatedUser)
-						_, err = syncer.updateUserForOriginalFields(updatedUser, key)
-						if err != nil {
-							return err
-						}
-					}
-				}
-			}
+			continue
+		}
+
+		err = syncer.syncUser(run, user, oUser, affiliationMap, key)
+		if err != nil {
+			return err
 		}
 	}
 
-	if len(newUsers) != 0 {
+	if len(newUsers) != 0 && !run.IsDryRun {
 		_, err = AddUsersInBatch(newUsers)
 		if err != nil {
 			return err
 		}
 	}
 
-	if !syncer.IsReadOnly {
-		for _, user := range users {
-			primary := syncer.getUserValue(user, key)
-			if _, ok := myOUsers[primary]; !ok {
-				newOUser := syncer.createOriginalUserFromUser(user)
+	for _, user := range users {
+		primary := syncer.getUserValue(user, key)
+		if _, ok := myOUsers[primary]; ok {
+			continue
+		}
 
-				fmt.Printf("New oUser: %v\n", newOUser)
+		if !syncer.isReadOnly() {
+			newOUser := syncer.createOriginalUserFromUser(user)
+			run.addDiff(primary, SyncerActionCreate, SyncerSideSource, false, syncer.getFieldDiffs(nil, newOUser, SyncerSideSource))
+			if !run.IsDryRun {
 				_, err = syncer.addUser(newOUser)
 				if err != nil {
 					return err
@@ -167,6 +130,92 @@ func (syncer *Syncer) syncUsers() error {
 	return nil
 }
 
+// syncUser compares the hash of the source user with the hashes saved at the last sync to find the side that
+// changed, a user changed on both sides is resolved by the conflict policy
+func (syncer *Syncer) syncUser(run *SyncerRun, user *User, oUser *OriginalUser, affiliationMap map[int]string, key string) error {
+	oHash := syncer.calculateHash(oUser)
+	isCasdoorChanged := user.Hash != user.PreHash
+	isSourceChanged := user.PreHash != oHash
+
+	switch {
+	case !isCasdoorChanged && !isSourceChanged:
+		return nil
+	case !isCasdoorChanged:
+		return syncer.updateCasdoorUser(run, user, oUser, affiliationMap, key, false)
+	case !isSourceChanged:
+		return syncer.updateSourceUser(run, user, oUser, key, false)
+	case user.Hash == oHash:
+		return syncer.updatePreHash(run, user)
+	}
+
+	if syncer.ConflictPolicy == SyncerConflictPolicyField {
+		err := syncer.updateSourceUser(run, user, oUser, key, true)
+		if err != nil {
+			return err
+		}
+		return syncer.updateCasdoorUser(run, user, oUser, affiliationMap, key, true)
+	}
+
+	if syncer.getConflictWinner(user, oUser) == SyncerSideSource {
+		return syncer.updateCasdoorUser(run, user, oUser, affiliationMap, key, true)
+	}
+
+	if syncer.isReadOnly() {
+		// the hashes are kept so that the conflict is resolved again until the source agrees with Casdoor
+		updatedUser := syncer.createUserFromOriginalUser(oUser, affiliationMap)
+		run.addDiff(syncer.getUserValue(user, key), SyncerActionSkip, SyncerSideCasdoor, true, syncer.getFieldDiffs(user, updatedUser, SyncerSideCasdoor))
+		return nil
+	}
+	return syncer.updateSourceUser(run, user, oUser, key, true)
+}
+
+func (syncer *Syncer) updateCasdoorUser(run *SyncerRun, user *User, oUser *OriginalUser, affiliationMap map[int]string, key string, isConflict bool) error {
+	oHash := syncer.calculateHash(oUser)
+	updatedUser := syncer.createUserFromOriginalUser(oUser, affiliationMap)
+	updatedUser.Hash = oHash
+	updatedUser.PreHash = oHash
+
+	fields := syncer.getFieldDiffs(user, updatedUser, SyncerSideCasdoor)
+	if len(fields) != 0 || isConflict {
+		run.addDiff(syncer.getUserValue(user, key), SyncerActionUpdate, SyncerSideCasdoor, isConflict, fields)
+	}
+	if run.IsDryRun {
+		return nil
+	}
+
+	_, err := syncer.updateUserForOriginalFields(updatedUser, key)
+	return err
+}
+
+func (syncer *Syncer) updateSourceUser(run *SyncerRun, user *User, oUser *OriginalUser, key string, isConflict bool) error {
+	if !syncer.isReadOnly() {
+		updatedOUser := syncer.createOriginalUserFromUser(user)
+
+		fields := syncer.getFieldDiffs(oUser, updatedOUser, SyncerSideSource)
+		if len(fields) != 0 || isConflict {
+			run.addDiff(syncer.getUserValue(user, key), SyncerActionUpdate, SyncerSideSource, isConflict, fields)
+		}
+		if !run.IsDryRun {
+			_, err := syncer.updateUser(updatedOUser)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return syncer.updatePreHash(run, user)
+}
+
+func (syncer *Syncer) updatePreHash(run *SyncerRun, user *User) error {
+	if run.IsDryRun {
+		return nil
+	}
+
+	user.PreHash = user.Hash
+	_, err := SetUserField(user, "pre_hash", user.PreHash)
+	return err
+}
+
 func (syncer *Syncer) syncUsersNoError() {
 	err := syncer.syncUsers()
 	if err != nil {
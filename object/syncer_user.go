@@ -36,6 +36,14 @@ type Credential struct {
 }
 
 func (syncer *Syncer) getOriginalUsers() ([]*OriginalUser, error) {
+	if !syncer.isDatabase() {
+		results, err := syncer.getSourceRecords()
+		if err != nil {
+			return nil, err
+		}
+		return syncer.getOriginalUsersFromMap(results), nil
+	}
+
 	var results []map[string]sql.NullString
 	err := syncer.Ormer.Engine.Table(syncer.getTable()).Find(&results)
 	if err != nil {
@@ -66,7 +74,7 @@ func (syncer *Syncer) addUser(user *OriginalUser) (bool, error) {
 func (syncer *Syncer) getCasdoorColumns() []string {
 	res := []string{}
 	for _, tableColumn := range syncer.TableColumns {
-		if tableColumn.CasdoorName != "Id" {
+		if tableColumn.CasdoorName != "Id" && syncer.isColumnWritable(tableColumn, SyncerSideCasdoor) {
 			v := util.CamelToSnakeCase(tableColumn.CasdoorName)
 			res = append(res, v)
 		}
@@ -79,6 +87,14 @@ func (syncer *Syncer) updateUser(user *OriginalUser) (bool, error) {
 	m := syncer.getMapFromOriginalUser(user)
 	pkValue := m[key]
 	delete(m, key)
+	for _, tableColumn := range syncer.TableColumns {
+		if !syncer.isColumnWritable(tableColumn, SyncerSideSource) {
+			delete(m, tableColumn.Name)
+		}
+	}
+	if len(m) == 0 {
+		return false, nil
+	}
 
 	affected, err := syncer.Ormer.Engine.Table(syncer.getTable()).Where(fmt.Sprintf("%s = ?", key), pkValue).Update(&m)
 	if err != nil {
@@ -143,7 +159,7 @@ func (t dsnConnector) Driver() driver.Driver {
 }
 
 func (syncer *Syncer) initAdapter() error {
-	if syncer.Ormer != nil {
+	if syncer.Ormer != nil || !syncer.isDatabase() {
 		return nil
 	}
 
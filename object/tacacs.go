@@ -0,0 +1,147 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// TacacsResourceType is the resource type of the permissions authorizing TACACS+ sessions, a resource is
+// either a privilege level like "priv-lvl=15" or a command pattern like "show *"
+const TacacsResourceType = "TACACS+"
+
+const tacacsPrivLvlPrefix = "priv-lvl="
+
+type TacacsAccounting struct {
+	Owner       string    `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string    `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime time.Time `json:"createdTime"`
+
+	Username string `xorm:"index" json:"username"`
+	NasAddr  string `json:"nasAddr"`
+	Port     string `json:"port"`    // The port the user is connected to on the device, e.g. "tty0" or "vty1"
+	RemAddr  string `json:"remAddr"` // The address the user connects to the device from
+	Service  string `json:"service"` // e.g. "shell"
+	TaskId   string `xorm:"index" json:"taskId"`
+	PrivLvl  int    `json:"privLvl"`
+	Command  string `xorm:"mediumtext" json:"command"`
+
+	StartTime   time.Time `json:"startTime"`
+	StopTime    time.Time `json:"stopTime"`
+	ElapsedTime int64     `json:"elapsedTime"`
+	LastUpdate  time.Time `json:"lastUpdate"`
+}
+
+func (ta *TacacsAccounting) GetId() string {
+	return util.GetId(ta.Owner, ta.Name)
+}
+
+func GetTacacsAccountingByTaskId(nasAddr string, taskId string) (*TacacsAccounting, error) {
+	if taskId == "" {
+		return nil, nil
+	}
+
+	ta := TacacsAccounting{}
+	existed, err := ormer.Engine.Where("nas_addr = ? and task_id = ?", nasAddr, taskId).Desc("created_time").Get(&ta)
+	if err != nil {
+		return nil, err
+	}
+
+	if existed {
+		return &ta, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func AddTacacsAccounting(ta *TacacsAccounting) error {
+	_, err := ormer.Engine.Insert(ta)
+	return err
+}
+
+func UpdateTacacsAccounting(ta *TacacsAccounting) error {
+	_, err := ormer.Engine.ID(core.PK{ta.Owner, ta.Name}).AllCols().Update(ta)
+	return err
+}
+
+// TacacsAuthorization is what the TACACS+ permissions of a user, directly or through roles, allow
+type TacacsAuthorization struct {
+	IsAllowed       bool
+	PrivLvl         int
+	AllowedCommands []string
+	DeniedCommands  []string
+}
+
+func GetTacacsAuthorization(userId string) (*TacacsAuthorization, error) {
+	permissions, _, err := getPermissionsAndRolesByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTacacsAuthorization(permissions), nil
+}
+
+func getTacacsAuthorization(permissions []*Permission) *TacacsAuthorization {
+	authorization := &TacacsAuthorization{PrivLvl: 1}
+	for _, permission := range permissions {
+		if !permission.IsEnabled || permission.ResourceType != TacacsResourceType {
+			continue
+		}
+
+		isDenied := permission.Effect == "Deny"
+		if !isDenied {
+			authorization.IsAllowed = true
+		}
+
+		for _, resource := range permission.Resources {
+			if strings.HasPrefix(resource, tacacsPrivLvlPrefix) {
+				privLvl, err := strconv.Atoi(strings.TrimPrefix(resource, tacacsPrivLvlPrefix))
+				if err == nil && !isDenied && privLvl > authorization.PrivLvl && privLvl <= 15 {
+					authorization.PrivLvl = privLvl
+				}
+			} else if isDenied {
+				authorization.DeniedCommands = append(authorization.DeniedCommands, resource)
+			} else {
+				authorization.AllowedCommands = append(authorization.AllowedCommands, resource)
+			}
+		}
+	}
+	return authorization
+}
+
+func isTacacsCommandMatched(patterns []string, command string) bool {
+	for _, pattern := range patterns {
+		expression := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSpace(pattern)), `\*`, ".*") + "$"
+		if matched, err := regexp.MatchString(expression, command); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCommandAllowed checks a command line like "show running-config", deny patterns take precedence
+func (authorization *TacacsAuthorization) IsCommandAllowed(command string) bool {
+	command = strings.Join(strings.Fields(command), " ")
+	if isTacacsCommandMatched(authorization.DeniedCommands, command) {
+		return false
+	}
+	return isTacacsCommandMatched(authorization.AllowedCommands, command)
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTacacsAuthorization(t *testing.T) {
+	permissions := []*Permission{
+		{ResourceType: TacacsResourceType, Resources: []string{"priv-lvl=7", "show *"}, Effect: "Allow", IsEnabled: true},
+		{ResourceType: TacacsResourceType, Resources: []string{"priv-lvl=15", "configure *"}, Effect: "Allow", IsEnabled: false},
+		{ResourceType: TacacsResourceType, Resources: []string{"show running-config"}, Effect: "Deny", IsEnabled: true},
+		{ResourceType: "Application", Resources: []string{"*"}, Effect: "Allow", IsEnabled: true},
+	}
+
+	authorization := getTacacsAuthorization(permissions)
+	assert.True(t, authorization.IsAllowed)
+	assert.Equal(t, 7, authorization.PrivLvl)
+	assert.True(t, authorization.IsCommandAllowed("show  interfaces Gi1/0/1"))
+	assert.False(t, authorization.IsCommandAllowed("show running-config"))
+	assert.False(t, authorization.IsCommandAllowed("configure terminal"))
+
+	assert.False(t, getTacacsAuthorization(permissions[3:]).IsAllowed)
+}
@@ -21,9 +21,7 @@ import (
 	"encoding/pem"
 	"encoding/xml"
 	"fmt"
-	"math/rand"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/beevik/etree"
@@ -88,12 +86,6 @@ type CasAnyAttribute struct {
 	Value   string `xml:",chardata"`
 }
 
-type CasAuthenticationSuccessWrapper struct {
-	AuthenticationSuccess *CasAuthenticationSuccess // the token we issued
-	Service               string                    // to which service this token is issued
-	UserId                string
-}
-
 type CasProxySuccess struct {
 	XMLName     xml.Name `xml:"cas:proxySuccess" json:"-"`
 	ProxyTicket string   `xml:"cas:proxyTicket"`
@@ -118,27 +110,42 @@ type Saml11AssertionArtifact struct {
 	InnerXML string   `xml:",innerxml"`
 }
 
-// st is short for service ticket
-var stToServiceResponse sync.Map
-
-// pgt is short for proxy granting ticket
-var pgtToServiceResponse sync.Map
-
-func CheckCasLogin(application *Application, lang string, service string) error {
+// CheckCasLogin checks the service before the login, and the MFA required by the service when the user is given,
+// a service that requires MFA accepts only the users who passed MFA in the current login session
+func CheckCasLogin(application *Application, lang string, service string, user *User, isMfaVerified bool) error {
 	if len(application.RedirectUris) > 0 && !application.IsRedirectUriValid(service) {
 		return fmt.Errorf(i18n.Translate(lang, "token:Redirect URI: %s doesn't exist in the allowed Redirect URI list"), service)
 	}
+
+	if !application.IsCasServiceRegistryEnabled() {
+		return nil
+	}
+
+	casService := application.GetCasService(service)
+	if casService == nil {
+		return fmt.Errorf(i18n.Translate(lang, "cas:The service: %s is not registered in the application"), service)
+	}
+
+	if user != nil && casService.RequireMfa && !(user.IsMfaEnabled() && isMfaVerified) {
+		return fmt.Errorf(i18n.Translate(lang, "cas:The service: %s requires multi-factor authentication, please sign in with MFA"), service)
+	}
 	return nil
 }
 
-func StoreCasTokenForPgt(token *CasAuthenticationSuccess, service, userId string) string {
+// StoreCasTokenForPgt issues a proxy granting ticket for the validated service ticket
+func StoreCasTokenForPgt(token *CasAuthenticationSuccess, ticket *CasTicket) (string, error) {
 	pgt := fmt.Sprintf("PGT-%s", util.GenerateId())
-	pgtToServiceResponse.Store(pgt, &CasAuthenticationSuccessWrapper{
-		AuthenticationSuccess: token,
-		Service:               service,
-		UserId:                userId,
-	})
-	return pgt
+	pgtTicket, err := newCasTicket(CasTicketTypeProxyGranting, pgt, token, ticket.Service, ticket.UserId, ticket.SessionId)
+	if err != nil {
+		return "", err
+	}
+
+	pgtTicket.Application = ticket.Application
+	err = addCasTicket(pgtTicket)
+	if err != nil {
+		return "", err
+	}
+	return pgt, nil
 }
 
 func GenerateId() {
@@ -147,42 +154,62 @@ func GenerateId() {
 
 // GetCasTokenByPgt
 /**
-@ret1: whether a token is found
+@ret1: the proxy granting ticket, nil if not found or expired
 @ret2: token, nil if not found
-@ret3: the service URL who requested to issue this token
-@ret4: userIf of user who requested to issue this token
+@ret3: error
 */
-func GetCasTokenByPgt(pgt string) (bool, *CasAuthenticationSuccess, string, string) {
-	if responseWrapperType, ok := pgtToServiceResponse.LoadAndDelete(pgt); ok {
-		responseWrapperTypeCast := responseWrapperType.(*CasAuthenticationSuccessWrapper)
-		return true, responseWrapperTypeCast.AuthenticationSuccess, responseWrapperTypeCast.Service, responseWrapperTypeCast.UserId
+func GetCasTokenByPgt(pgt string) (*CasTicket, *CasAuthenticationSuccess, error) {
+	ticket, err := getCasTicketRegistry().GetTicket(pgt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ticket == nil || ticket.Type != CasTicketTypeProxyGranting {
+		return nil, nil, nil
+	}
+
+	response, err := ticket.GetResponse()
+	if err != nil {
+		return nil, nil, err
 	}
-	return false, nil, "", ""
+	return ticket, response, nil
 }
 
-// GetCasTokenByTicket
+// GetCasTokenByTicket consumes a service ticket or proxy ticket, a ticket can be validated only once
 /**
-@ret1: whether a token is found
+@ret1: the ticket, nil if not found, expired or already used
 @ret2: token, nil if not found
-@ret3: the service URL who requested to issue this token
-@ret4: userIf of user who requested to issue this token
+@ret3: error
 */
-func GetCasTokenByTicket(ticket string) (bool, *CasAuthenticationSuccess, string, string) {
-	if responseWrapperType, ok := stToServiceResponse.LoadAndDelete(ticket); ok {
-		responseWrapperTypeCast := responseWrapperType.(*CasAuthenticationSuccessWrapper)
-		return true, responseWrapperTypeCast.AuthenticationSuccess, responseWrapperTypeCast.Service, responseWrapperTypeCast.UserId
+func GetCasTokenByTicket(name string) (*CasTicket, *CasAuthenticationSuccess, error) {
+	ticket, err := getCasTicketRegistry().ConsumeTicket(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ticket == nil || ticket.Type == CasTicketTypeProxyGranting {
+		return nil, nil, nil
+	}
+
+	response, err := ticket.GetResponse()
+	if err != nil {
+		return nil, nil, err
 	}
-	return false, nil, "", ""
+	return ticket, response, nil
 }
 
-func StoreCasTokenForProxyTicket(token *CasAuthenticationSuccess, targetService, userId string) string {
+// StoreCasTokenForProxyTicket issues a proxy ticket for the target service by the proxy granting ticket
+func StoreCasTokenForProxyTicket(token *CasAuthenticationSuccess, targetService string, pgtTicket *CasTicket) (string, error) {
 	proxyTicket := fmt.Sprintf("PT-%s", util.GenerateId())
-	stToServiceResponse.Store(proxyTicket, &CasAuthenticationSuccessWrapper{
-		AuthenticationSuccess: token,
-		Service:               targetService,
-		UserId:                userId,
-	})
-	return proxyTicket
+	ticket, err := newCasTicket(CasTicketTypeProxy, proxyTicket, token, targetService, pgtTicket.UserId, pgtTicket.SessionId)
+	if err != nil {
+		return "", err
+	}
+
+	ticket.Application = pgtTicket.Application
+	err = addCasTicket(ticket)
+	if err != nil {
+		return "", err
+	}
+	return proxyTicket, nil
 }
 
 func escapeXMLText(input string) (string, error) {
@@ -194,7 +221,7 @@ func escapeXMLText(input string) (string, error) {
 	return sb.String(), nil
 }
 
-func GenerateCasToken(userId string, service string) (string, error) {
+func GenerateCasToken(application *Application, userId string, service string, sessionId string) (string, error) {
 	user, err := GetUser(userId)
 	if err != nil {
 		return "", err
@@ -203,6 +230,14 @@ func GenerateCasToken(userId string, service string) (string, error) {
 		return "", fmt.Errorf("The user: %s doesn't exist", userId)
 	}
 
+	var casService *CasService
+	if application.IsCasServiceRegistryEnabled() {
+		casService = application.GetCasService(service)
+		if casService == nil {
+			return "", fmt.Errorf("the service: %s is not registered in the application: %s", service, application.GetId())
+		}
+	}
+
 	user, _ = GetMaskedUser(user, false)
 
 	user.WebauthnCredentials = nil
@@ -234,6 +269,13 @@ func GenerateCasToken(userId string, service string) (string, error) {
 			value = ""
 		}
 
+		if casService != nil {
+			if !casService.IsAttributeReleased(k) {
+				continue
+			}
+			k = casService.GetAttributeAlias(k)
+		}
+
 		if value != "" {
 			if escapedValue, err := escapeXMLText(value); err != nil {
 				return "", err
@@ -247,12 +289,17 @@ func GenerateCasToken(userId string, service string) (string, error) {
 		}
 	}
 
-	st := fmt.Sprintf("ST-%d", rand.Int())
-	stToServiceResponse.Store(st, &CasAuthenticationSuccessWrapper{
-		AuthenticationSuccess: &authenticationSuccess,
-		Service:               service,
-		UserId:                userId,
-	})
+	st := fmt.Sprintf("ST-%s", util.GenerateId())
+	ticket, err := newCasTicket(CasTicketTypeService, st, &authenticationSuccess, service, userId, sessionId)
+	if err != nil {
+		return "", err
+	}
+
+	ticket.Application = application.GetId()
+	err = addCasTicket(ticket)
+	if err != nil {
+		return "", err
+	}
 	return st, nil
 }
 
@@ -274,11 +321,25 @@ func GetValidationBySaml(samlRequest string, host string) (string, string, error
 		return "", "", fmt.Errorf("request.AssertionArtifact.InnerXML error, AssertionArtifact field not found")
 	}
 
-	ok, _, service, userId := GetCasTokenByTicket(ticket)
-	if !ok {
+	casTicket, _, err := GetCasTokenByTicket(ticket)
+	if err != nil {
+		return "", "", err
+	}
+	if casTicket == nil {
 		return "", "", fmt.Errorf("the CAS token for ticket %s is not found", ticket)
 	}
 
+	service, userId := casTicket.Service, casTicket.UserId
+
+	// check the registered CAS service of the application as the CAS protocol validation does
+	casService, isRegistered, err := GetCasServiceByTicket(casTicket)
+	if err != nil {
+		return "", "", err
+	}
+	if !isRegistered {
+		return "", "", fmt.Errorf("the service: %s is not registered", service)
+	}
+
 	user, err := GetUser(userId)
 	if err != nil {
 		return "", "", err
@@ -288,15 +349,15 @@ func GetValidationBySaml(samlRequest string, host string) (string, string, error
 		return "", "", fmt.Errorf("the user %s is not found", userId)
 	}
 
-	application, err := GetApplicationByUser(user)
+	application, err := GetApplication(casTicket.Application)
 	if err != nil {
 		return "", "", err
 	}
 	if application == nil {
-		return "", "", fmt.Errorf("the application for user %s is not found", userId)
+		return "", "", fmt.Errorf("the application: %s does not exist", casTicket.Application)
 	}
 
-	samlResponse, err := NewSamlResponse11(application, user, request.RequestID, host)
+	samlResponse, err := NewSamlResponse11(application, user, request.RequestID, host, casService)
 	if err != nil {
 		return "", "", err
 	}
@@ -0,0 +1,131 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/proxy"
+	"github.com/casdoor/casdoor/util"
+)
+
+type CasLogoutRequest struct {
+	XMLName      xml.Name `xml:"samlp:LogoutRequest"`
+	Samlp        string   `xml:"xmlns:samlp,attr"`
+	Saml         string   `xml:"xmlns:saml,attr"`
+	ID           string   `xml:"ID,attr"`
+	Version      string   `xml:"Version,attr"`
+	IssueInstant string   `xml:"IssueInstant,attr"`
+	NameID       string   `xml:"saml:NameID"`
+	SessionIndex string   `xml:"samlp:SessionIndex"`
+}
+
+// NewCasLogoutRequest builds the SAML logout request defined by the CAS protocol, the session index is the service ticket
+// https://apereo.github.io/cas/6.6.x/installation/Logout-Single-Signout.html#back-channel
+func NewCasLogoutRequest(nameId string, ticket string) (string, error) {
+	request := CasLogoutRequest{
+		Samlp:        "urn:oasis:names:tc:SAML:2.0:protocol",
+		Saml:         "urn:oasis:names:tc:SAML:2.0:assertion",
+		ID:           fmt.Sprintf("LR-%s", util.GenerateId()),
+		Version:      "2.0",
+		IssueInstant: time.Now().UTC().Format(time.RFC3339),
+		NameID:       nameId,
+		SessionIndex: ticket,
+	}
+
+	data, err := xml.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func sendCasLogoutRequest(ticket *CasTicket) error {
+	_, name := util.GetOwnerAndNameFromId(ticket.UserId)
+	logoutRequest, err := NewCasLogoutRequest(name, ticket.Name)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	form.Set("logoutRequest", logoutRequest)
+	req, err := http.NewRequest("POST", ticket.Service, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := proxy.DefaultHttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("the service: %s returned status code: %d", ticket.Service, resp.StatusCode)
+	}
+	return nil
+}
+
+// SendCasLogoutRequests notifies every service that validated a ticket within the SSO session that the session has ended,
+// and removes all the tickets of the session. The requests are sent in the background.
+func SendCasLogoutRequests(sessionId string) error {
+	if sessionId == "" {
+		return nil
+	}
+
+	registry := getCasTicketRegistry()
+	tickets, err := registry.GetTicketsBySessionId(sessionId)
+	if err != nil {
+		return err
+	}
+
+	logoutTickets := []*CasTicket{}
+	for _, ticket := range tickets {
+		err = registry.DeleteTicket(ticket.Name)
+		if err != nil {
+			return err
+		}
+
+		if ticket.Type != CasTicketTypeProxyGranting && ticket.IsUsed && ticket.Service != "" {
+			logoutTickets = append(logoutTickets, ticket)
+		}
+	}
+
+	err = registry.DeleteExpiredTickets()
+	if err != nil {
+		return err
+	}
+
+	if len(logoutTickets) == 0 {
+		return nil
+	}
+
+	util.SafeGoroutine(func() {
+		for _, ticket := range logoutTickets {
+			err := sendCasLogoutRequest(ticket)
+			if err != nil {
+				logs.Warning(fmt.Sprintf("CAS single logout failed for ticket: %s, error: %s", ticket.Name, err.Error()))
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,291 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	CasTicketTypeService          = "ST"
+	CasTicketTypeProxy            = "PT"
+	CasTicketTypeProxyGranting    = "PGT"
+	CasTicketRegistryDatabase     = "Database"
+	CasTicketRegistryMemory       = "Memory"
+	casServiceTicketTimeout       = 5 * time.Minute
+	casProxyGrantingTicketTimeout = 2 * time.Hour
+	// validated tickets are kept for the lifetime of the SSO session so that
+	// single logout can still reach the services they were issued for
+	casUsedTicketTimeout   = 30 * 24 * time.Hour
+	casTicketPurgeInterval = time.Minute
+)
+
+// CasTicket is a service ticket, proxy ticket or proxy granting ticket issued by the CAS server.
+type CasTicket struct {
+	Name        string `xorm:"varchar(200) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Type        string `xorm:"varchar(100)" json:"type"`
+	Application string `xorm:"varchar(100)" json:"application"`
+	Service     string `xorm:"varchar(1000)" json:"service"`
+	UserId      string `xorm:"varchar(100) index" json:"userId"`
+	SessionId   string `xorm:"varchar(100) index" json:"sessionId"`
+	Response    string `xorm:"mediumtext" json:"response"`
+	IsUsed      bool   `json:"isUsed"`
+	ExpireIn    int64  `xorm:"index" json:"expireIn"`
+}
+
+// CasTicketRegistry stores issued CAS tickets. Service and proxy tickets can be consumed only once,
+// proxy granting tickets stay valid until they expire.
+type CasTicketRegistry interface {
+	AddTicket(ticket *CasTicket) error
+	GetTicket(name string) (*CasTicket, error)
+	ConsumeTicket(name string) (*CasTicket, error)
+	GetTicketsBySessionId(sessionId string) ([]*CasTicket, error)
+	DeleteTicket(name string) error
+	DeleteExpiredTickets() error
+}
+
+var (
+	casTicketRegistry     CasTicketRegistry
+	casTicketRegistryOnce sync.Once
+
+	casTicketPurgeTime  time.Time
+	casTicketPurgeMutex sync.Mutex
+)
+
+func getCasTicketRegistry() CasTicketRegistry {
+	casTicketRegistryOnce.Do(func() {
+		if casTicketRegistry != nil {
+			return
+		}
+
+		if conf.GetConfigString("casTicketRegistry") == CasTicketRegistryDatabase {
+			casTicketRegistry = &DbCasTicketRegistry{}
+		} else {
+			casTicketRegistry = NewMemoryCasTicketRegistry()
+		}
+	})
+	return casTicketRegistry
+}
+
+// addCasTicket adds the ticket to the registry, the expired tickets are purged at most once per purge interval so
+// that the tickets that are never validated do not accumulate
+func addCasTicket(ticket *CasTicket) error {
+	registry := getCasTicketRegistry()
+
+	casTicketPurgeMutex.Lock()
+	isPurgeDue := time.Since(casTicketPurgeTime) >= casTicketPurgeInterval
+	if isPurgeDue {
+		casTicketPurgeTime = time.Now()
+	}
+	casTicketPurgeMutex.Unlock()
+
+	if isPurgeDue {
+		err := registry.DeleteExpiredTickets()
+		if err != nil {
+			return err
+		}
+	}
+
+	return registry.AddTicket(ticket)
+}
+
+// SetCasTicketRegistry replaces the registry used by the CAS server, it must be called before any ticket is issued.
+func SetCasTicketRegistry(registry CasTicketRegistry) {
+	casTicketRegistry = registry
+}
+
+func newCasTicket(ticketType string, name string, response *CasAuthenticationSuccess, service string, userId string, sessionId string) (*CasTicket, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := casServiceTicketTimeout
+	if ticketType == CasTicketTypeProxyGranting {
+		timeout = casProxyGrantingTicketTimeout
+	}
+
+	ticket := &CasTicket{
+		Name:        name,
+		CreatedTime: util.GetCurrentTime(),
+		Type:        ticketType,
+		Service:     service,
+		UserId:      userId,
+		SessionId:   sessionId,
+		Response:    string(data),
+		ExpireIn:    time.Now().Add(timeout).Unix(),
+	}
+	return ticket, nil
+}
+
+func (ticket *CasTicket) IsExpired() bool {
+	return time.Now().Unix() > ticket.ExpireIn
+}
+
+func (ticket *CasTicket) GetResponse() (*CasAuthenticationSuccess, error) {
+	var response CasAuthenticationSuccess
+	err := json.Unmarshal([]byte(ticket.Response), &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DbCasTicketRegistry keeps tickets in the database so that they survive restarts and
+// can be shared by multiple Casdoor instances behind a load balancer.
+type DbCasTicketRegistry struct{}
+
+func (r *DbCasTicketRegistry) AddTicket(ticket *CasTicket) error {
+	_, err := ormer.Engine.Insert(ticket)
+	return err
+}
+
+func (r *DbCasTicketRegistry) GetTicket(name string) (*CasTicket, error) {
+	ticket := CasTicket{Name: name}
+	existed, err := ormer.Engine.Get(&ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !existed || ticket.IsExpired() {
+		return nil, nil
+	}
+	return &ticket, nil
+}
+
+func (r *DbCasTicketRegistry) ConsumeTicket(name string) (*CasTicket, error) {
+	// the conditional update guarantees that only one instance can consume the ticket
+	now := time.Now()
+	affected, err := ormer.Engine.Where("name = ? and is_used = ? and expire_in >= ?", name, false, now.Unix()).
+		Cols("is_used", "expire_in").Update(&CasTicket{IsUsed: true, ExpireIn: now.Add(casUsedTicketTimeout).Unix()})
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+
+	return r.GetTicket(name)
+}
+
+func (r *DbCasTicketRegistry) GetTicketsBySessionId(sessionId string) ([]*CasTicket, error) {
+	tickets := []*CasTicket{}
+	err := ormer.Engine.Where("session_id = ?", sessionId).Find(&tickets)
+	if err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (r *DbCasTicketRegistry) DeleteTicket(name string) error {
+	_, err := ormer.Engine.ID(name).Delete(&CasTicket{})
+	return err
+}
+
+func (r *DbCasTicketRegistry) DeleteExpiredTickets() error {
+	_, err := ormer.Engine.Where("expire_in < ?", time.Now().Unix()).Delete(&CasTicket{})
+	return err
+}
+
+// MemoryCasTicketRegistry keeps tickets in process memory, it is only suitable for a single Casdoor instance.
+type MemoryCasTicketRegistry struct {
+	lock    sync.Mutex
+	tickets map[string]*CasTicket
+}
+
+func NewMemoryCasTicketRegistry() *MemoryCasTicketRegistry {
+	return &MemoryCasTicketRegistry{
+		tickets: map[string]*CasTicket{},
+	}
+}
+
+func (r *MemoryCasTicketRegistry) AddTicket(ticket *CasTicket) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	tmp := *ticket
+	r.tickets[ticket.Name] = &tmp
+	return nil
+}
+
+func (r *MemoryCasTicketRegistry) GetTicket(name string) (*CasTicket, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ticket, ok := r.tickets[name]
+	if !ok || ticket.IsExpired() {
+		return nil, nil
+	}
+
+	tmp := *ticket
+	return &tmp, nil
+}
+
+func (r *MemoryCasTicketRegistry) ConsumeTicket(name string) (*CasTicket, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ticket, ok := r.tickets[name]
+	if !ok || ticket.IsUsed || ticket.IsExpired() {
+		return nil, nil
+	}
+
+	ticket.IsUsed = true
+	ticket.ExpireIn = time.Now().Add(casUsedTicketTimeout).Unix()
+
+	tmp := *ticket
+	return &tmp, nil
+}
+
+func (r *MemoryCasTicketRegistry) GetTicketsBySessionId(sessionId string) ([]*CasTicket, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	tickets := []*CasTicket{}
+	for _, ticket := range r.tickets {
+		if ticket.SessionId == sessionId {
+			tmp := *ticket
+			tickets = append(tickets, &tmp)
+		}
+	}
+	return tickets, nil
+}
+
+func (r *MemoryCasTicketRegistry) DeleteTicket(name string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.tickets, name)
+	return nil
+}
+
+func (r *MemoryCasTicketRegistry) DeleteExpiredTickets() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for name, ticket := range r.tickets {
+		if ticket.IsExpired() {
+			delete(r.tickets, name)
+		}
+	}
+	return nil
+}
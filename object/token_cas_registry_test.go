@@ -0,0 +1,72 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCasTicketRegistry(t *testing.T) {
+	registry := NewMemoryCasTicketRegistry()
+	response := &CasAuthenticationSuccess{User: "alice"}
+
+	ticket, err := newCasTicket(CasTicketTypeService, "ST-1", response, "https://app.example.com", "built-in/alice", "session-1")
+	assert.Nil(t, err)
+	assert.Nil(t, registry.AddTicket(ticket))
+
+	consumed, err := registry.ConsumeTicket("ST-1")
+	assert.Nil(t, err)
+	assert.NotNil(t, consumed)
+	assert.True(t, consumed.IsUsed)
+
+	decoded, err := consumed.GetResponse()
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", decoded.User)
+
+	// a service ticket can be validated only once
+	consumed, err = registry.ConsumeTicket("ST-1")
+	assert.Nil(t, err)
+	assert.Nil(t, consumed)
+
+	tickets, err := registry.GetTicketsBySessionId("session-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tickets))
+
+	expired, err := newCasTicket(CasTicketTypeService, "ST-2", response, "https://app.example.com", "built-in/alice", "session-1")
+	assert.Nil(t, err)
+	expired.ExpireIn = time.Now().Add(-time.Minute).Unix()
+	assert.Nil(t, registry.AddTicket(expired))
+
+	consumed, err = registry.ConsumeTicket("ST-2")
+	assert.Nil(t, err)
+	assert.Nil(t, consumed)
+
+	assert.Nil(t, registry.DeleteExpiredTickets())
+	tickets, err = registry.GetTicketsBySessionId("session-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tickets))
+}
+
+func TestNewCasLogoutRequest(t *testing.T) {
+	request, err := NewCasLogoutRequest("alice", "ST-1")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(request, "<samlp:LogoutRequest"))
+	assert.Contains(t, request, "<samlp:SessionIndex>ST-1</samlp:SessionIndex>")
+	assert.Contains(t, request, "<saml:NameID>alice</saml:NameID>")
+}
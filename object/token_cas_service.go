@@ -0,0 +1,105 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type CasAttributeMapping struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+}
+
+// CasService is a CAS client registered in an application. The service pattern is a regular expression
+// which must match the whole service URL.
+type CasService struct {
+	Name              string                 `json:"name"`
+	ServicePattern    string                 `json:"servicePattern"`
+	AllowedAttributes []string               `json:"allowedAttributes"`
+	AttributeMappings []*CasAttributeMapping `json:"attributeMappings"`
+	AllowProxy        bool                   `json:"allowProxy"`
+	RequireMfa        bool                   `json:"requireMfa"`
+}
+
+func (casService *CasService) IsServiceMatched(service string) bool {
+	if casService.ServicePattern == "" {
+		return false
+	}
+
+	re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", casService.ServicePattern))
+	if err != nil {
+		return false
+	}
+	return re.MatchString(service)
+}
+
+// IsAttributeReleased reports whether the user attribute can be released to the service,
+// all attributes are released when no allowed attributes are configured.
+func (casService *CasService) IsAttributeReleased(name string) bool {
+	if len(casService.AllowedAttributes) == 0 {
+		return true
+	}
+
+	for _, attribute := range casService.AllowedAttributes {
+		if attribute == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (casService *CasService) GetAttributeAlias(name string) string {
+	for _, mapping := range casService.AttributeMappings {
+		if mapping.Name == name && mapping.Alias != "" {
+			return mapping.Alias
+		}
+	}
+	return name
+}
+
+func (application *Application) IsCasServiceRegistryEnabled() bool {
+	return len(application.CasServices) > 0
+}
+
+// GetCasService returns the first registered CAS service matching the service URL, nil if none matches
+func (application *Application) GetCasService(service string) *CasService {
+	for _, casService := range application.CasServices {
+		if casService.IsServiceMatched(service) {
+			return casService
+		}
+	}
+	return nil
+}
+
+// GetCasServiceByTicket returns the registered CAS service the ticket was issued for, the returned bool is false
+// when the application restricts CAS services and the ticket's service is no longer registered
+func GetCasServiceByTicket(ticket *CasTicket) (*CasService, bool, error) {
+	application, err := GetApplication(ticket.Application)
+	if err != nil {
+		return nil, false, err
+	}
+	if application == nil {
+		return nil, false, fmt.Errorf("the application: %s does not exist", ticket.Application)
+	}
+
+	if !application.IsCasServiceRegistryEnabled() {
+		return nil, true, nil
+	}
+
+	casService := application.GetCasService(ticket.Service)
+	return casService, casService != nil, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCasService(t *testing.T) {
+	application := &Application{
+		CasServices: []*CasService{
+			{
+				Name:              "wiki",
+				ServicePattern:    `https://wiki\.example\.com/.*`,
+				AllowedAttributes: []string{"email", "displayName"},
+				AttributeMappings: []*CasAttributeMapping{{Name: "email", Alias: "mail"}},
+			},
+		},
+	}
+
+	assert.True(t, application.IsCasServiceRegistryEnabled())
+
+	casService := application.GetCasService("https://wiki.example.com/login")
+	assert.NotNil(t, casService)
+	assert.Nil(t, application.GetCasService("https://evil.com/https://wiki.example.com/login"))
+
+	assert.True(t, casService.IsAttributeReleased("email"))
+	assert.False(t, casService.IsAttributeReleased("phone"))
+	assert.Equal(t, "mail", casService.GetAttributeAlias("email"))
+	assert.Equal(t, "displayName", casService.GetAttributeAlias("displayName"))
+}
@@ -381,6 +381,11 @@ func generateJwtToken(application *Application, user *User, provider string, non
 		refreshExpireTime = expireTime
 	}
 
+	user, hookClaims, err := runTokenHooks(application, user)
+	if err != nil {
+		return "", "", "", err
+	}
+
 	user = refineUser(user)
 
 	_, originBackend := getOriginFromHost(host)
@@ -469,6 +474,15 @@ func generateJwtToken(application *Application, user *User, provider string, non
 		return "", "", "", fmt.Errorf("unknown application TokenFormat: %s", application.TokenFormat)
 	}
 
+	err = addEventHookClaims(token, hookClaims)
+	if err != nil {
+		return "", "", "", err
+	}
+	err = addEventHookClaims(refreshToken, hookClaims)
+	if err != nil {
+		return "", "", "", err
+	}
+
 	cert, err := getCertByApplication(application)
 	if err != nil {
 		return "", "", "", err
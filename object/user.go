@@ -311,6 +311,30 @@ func GetPaginationGlobalUsers(offset, limit int, field, value, sortField, sortOr
 	return users, nil
 }
 
+func GetUserCountWithFilter(owner string, cond builder.Cond) (int64, error) {
+	return getFilterSession(owner, cond).Count(&User{})
+}
+
+func GetPaginationUsersWithFilter(owner string, offset, limit int, cond builder.Cond, sortField, sortOrder string) ([]*User, error) {
+	users := []*User{}
+	session := getFilterSession(owner, cond).Limit(limit, offset)
+	if sortField == "" {
+		sortField = "created_time"
+	}
+	if sortOrder == "descend" {
+		session = session.Desc(util.SnakeString(sortField))
+	} else {
+		session = session.Asc(util.SnakeString(sortField))
+	}
+
+	err := session.Find(&users)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func GetUserCount(owner, field, value string, groupName string) (int64, error) {
 	session := GetSession(owner, -1, -1, field, value, "", "")
 
@@ -760,6 +784,20 @@ func UpdateUser(id string, user *User, columns []string, isAdmin bool) (bool, er
 		return false, err
 	}
 
+	if affected != 0 {
+		writeBackUpdatedUser(oldUser, user, columns)
+		if name != user.Name {
+			renameScimProvisioningObject(owner, ScimProvisioningUser, name, user.Name)
+		}
+		provisionScimUser(oldUser, user)
+
+		if user.IsDeleted && !oldUser.IsDeleted {
+			addUserDomainEvent(DomainEventUserDeleted, user)
+		} else {
+			addUserDomainEvent(DomainEventUserUpdated, user)
+		}
+	}
+
 	return affected != 0, nil
 }
 
@@ -815,6 +853,15 @@ func UpdateUserForAllFields(id string, user *User) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		writeBackUpdatedUser(oldUser, user, ldapWriteBackColumns)
+		if name != user.Name {
+			renameScimProvisioningObject(owner, ScimProvisioningUser, name, user.Name)
+		}
+		provisionScimUser(oldUser, user)
+		addUserDomainEvent(DomainEventUserUpdated, user)
+	}
+
 	return affected != 0, nil
 }
 
@@ -863,7 +910,9 @@ func AddUser(user *User) (bool, error) {
 		user.Password = organization.DefaultPassword
 	}
 
+	plainPassword := ""
 	if user.PasswordType == "" || user.PasswordType == "plain" {
+		plainPassword = user.Password
 		user.UpdateUserPassword(organization)
 	}
 
@@ -916,6 +965,12 @@ func AddUser(user *User) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		writeBackNewUser(user, plainPassword)
+		provisionScimUser(nil, user)
+		addUserDomainEvent(DomainEventUserCreated, user)
+	}
+
 	return affected != 0, nil
 }
 
@@ -963,6 +1018,13 @@ func AddUsers(users []*User) (bool, error) {
 		}
 	}
 
+	if affected != 0 {
+		for _, user := range users {
+			provisionScimUser(nil, user)
+			addUserDomainEvent(DomainEventUserCreated, user)
+		}
+	}
+
 	return affected != 0, nil
 }
 
@@ -999,6 +1061,11 @@ func deleteUser(user *User) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		deprovisionScimUser(user)
+		addUserDomainEvent(DomainEventUserDeleted, user)
+	}
+
 	return affected != 0, nil
 }
 
@@ -43,6 +43,16 @@ type Webhook struct {
 	IsUserExtended bool      `json:"isUserExtended"`
 	SingleOrgOnly  bool      `json:"singleOrgOnly"`
 	IsEnabled      bool      `json:"isEnabled"`
+
+	MaxAttempts         int    `json:"maxAttempts"`
+	FailureThreshold    int    `json:"failureThreshold"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	DisabledReason      string `xorm:"varchar(1000)" json:"disabledReason"`
+
+	Secret                   string `xorm:"varchar(100)" json:"secret"`
+	PreviousSecret           string `xorm:"varchar(100)" json:"-"`
+	PreviousSecretExpireTime string `xorm:"varchar(100)" json:"previousSecretExpireTime"`
+	PayloadTemplate          string `xorm:"mediumtext" json:"payloadTemplate"`
 }
 
 func GetWebhookCount(owner, organization, field, value string) (int64, error) {
@@ -104,14 +114,71 @@ func GetWebhook(id string) (*Webhook, error) {
 	return getWebhook(owner, name)
 }
 
+func GetMaskedWebhook(webhook *Webhook, errs ...error) (*Webhook, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	if webhook == nil {
+		return nil, nil
+	}
+
+	if webhook.Secret != "" {
+		webhook.Secret = "***"
+	}
+	return webhook, nil
+}
+
+func GetMaskedWebhooks(webhooks []*Webhook, errs ...error) ([]*Webhook, error) {
+	if len(errs) > 0 && errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	var err error
+	for _, webhook := range webhooks {
+		webhook, err = GetMaskedWebhook(webhook)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return webhooks, nil
+}
+
 func UpdateWebhook(id string, webhook *Webhook) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
-	if w, err := getWebhook(owner, name); err != nil {
+	w, err := getWebhook(owner, name)
+	if err != nil {
 		return false, err
 	} else if w == nil {
 		return false, nil
 	}
 
+	if webhook.Secret == "***" {
+		webhook.Secret = w.Secret
+	}
+
+	err = checkWebhook(webhook)
+	if err != nil {
+		return false, err
+	}
+
+	// the failures are counted by the delivery worker, enabling the webhook again starts over
+	webhook.ConsecutiveFailures = w.ConsecutiveFailures
+	webhook.DisabledReason = w.DisabledReason
+	if webhook.IsEnabled && !w.IsEnabled {
+		webhook.ConsecutiveFailures = 0
+		webhook.DisabledReason = ""
+	}
+
+	webhook.PreviousSecret = w.PreviousSecret
+	webhook.PreviousSecretExpireTime = w.PreviousSecretExpireTime
+	if webhook.Secret != w.Secret {
+		// a secret changed by hand replaces the previous one immediately, unlike a rotation
+		webhook.PreviousSecret = ""
+		webhook.PreviousSecretExpireTime = ""
+	}
+
 	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(webhook)
 	if err != nil {
 		return false, err
@@ -121,6 +188,11 @@ func UpdateWebhook(id string, webhook *Webhook) (bool, error) {
 }
 
 func AddWebhook(webhook *Webhook) (bool, error) {
+	err := checkWebhook(webhook)
+	if err != nil {
+		return false, err
+	}
+
 	affected, err := ormer.Engine.Insert(webhook)
 	if err != nil {
 		return false, err
@@ -135,6 +207,13 @@ func DeleteWebhook(webhook *Webhook) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		err = deleteWebhookDeliveries(webhook)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	return affected != 0, nil
 }
 
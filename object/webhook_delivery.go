@@ -0,0 +1,388 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	WebhookDeliveryStatePending   = "Pending"
+	WebhookDeliveryStateSending   = "Sending"
+	WebhookDeliveryStateSucceeded = "Succeeded"
+	WebhookDeliveryStateFailed    = "Failed"
+	WebhookDeliveryStateDead      = "Dead"
+
+	webhookDefaultMaxAttempts      = 5
+	webhookDefaultFailureThreshold = 50
+	webhookDeliveryBatchSize       = 100
+	webhookMaxReplayCount          = 1000
+	webhookMaxResponseLength       = 1000
+	webhookTimeout                 = 30 * time.Second
+	// the webhooks are sent in parallel, the deliveries of a webhook are sent in order
+	webhookDeliveryWorkers = 10
+	// a claimed delivery is sent again after the lease when the instance stopped before writing the result back, it
+	// must be longer than webhookTimeout because a delivery is claimed right before it is sent
+	webhookDeliveryLease = 5 * time.Minute
+)
+
+// WebhookDelivery is a queued request of a webhook and the result of its last attempt. A failed delivery is retried
+// with exponential backoff until the max attempts of the webhook, then it becomes dead.
+type WebhookDelivery struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100) index" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Webhook       string `xorm:"varchar(100) index" json:"webhook"`
+	Action        string `xorm:"varchar(100)" json:"action"`
+	Body          string `xorm:"mediumtext" json:"body"`
+	ReplayOf      string `xorm:"varchar(100)" json:"replayOf"`
+	State         string `xorm:"varchar(100) index" json:"state"`
+	Attempts      int    `json:"attempts"`
+	NextRetryTime string `xorm:"varchar(100) index" json:"nextRetryTime"`
+	StatusCode    int    `json:"statusCode"`
+	Latency       int64  `json:"latency"`
+	Response      string `xorm:"mediumtext" json:"response"`
+	Error         string `xorm:"mediumtext" json:"error"`
+}
+
+var webhookDeliverySignal = make(chan struct{}, 1)
+
+func GetWebhookDeliveryCount(owner, webhook, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&WebhookDelivery{Webhook: webhook})
+}
+
+func GetPaginationWebhookDeliveries(owner, webhook string, offset, limit int, field, value, sortField, sortOrder string) ([]*WebhookDelivery, error) {
+	deliveries := []*WebhookDelivery{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&deliveries, &WebhookDelivery{Webhook: webhook})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (webhook *Webhook) getMaxAttempts() int {
+	if webhook.MaxAttempts > 0 {
+		return webhook.MaxAttempts
+	}
+	return webhookDefaultMaxAttempts
+}
+
+// getFailureThreshold returns the count of consecutive failed attempts that disables the webhook, a negative
+// threshold never disables it
+func (webhook *Webhook) getFailureThreshold() int {
+	if webhook.FailureThreshold != 0 {
+		return webhook.FailureThreshold
+	}
+	return webhookDefaultFailureThreshold
+}
+
+func addWebhookDelivery(webhook *Webhook, action string, body string, replayOf string) error {
+	delivery := &WebhookDelivery{
+		Owner:         webhook.Owner,
+		Name:          util.GenerateId(),
+		CreatedTime:   util.GetCurrentTime(),
+		UpdatedTime:   util.GetCurrentTime(),
+		Webhook:       webhook.GetId(),
+		Action:        action,
+		Body:          body,
+		ReplayOf:      replayOf,
+		State:         WebhookDeliveryStatePending,
+		NextRetryTime: util.GetCurrentTime(),
+	}
+
+	_, err := ormer.Engine.Insert(delivery)
+	return err
+}
+
+func updateWebhookDelivery(delivery *WebhookDelivery) error {
+	delivery.UpdatedTime = util.GetCurrentTime()
+	_, err := ormer.Engine.ID(core.PK{delivery.Owner, delivery.Name}).AllCols().Update(delivery)
+	return err
+}
+
+// claimWebhookDelivery marks the delivery as sending if it wasn't claimed since it was read, so that every delivery
+// is sent by a single instance. It returns false when another instance claimed it.
+func claimWebhookDelivery(delivery *WebhookDelivery) (bool, error) {
+	nextRetryTime := delivery.NextRetryTime
+	delivery.State = WebhookDeliveryStateSending
+	delivery.UpdatedTime = util.GetCurrentTime()
+	delivery.NextRetryTime = time.Now().Add(webhookDeliveryLease).Format(time.RFC3339)
+
+	affected, err := ormer.Engine.ID(core.PK{delivery.Owner, delivery.Name}).Where("next_retry_time = ?", nextRetryTime).
+		In("state", WebhookDeliveryStatePending, WebhookDeliveryStateFailed, WebhookDeliveryStateSending).
+		Cols("state", "updated_time", "next_retry_time").Update(delivery)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func deleteWebhookDeliveries(webhook *Webhook) error {
+	_, err := ormer.Engine.Where("webhook = ?", webhook.GetId()).Delete(&WebhookDelivery{})
+	return err
+}
+
+func signalWebhookDeliveryWorker() {
+	select {
+	case webhookDeliverySignal <- struct{}{}:
+	default:
+	}
+}
+
+// ReplayWebhookDeliveries queues the deliveries again as new deliveries, either the delivery with the name or the
+// deliveries created in the time range, optionally only those in the state
+func ReplayWebhookDeliveries(webhook *Webhook, name string, startTime string, endTime string, state string) (int, error) {
+	deliveries := []*WebhookDelivery{}
+	session := ormer.Engine.Where("owner = ? and webhook = ?", webhook.Owner, webhook.GetId())
+	if name != "" {
+		session = session.And("name = ?", name)
+	} else {
+		if startTime == "" || endTime == "" {
+			return 0, fmt.Errorf("the start time and end time of the deliveries to replay should not be empty")
+		}
+		session = session.And("created_time >= ? and created_time <= ?", startTime, endTime)
+		if state != "" {
+			session = session.And("state = ?", state)
+		}
+	}
+
+	err := session.Asc("created_time").Limit(webhookMaxReplayCount + 1).Find(&deliveries)
+	if err != nil {
+		return 0, err
+	}
+	if len(deliveries) > webhookMaxReplayCount {
+		return 0, fmt.Errorf("more than %d deliveries are in the range, please narrow the time range", webhookMaxReplayCount)
+	}
+
+	for _, delivery := range deliveries {
+		err = addWebhookDelivery(webhook, delivery.Action, delivery.Body, delivery.Name)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(deliveries) != 0 {
+		signalWebhookDeliveryWorker()
+	}
+	return len(deliveries), nil
+}
+
+func getWebhookRetryDelay(attempts int) time.Duration {
+	seconds := 30 << min(attempts-1, 7)
+	return time.Duration(min(seconds, 3600)) * time.Second
+}
+
+// processWebhookDelivery sends the delivery and counts the consecutive failures of the webhook, the webhook is
+// disabled when the failures reach its threshold
+func processWebhookDelivery(webhook *Webhook, delivery *WebhookDelivery) error {
+	startTime := time.Now()
+	statusCode, respBody, err := sendWebhook(webhook, delivery.Body)
+	if len(respBody) > webhookMaxResponseLength {
+		respBody = respBody[0:webhookMaxResponseLength]
+	}
+
+	delivery.Attempts++
+	delivery.StatusCode = statusCode
+	delivery.Latency = time.Since(startTime).Milliseconds()
+	delivery.Response = respBody
+	delivery.Error = ""
+	if err == nil && (statusCode < 200 || statusCode >= 300) {
+		err = fmt.Errorf("the webhook returned status code: %d", statusCode)
+	}
+
+	failures := 0
+	if err == nil {
+		delivery.State = WebhookDeliveryStateSucceeded
+		delivery.NextRetryTime = ""
+	} else {
+		failures = webhook.ConsecutiveFailures + 1
+		delivery.Error = err.Error()
+		delivery.State = WebhookDeliveryStateFailed
+		delivery.NextRetryTime = time.Now().Add(getWebhookRetryDelay(delivery.Attempts)).Format(time.RFC3339)
+		if delivery.Attempts >= webhook.getMaxAttempts() {
+			delivery.State = WebhookDeliveryStateDead
+			delivery.NextRetryTime = ""
+		}
+	}
+
+	err = updateWebhookDelivery(delivery)
+	if err != nil {
+		return err
+	}
+
+	if failures == webhook.ConsecutiveFailures {
+		return nil
+	}
+
+	webhook.ConsecutiveFailures = failures
+	columns := []string{"consecutive_failures"}
+	threshold := webhook.getFailureThreshold()
+	if threshold > 0 && failures >= threshold {
+		webhook.IsEnabled = false
+		webhook.DisabledReason = fmt.Sprintf("disabled after %d consecutive failed attempts, the last error: %s", failures, delivery.Error)
+		columns = append(columns, "is_enabled", "disabled_reason")
+	}
+
+	_, err = ormer.Engine.ID(core.PK{webhook.Owner, webhook.Name}).Cols(columns...).Update(webhook)
+	return err
+}
+
+// processWebhookDeliveriesOfWebhook sends the deliveries of a webhook in order, each delivery is claimed right before
+// it is sent so that its lease covers a single request
+func processWebhookDeliveriesOfWebhook(id string, deliveries []*WebhookDelivery) error {
+	webhook, err := GetWebhook(id)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		claimed, err := claimWebhookDelivery(delivery)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		if webhook == nil || !webhook.IsEnabled {
+			delivery.State = WebhookDeliveryStateDead
+			delivery.NextRetryTime = ""
+			delivery.Error = fmt.Sprintf("the webhook: %s does not exist or is disabled", id)
+			err = updateWebhookDelivery(delivery)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = processWebhookDelivery(webhook, delivery)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	webhookDeliveryWorkerSlots = make(chan struct{}, webhookDeliveryWorkers)
+	sendingWebhookIds          = map[string]bool{}
+	sendingWebhookIdsMutex     sync.Mutex
+)
+
+func getSendingWebhookIds() []string {
+	sendingWebhookIdsMutex.Lock()
+	defer sendingWebhookIdsMutex.Unlock()
+
+	ids := []string{}
+	for id := range sendingWebhookIds {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func setWebhookSending(id string, sending bool) {
+	sendingWebhookIdsMutex.Lock()
+	defer sendingWebhookIdsMutex.Unlock()
+
+	if sending {
+		sendingWebhookIds[id] = true
+	} else {
+		delete(sendingWebhookIds, id)
+	}
+}
+
+// processWebhookDeliveries starts sending the due deliveries without waiting for them, a slow webhook only delays its
+// own deliveries because every webhook is sent by its own worker of a bounded pool, and the webhooks still being sent
+// are skipped until their worker returns
+func processWebhookDeliveries() error {
+	query := ormer.Engine.Where("next_retry_time != ? and next_retry_time <= ?", "", util.GetCurrentTime()).
+		In("state", WebhookDeliveryStatePending, WebhookDeliveryStateFailed, WebhookDeliveryStateSending)
+	if sendingIds := getSendingWebhookIds(); len(sendingIds) != 0 {
+		query = query.NotIn("webhook", sendingIds)
+	}
+
+	deliveries := []*WebhookDelivery{}
+	err := query.Asc("next_retry_time").Limit(webhookDeliveryBatchSize).Find(&deliveries)
+	if err != nil {
+		return err
+	}
+
+	webhookIds := []string{}
+	webhookDeliveries := map[string][]*WebhookDelivery{}
+	for _, delivery := range deliveries {
+		if _, ok := webhookDeliveries[delivery.Webhook]; !ok {
+			webhookIds = append(webhookIds, delivery.Webhook)
+		}
+		webhookDeliveries[delivery.Webhook] = append(webhookDeliveries[delivery.Webhook], delivery)
+	}
+
+	for _, id := range webhookIds {
+		select {
+		case webhookDeliveryWorkerSlots <- struct{}{}:
+		default:
+			// all the workers are busy, the remaining deliveries are picked up by a later poll
+			return nil
+		}
+
+		setWebhookSending(id, true)
+		go func(id string) {
+			defer func() {
+				if r := recover(); r != nil {
+					logs.Error(fmt.Sprintf("webhook delivery of %s panicked: %v", id, r))
+				}
+				setWebhookSending(id, false)
+				<-webhookDeliveryWorkerSlots
+				signalWebhookDeliveryWorker()
+			}()
+
+			err := processWebhookDeliveriesOfWebhook(id, webhookDeliveries[id])
+			if err != nil {
+				logs.Warning(fmt.Sprintf("webhook delivery of %s failed, error: %s", id, err))
+			}
+		}(id)
+	}
+	return nil
+}
+
+// RunWebhookDeliveryWorker sends the queued deliveries when a record triggers webhooks and retries the failed
+// deliveries periodically
+func RunWebhookDeliveryWorker() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-webhookDeliverySignal:
+		}
+
+		err := processWebhookDeliveries()
+		if err != nil {
+			logs.Warning(fmt.Sprintf("webhook delivery failed, error: %s", err))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWebhookRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, getWebhookRetryDelay(1))
+	assert.Equal(t, 2*time.Minute, getWebhookRetryDelay(3))
+	assert.Equal(t, time.Hour, getWebhookRetryDelay(20))
+
+	webhook := &Webhook{}
+	assert.Equal(t, webhookDefaultMaxAttempts, webhook.getMaxAttempts())
+	webhook.FailureThreshold = -1
+	assert.Equal(t, -1, webhook.getFailureThreshold())
+}
+
+func TestSendWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"action":"signup"}`, string(body))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		signatures := strings.Split(r.Header.Get(WebhookSignatureHeader), ",")
+		assert.Equal(t, []string{getWebhookSignature("new", timestamp, string(body)), getWebhookSignature("old", timestamp, string(body))}, signatures)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("queued"))
+	}))
+	defer server.Close()
+
+	webhook := &Webhook{Url: server.URL, Method: "POST", ContentType: "application/json", Headers: []*Header{{Name: "X-Token", Value: "secret"}}}
+	webhook.Secret = "new"
+	webhook.PreviousSecret = "old"
+	webhook.PreviousSecretExpireTime = time.Now().Add(time.Hour).Format(time.RFC3339)
+	statusCode, respBody, err := sendWebhook(webhook, `{"action":"signup"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, statusCode)
+	assert.Equal(t, "queued", respBody)
+}
+
+func TestRenderWebhookTemplate(t *testing.T) {
+	payload := `{"action":"signup","user":"alice","extendedUser":{"email":"alice@example.com"}}`
+	body, err := renderWebhookTemplate(`{"event": {{json (upper .action)}}, "email": {{json .extendedUser.email}}, "phone": {{json (default "n/a" .extendedUser.phone)}}}`, payload)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"event": "SIGNUP", "email": "alice@example.com", "phone": "n/a"}`, body)
+
+	assert.NotNil(t, checkWebhook(&Webhook{PayloadTemplate: "{{.action"}))
+}
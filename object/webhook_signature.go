@@ -0,0 +1,143 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// A webhook with a secret signs each request: the timestamp header is the Unix time of the attempt and the signature
+// header is "v1=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>". While a rotated secret is in its grace
+// period, the signature of the previous secret is sent too, separated by a comma. A receiver verifies one of the
+// signatures and rejects the timestamps that are too old to prevent replays.
+
+const (
+	WebhookTimestampHeader = "X-Casdoor-Timestamp"
+	WebhookSignatureHeader = "X-Casdoor-Signature"
+
+	webhookSecretGracePeriod = 24 * time.Hour
+)
+
+func getWebhookSignature(secret string, timestamp string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (webhook *Webhook) isPreviousSecretValid() bool {
+	if webhook.PreviousSecret == "" {
+		return false
+	}
+
+	expireTime, err := time.Parse(time.RFC3339, webhook.PreviousSecretExpireTime)
+	return err == nil && time.Now().Before(expireTime)
+}
+
+func (webhook *Webhook) signRequest(req *http.Request, body string) {
+	if webhook.Secret == "" {
+		return
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signatures := []string{getWebhookSignature(webhook.Secret, timestamp, body)}
+	if webhook.isPreviousSecretValid() {
+		signatures = append(signatures, getWebhookSignature(webhook.PreviousSecret, timestamp, body))
+	}
+
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, strings.Join(signatures, ","))
+}
+
+// RotateWebhookSecret generates a new secret, the requests are signed with the previous secret too until the grace
+// period ends so that the receivers can switch to the new secret
+func RotateWebhookSecret(webhook *Webhook) (string, error) {
+	if webhook.Secret != "" {
+		webhook.PreviousSecret = webhook.Secret
+		webhook.PreviousSecretExpireTime = time.Now().Add(webhookSecretGracePeriod).Format(time.RFC3339)
+	}
+	webhook.Secret = util.GenerateClientSecret()
+
+	_, err := ormer.Engine.ID(core.PK{webhook.Owner, webhook.Name}).Cols("secret", "previous_secret", "previous_secret_expire_time").Update(webhook)
+	if err != nil {
+		return "", err
+	}
+	return webhook.Secret, nil
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"default": func(defaultValue interface{}, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return defaultValue
+		}
+		return v
+	},
+}
+
+func parseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(webhookTemplateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// renderWebhookTemplate executes the payload template of the webhook on the default payload, the template accesses
+// the fields by their JSON names, e.g. {"event": {{json .action}}, "email": {{json .extendedUser.email}}}
+func renderWebhookTemplate(text string, payload string) (string, error) {
+	tmpl, err := parseWebhookTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var data map[string]interface{}
+	err = json.Unmarshal([]byte(payload), &data)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", fmt.Errorf("the payload template of the webhook failed: %s", err.Error())
+	}
+	return buf.String(), nil
+}
+
+func checkWebhook(webhook *Webhook) error {
+	if webhook.PayloadTemplate == "" {
+		return nil
+	}
+
+	_, err := parseWebhookTemplate(webhook.PayloadTemplate)
+	if err != nil {
+		return fmt.Errorf("the payload template of the webhook is invalid: %s", err.Error())
+	}
+	return nil
+}
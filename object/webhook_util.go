@@ -24,10 +24,17 @@ import (
 	"github.com/casvisor/casvisor-go-sdk/casvisorsdk"
 )
 
-func sendWebhook(webhook *Webhook, record *casvisorsdk.Record, extendedUser *User) (int, string, error) {
-	client := &http.Client{}
+// getWebhookBody returns the record with the extended user, rendered by the payload template of the webhook if any
+func getWebhookBody(webhook *Webhook, record *casvisorsdk.Record, extendedUser *User) (string, error) {
+	body := getWebhookPayload(webhook, record, extendedUser)
+	if webhook.PayloadTemplate == "" {
+		return body, nil
+	}
+	return renderWebhookTemplate(webhook.PayloadTemplate, body)
+}
+
+func getWebhookPayload(webhook *Webhook, record *casvisorsdk.Record, extendedUser *User) string {
 	userMap := make(map[string]interface{})
-	var body io.Reader
 
 	if webhook.TokenFields != nil && len(webhook.TokenFields) > 0 && extendedUser != nil {
 		userValue := reflect.ValueOf(extendedUser).Elem()
@@ -50,7 +57,7 @@ func sendWebhook(webhook *Webhook, record *casvisorsdk.Record, extendedUser *Use
 			ExtendedUser: userMap,
 		}
 
-		body = strings.NewReader(util.StructToJson(recordEx))
+		return util.StructToJson(recordEx)
 	} else {
 		type RecordEx struct {
 			casvisorsdk.Record
@@ -61,10 +68,13 @@ func sendWebhook(webhook *Webhook, record *casvisorsdk.Record, extendedUser *Use
 			ExtendedUser: extendedUser,
 		}
 
-		body = strings.NewReader(util.StructToJson(recordEx))
+		return util.StructToJson(recordEx)
 	}
+}
 
-	req, err := http.NewRequest(webhook.Method, webhook.Url, body)
+func sendWebhook(webhook *Webhook, body string) (int, string, error) {
+	client := &http.Client{Timeout: webhookTimeout}
+	req, err := http.NewRequest(webhook.Method, webhook.Url, strings.NewReader(body))
 	if err != nil {
 		return 0, "", err
 	}
@@ -74,6 +84,7 @@ func sendWebhook(webhook *Webhook, record *casvisorsdk.Record, extendedUser *Use
 	for _, header := range webhook.Headers {
 		req.Header.Set(header.Name, header.Value)
 	}
+	webhook.signRequest(req, body)
 
 	resp, err := client.Do(req)
 	if err != nil {
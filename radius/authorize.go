@@ -0,0 +1,217 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+	"layeh.com/radius/rfc2868"
+)
+
+// RFC 3580: Tunnel-Type VLAN
+const tunnelTypeVlan rfc2868.TunnelType = 13
+
+// radiusClientCacheTtl is long enough for the handler of a packet to reuse the client looked up for its secret
+const radiusClientCacheTtl = 2 * time.Second
+
+type radiusClientCacheEntry struct {
+	client     *object.RadiusClient
+	expireTime time.Time
+}
+
+var (
+	radiusClientCache          = map[string]*radiusClientCacheEntry{}
+	radiusClientCachePruneTime time.Time
+	radiusClientCacheMutex     sync.Mutex
+)
+
+// getRadiusClient returns the registered NAS client of the IP, the client is looked up once per packet
+// as the secret source and the handler share the lookup
+func getRadiusClient(ip string) (*object.RadiusClient, error) {
+	radiusClientCacheMutex.Lock()
+	entry, ok := radiusClientCache[ip]
+	radiusClientCacheMutex.Unlock()
+	if ok && time.Now().Before(entry.expireTime) {
+		return entry.client, nil
+	}
+
+	client, err := object.GetRadiusClientByIp(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	radiusClientCacheMutex.Lock()
+	defer radiusClientCacheMutex.Unlock()
+	if time.Since(radiusClientCachePruneTime) > radiusClientCacheTtl {
+		for cachedIp, cachedEntry := range radiusClientCache {
+			if time.Now().After(cachedEntry.expireTime) {
+				delete(radiusClientCache, cachedIp)
+			}
+		}
+		radiusClientCachePruneTime = time.Now()
+	}
+	radiusClientCache[ip] = &radiusClientCacheEntry{client: client, expireTime: time.Now().Add(radiusClientCacheTtl)}
+	return client, nil
+}
+
+// clientSecretSource looks up the shared secret of the registered NAS client sending the packet, and falls back
+// to radiusSecret for the ones not registered, which are dropped when it is empty.
+type clientSecretSource struct{}
+
+func (clientSecretSource) RADIUSSecret(ctx context.Context, remoteAddr net.Addr) ([]byte, error) {
+	client, err := getRadiusClient(getRemoteIp(remoteAddr))
+	if err != nil {
+		return nil, err
+	}
+
+	if client != nil {
+		return []byte(client.Secret), nil
+	}
+	return []byte(conf.GetConfigString("radiusSecret")), nil
+}
+
+func getRemoteIp(addr net.Addr) string {
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		return udpAddr.IP.String()
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// getAccessAccept authorizes an authenticated user for the NAS client of the request, the Access-Accept carries
+// the reply attributes of the matched policy.
+func getAccessAccept(r *radius.Request, user *object.User) (*radius.Packet, error) {
+	client, err := getRadiusClient(getRemoteIp(r.RemoteAddr))
+	if err != nil {
+		return nil, err
+	}
+	if client != nil && !client.IsOrganizationAllowed(user.Owner) {
+		return nil, fmt.Errorf("the organization: %s is not allowed for the RADIUS client: %s", user.Owner, client.GetId())
+	}
+
+	policy, err := object.GetRadiusPolicyByUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	response := r.Response(radius.CodeAccessAccept)
+	if policy != nil {
+		for _, attribute := range policy.Attributes {
+			err = addReplyAttribute(response, attribute)
+			if err != nil {
+				return nil, fmt.Errorf("invalid attribute %s in the RADIUS policy: %s, err = %v", attribute.Name, policy.GetId(), err)
+			}
+		}
+	}
+	return response, nil
+}
+
+func writeAccessAccept(w radius.ResponseWriter, r *radius.Request, user *object.User) {
+	response, err := getAccessAccept(r, user)
+	if err != nil {
+		log.Printf("writeAccessAccept() rejected user: %s, err = %v", user.GetId(), err)
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	w.Write(response)
+}
+
+func addReplyAttribute(p *radius.Packet, attribute *object.RadiusReplyAttribute) error {
+	switch attribute.Name {
+	case "Tunnel-Private-Group-ID":
+		err := rfc2868.TunnelType_Add(p, 0, tunnelTypeVlan)
+		if err != nil {
+			return err
+		}
+		err = rfc2868.TunnelMediumType_Add(p, 0, rfc2868.TunnelMediumType_Value_IEEE802)
+		if err != nil {
+			return err
+		}
+		return rfc2868.TunnelPrivateGroupID_AddString(p, 0, attribute.Value)
+	case "Filter-Id":
+		return rfc2865.FilterID_AddString(p, attribute.Value)
+	case "Class":
+		return rfc2865.Class_AddString(p, attribute.Value)
+	case "Reply-Message":
+		return rfc2865.ReplyMessage_AddString(p, attribute.Value)
+	case "Session-Timeout":
+		value, err := strconv.ParseUint(attribute.Value, 10, 32)
+		if err != nil {
+			return err
+		}
+		return rfc2865.SessionTimeout_Add(p, rfc2865.SessionTimeout(value))
+	case "Idle-Timeout":
+		value, err := strconv.ParseUint(attribute.Value, 10, 32)
+		if err != nil {
+			return err
+		}
+		return rfc2865.IdleTimeout_Add(p, rfc2865.IdleTimeout(value))
+	case "Framed-IP-Address":
+		ip := net.ParseIP(attribute.Value)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address: %s", attribute.Value)
+		}
+		return rfc2865.FramedIPAddress_Add(p, ip)
+	case "Vendor-Specific":
+		return addVendorSpecificAttribute(p, attribute)
+	default:
+		return fmt.Errorf("unsupported attribute")
+	}
+}
+
+// addVendorSpecificAttribute adds a VSA, the value is sent as hex bytes when prefixed with "0x" and as a string otherwise.
+func addVendorSpecificAttribute(p *radius.Packet, attribute *object.RadiusReplyAttribute) error {
+	if attribute.VendorId <= 0 || attribute.VendorType <= 0 || attribute.VendorType > 255 {
+		return fmt.Errorf("invalid vendor id: %d or vendor type: %d", attribute.VendorId, attribute.VendorType)
+	}
+
+	value := []byte(attribute.Value)
+	if strings.HasPrefix(attribute.Value, "0x") {
+		var err error
+		value, err = hex.DecodeString(strings.TrimPrefix(attribute.Value, "0x"))
+		if err != nil {
+			return err
+		}
+	}
+	if len(value) > 247 {
+		return fmt.Errorf("the value is too long")
+	}
+
+	vendorAttribute := append([]byte{byte(attribute.VendorType), byte(2 + len(value))}, value...)
+	vsa, err := radius.NewVendorSpecific(uint32(attribute.VendorId), vendorAttribute)
+	if err != nil {
+		return err
+	}
+
+	p.Add(rfc2865.VendorSpecific_Type, vsa)
+	return nil
+}
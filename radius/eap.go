@@ -0,0 +1,475 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+	"layeh.com/radius/rfc2869"
+	"layeh.com/radius/vendors/microsoft"
+)
+
+const (
+	eapCodeRequest  = 1
+	eapCodeResponse = 2
+	eapCodeSuccess  = 3
+	eapCodeFailure  = 4
+)
+
+const (
+	eapTypeIdentity = 1
+	eapTypeNak      = 3
+	eapTypeTtls     = 21
+	eapTypePeap     = 25
+	eapTypeMschapv2 = 26
+	eapTypeTlv      = 33
+)
+
+const (
+	eapTlsFlagLength = 0x80
+	eapTlsFlagMore   = 0x40
+	eapTlsFlagStart  = 0x20
+)
+
+const (
+	EapMethodPeap = "PEAP"
+	EapMethodTtls = "TTLS"
+)
+
+const (
+	eapSessionExpiredTime = time.Second * 120
+	eapMaxFragmentSize    = 1000
+	eapMaxMessageSize     = 64 * 1024
+)
+
+type eapPacket struct {
+	Code       byte
+	Identifier byte
+	Type       byte
+	Data       []byte
+}
+
+func parseEapPacket(b []byte) (*eapPacket, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("EAP packet is too short")
+	}
+
+	length := int(binary.BigEndian.Uint16(b[2:4]))
+	if length < 4 || length > len(b) {
+		return nil, fmt.Errorf("invalid EAP packet length: %d", length)
+	}
+
+	packet := &eapPacket{
+		Code:       b[0],
+		Identifier: b[1],
+	}
+	if packet.Code == eapCodeRequest || packet.Code == eapCodeResponse {
+		if length < 5 {
+			return nil, fmt.Errorf("EAP packet has no type")
+		}
+		packet.Type = b[4]
+		packet.Data = b[5:length]
+	}
+	return packet, nil
+}
+
+func (p *eapPacket) encode() []byte {
+	length := 4
+	if p.Code == eapCodeRequest || p.Code == eapCodeResponse {
+		length += 1 + len(p.Data)
+	}
+
+	b := make([]byte, length)
+	b[0] = p.Code
+	b[1] = p.Identifier
+	binary.BigEndian.PutUint16(b[2:4], uint16(length))
+	if length > 4 {
+		b[4] = p.Type
+		copy(b[5:], p.Data)
+	}
+	return b
+}
+
+// eapTlsData builds the payload of an EAP-TLS based request (PEAP and EAP-TTLS
+// share the same framing), splitting large TLS records into fragments.
+func eapTlsData(flags byte, data []byte, totalLength int) []byte {
+	if flags&eapTlsFlagLength != 0 {
+		b := make([]byte, 5+len(data))
+		b[0] = flags
+		binary.BigEndian.PutUint32(b[1:5], uint32(totalLength))
+		copy(b[5:], data)
+		return b
+	}
+	return append([]byte{flags}, data...)
+}
+
+func parseEapTlsData(data []byte) (byte, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, fmt.Errorf("EAP-TLS data is empty")
+	}
+
+	flags := data[0]
+	data = data[1:]
+	if flags&eapTlsFlagLength != 0 {
+		if len(data) < 4 {
+			return 0, nil, fmt.Errorf("EAP-TLS length field is truncated")
+		}
+		data = data[4:]
+	}
+	return flags, data, nil
+}
+
+func getEapMethodType(method string) byte {
+	if strings.EqualFold(method, EapMethodTtls) {
+		return eapTypeTtls
+	}
+	return eapTypePeap
+}
+
+// verifyMessageAuthenticator checks the Message-Authenticator attribute which
+// RFC 3579 requires in every Access-Request carrying an EAP-Message.
+func verifyMessageAuthenticator(p *radius.Packet) bool {
+	mac, err := rfc2869.MessageAuthenticator_Lookup(p)
+	if err != nil || len(mac) != md5.Size {
+		return false
+	}
+
+	packet := *p
+	packet.Attributes = append(radius.Attributes(nil), p.Attributes...)
+	err = rfc2869.MessageAuthenticator_Set(&packet, make([]byte, md5.Size))
+	if err != nil {
+		return false
+	}
+
+	b, err := packet.MarshalBinary()
+	if err != nil {
+		return false
+	}
+
+	hash := hmac.New(md5.New, p.Secret)
+	hash.Write(b)
+	return hmac.Equal(mac, hash.Sum(nil))
+}
+
+func signMessageAuthenticator(p *radius.Packet) error {
+	err := rfc2869.MessageAuthenticator_Set(p, make([]byte, md5.Size))
+	if err != nil {
+		return err
+	}
+
+	// For replies the HMAC is computed over the packet carrying the Request
+	// Authenticator, which MarshalBinary keeps as-is.
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	hash := hmac.New(md5.New, p.Secret)
+	hash.Write(b)
+	return rfc2869.MessageAuthenticator_Set(p, hash.Sum(nil))
+}
+
+type eapSessionStore struct {
+	sync.Mutex
+	sessions map[string]*eapSession
+}
+
+var eapSessions = &eapSessionStore{sessions: map[string]*eapSession{}}
+
+func (s *eapSessionStore) get(state string) *eapSession {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	for key, session := range s.sessions {
+		if session.expiredAt.Before(now) {
+			session.close()
+			delete(s.sessions, key)
+		}
+	}
+	session, ok := s.sessions[state]
+	if ok {
+		session.expiredAt = now.Add(eapSessionExpiredTime)
+	}
+	return session
+}
+
+func (s *eapSessionStore) add(session *eapSession) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.sessions[session.state] = session
+}
+
+func (s *eapSessionStore) remove(session *eapSession) {
+	s.Lock()
+	defer s.Unlock()
+
+	session.close()
+	delete(s.sessions, session.state)
+}
+
+func handleEapAccessRequest(w radius.ResponseWriter, r *radius.Request, eapMessage []byte) {
+	if !verifyMessageAuthenticator(r.Packet) {
+		log.Printf("handleEapAccessRequest() dropped a packet with an invalid Message-Authenticator from %v", r.RemoteAddr)
+		return
+	}
+
+	eap, err := parseEapPacket(eapMessage)
+	if err != nil || eap.Code != eapCodeResponse {
+		log.Printf("handleEapAccessRequest() got an invalid EAP message, err = %v", err)
+		writeEapResponse(w, r, radius.CodeAccessReject, &eapPacket{Code: eapCodeFailure}, nil)
+		return
+	}
+
+	state := rfc2865.State_GetString(r.Packet)
+	session := eapSessions.get(state)
+	if session == nil {
+		if eap.Type != eapTypeIdentity {
+			writeEapResponse(w, r, radius.CodeAccessReject, &eapPacket{Code: eapCodeFailure, Identifier: eap.Identifier}, nil)
+			return
+		}
+
+		session = newEapSession(string(eap.Data), getRadiusOrganization(r))
+		eapSessions.add(session)
+	}
+
+	session.Lock()
+	defer session.Unlock()
+
+	reply, err := session.handle(eap)
+	if err != nil {
+		log.Printf("handleEapAccessRequest() failed for identity %s, err = %v", session.identity, err)
+		eapSessions.remove(session)
+		writeEapResponse(w, r, radius.CodeAccessReject, &eapPacket{Code: eapCodeFailure, Identifier: eap.Identifier}, nil)
+		return
+	}
+
+	if reply.Code == eapCodeSuccess {
+		eapSessions.remove(session)
+		writeEapResponse(w, r, radius.CodeAccessAccept, reply, session)
+		return
+	}
+
+	writeEapResponse(w, r, radius.CodeAccessChallenge, reply, session)
+}
+
+func writeEapResponse(w radius.ResponseWriter, r *radius.Request, code radius.Code, eap *eapPacket, session *eapSession) {
+	var response *radius.Packet
+	var err error
+	switch code {
+	case radius.CodeAccessChallenge:
+		response = r.Response(code)
+		err = rfc2865.State_SetString(response, session.state)
+	case radius.CodeAccessAccept:
+		response, err = getAccessAccept(r, session.user)
+		if err == nil {
+			err = addEapAcceptAttributes(response, session)
+		}
+	default:
+		response = r.Response(code)
+	}
+	if err != nil {
+		log.Printf("writeEapResponse() failed, err = %v", err)
+		response = r.Response(radius.CodeAccessReject)
+		eap = &eapPacket{Code: eapCodeFailure, Identifier: eap.Identifier}
+	}
+
+	err = rfc2869.EAPMessage_Set(response, eap.encode())
+	if err != nil {
+		log.Printf("writeEapResponse() failed, err = %v", err)
+		return
+	}
+
+	err = signMessageAuthenticator(response)
+	if err != nil {
+		log.Printf("writeEapResponse() failed, err = %v", err)
+		return
+	}
+
+	w.Write(response)
+}
+
+func addEapAcceptAttributes(response *radius.Packet, session *eapSession) error {
+	if len(session.msk) < 64 {
+		return fmt.Errorf("the EAP session has no keying material")
+	}
+
+	err := rfc2865.UserName_SetString(response, session.user.Name)
+	if err != nil {
+		return err
+	}
+
+	// RFC 2548: the MSK is split into MS-MPPE-Recv-Key and MS-MPPE-Send-Key
+	// from the point of view of the authenticator.
+	err = microsoft.MSMPPERecvKey_Add(response, session.msk[:32])
+	if err != nil {
+		return err
+	}
+	return microsoft.MSMPPESendKey_Add(response, session.msk[32:64])
+}
+
+func getRadiusOrganization(r *radius.Request) string {
+	organization := rfc2865.Class_GetString(r.Packet)
+	if organization == "" {
+		organization = conf.GetConfigString("radiusDefaultOrganization")
+		if organization == "" {
+			organization = "built-in"
+		}
+	}
+	return organization
+}
+
+type eapSession struct {
+	sync.Mutex
+	state        string
+	identity     string
+	organization string
+	method       byte
+	identifier   byte
+	expiredAt    time.Time
+
+	tunnel    *eapTlsTunnel
+	fragments []byte
+	pending   []byte
+	total     int
+
+	user *object.User
+	msk  []byte
+}
+
+func newEapSession(identity string, organization string) *eapSession {
+	return &eapSession{
+		state:        util.GenerateId(),
+		identity:     identity,
+		organization: organization,
+		method:       getEapMethodType(conf.GetConfigString("radiusEapMethod")),
+		expiredAt:    time.Now().Add(eapSessionExpiredTime),
+	}
+}
+
+func (s *eapSession) close() {
+	if s.tunnel != nil {
+		s.tunnel.close()
+	}
+}
+
+func (s *eapSession) request(eapType byte, data []byte) *eapPacket {
+	s.identifier++
+	return &eapPacket{Code: eapCodeRequest, Identifier: s.identifier, Type: eapType, Data: data}
+}
+
+// handle advances the EAP state machine with a response from the peer and
+// returns the next EAP packet to send back.
+func (s *eapSession) handle(eap *eapPacket) (*eapPacket, error) {
+	s.identifier = eap.Identifier
+
+	switch eap.Type {
+	case eapTypeIdentity:
+		if s.tunnel != nil {
+			return nil, fmt.Errorf("unexpected EAP-Identity in an established session")
+		}
+		return s.request(s.method, []byte{eapTlsFlagStart}), nil
+	case eapTypeNak:
+		if s.tunnel != nil {
+			return nil, fmt.Errorf("unexpected EAP-Nak in an established session")
+		}
+		for _, method := range eap.Data {
+			if method == eapTypePeap || method == eapTypeTtls {
+				s.method = method
+				return s.request(s.method, []byte{eapTlsFlagStart}), nil
+			}
+		}
+		return nil, fmt.Errorf("the peer does not support PEAP or EAP-TTLS, proposed methods: %v", eap.Data)
+	case s.method:
+		return s.handleTls(eap.Data)
+	default:
+		return nil, fmt.Errorf("unexpected EAP type: %d", eap.Type)
+	}
+}
+
+func (s *eapSession) handleTls(data []byte) (*eapPacket, error) {
+	flags, payload, err := parseEapTlsData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty response acknowledges the fragment we sent last.
+	if len(payload) == 0 && len(s.pending) > 0 {
+		return s.nextFragment(), nil
+	}
+
+	s.fragments = append(s.fragments, payload...)
+	if len(s.fragments) > eapMaxMessageSize {
+		return nil, fmt.Errorf("EAP-TLS message exceeds %d bytes", eapMaxMessageSize)
+	}
+	if flags&eapTlsFlagMore != 0 {
+		return s.request(s.method, []byte{0}), nil
+	}
+
+	payload = s.fragments
+	s.fragments = nil
+
+	if s.tunnel == nil {
+		s.tunnel, err = newEapTlsTunnel(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	output, done, err := s.tunnel.step(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if done {
+		if s.user == nil {
+			return nil, fmt.Errorf("the EAP tunnel finished without authenticating a user")
+		}
+		return &eapPacket{Code: eapCodeSuccess, Identifier: s.identifier}, nil
+	}
+
+	s.pending = output
+	s.total = len(output)
+	return s.nextFragment(), nil
+}
+
+func (s *eapSession) nextFragment() *eapPacket {
+	if len(s.pending) <= eapMaxFragmentSize {
+		data := eapTlsData(0, s.pending, s.total)
+		s.pending = nil
+		return s.request(s.method, data)
+	}
+
+	flags := byte(eapTlsFlagMore)
+	if len(s.pending) == s.total {
+		flags |= eapTlsFlagLength
+	}
+	data := eapTlsData(flags, s.pending[:eapMaxFragmentSize], s.total)
+	s.pending = s.pending[eapMaxFragmentSize:]
+	return s.request(s.method, data)
+}
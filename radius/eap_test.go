@@ -0,0 +1,84 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+	"layeh.com/radius/rfc2869"
+)
+
+func TestEapPacket(t *testing.T) {
+	packet := &eapPacket{Code: eapCodeResponse, Identifier: 7, Type: eapTypeIdentity, Data: []byte("alice")}
+	parsed, err := parseEapPacket(packet.encode())
+	assert.Nil(t, err)
+	assert.Equal(t, packet, parsed)
+
+	success := &eapPacket{Code: eapCodeSuccess, Identifier: 8}
+	assert.Equal(t, []byte{eapCodeSuccess, 8, 0, 4}, success.encode())
+
+	_, err = parseEapPacket([]byte{eapCodeResponse, 1, 0, 10, eapTypeIdentity})
+	assert.NotNil(t, err)
+}
+
+func TestEapFragments(t *testing.T) {
+	session := &eapSession{method: eapTypePeap}
+	session.pending = bytes.Repeat([]byte{0xab}, eapMaxFragmentSize*2+10)
+	session.total = len(session.pending)
+
+	var received []byte
+	for i := 0; ; i++ {
+		packet := session.nextFragment()
+		flags, data, err := parseEapTlsData(packet.Data)
+		assert.Nil(t, err)
+		assert.Equal(t, i == 0, flags&eapTlsFlagLength != 0)
+		received = append(received, data...)
+		if flags&eapTlsFlagMore == 0 {
+			break
+		}
+	}
+	assert.Equal(t, eapMaxFragmentSize*2+10, len(received))
+}
+
+func TestTtlsAvps(t *testing.T) {
+	b := []byte{
+		0, 0, 0, ttlsAvpUserName, 0x40, 0, 0, 13, 'a', 'l', 'i', 'c', 'e', 0, 0, 0,
+		0, 0, 0, ttlsAvpUserPassword, 0x40, 0, 0, 24, 's', 'e', 'c', 'r', 'e', 't', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	avps, err := parseTtlsAvps(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", string(avps[ttlsAvpUserName]))
+	assert.Equal(t, "secret", string(bytes.TrimRight(avps[ttlsAvpUserPassword], "\x00")))
+
+	_, err = parseTtlsAvps(b[:20])
+	assert.NotNil(t, err)
+}
+
+func TestMessageAuthenticator(t *testing.T) {
+	packet := radius.New(radius.CodeAccessRequest, []byte("secret"))
+	rfc2865.UserName_SetString(packet, "alice")
+	rfc2869.EAPMessage_Set(packet, (&eapPacket{Code: eapCodeResponse, Type: eapTypeIdentity, Data: []byte("alice")}).encode())
+	assert.False(t, verifyMessageAuthenticator(packet))
+
+	assert.Nil(t, signMessageAuthenticator(packet))
+	assert.True(t, verifyMessageAuthenticator(packet))
+
+	rfc2865.UserName_SetString(packet, "bob")
+	assert.False(t, verifyMessageAuthenticator(packet))
+}
@@ -0,0 +1,501 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	"layeh.com/radius/rfc2759"
+)
+
+const (
+	eapTunnelStepTimeout = time.Second * 10
+	eapMschapv2Name      = "casdoor"
+)
+
+const (
+	mschapv2OpCodeChallenge = 1
+	mschapv2OpCodeResponse  = 2
+	mschapv2OpCodeSuccess   = 3
+)
+
+const (
+	ttlsAvpUserName     = 1
+	ttlsAvpUserPassword = 2
+	ttlsAvpFlagVendor   = 0x80
+)
+
+// eapTlsTunnel runs a server side TLS connection over EAP. The TLS state
+// machine lives in its own goroutine and exchanges records with the RADIUS
+// handler through channels, one EAP round trip at a time.
+type eapTlsTunnel struct {
+	session *eapSession
+
+	in      chan []byte
+	waiting chan struct{}
+	done    chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	out     bytes.Buffer
+	readBuf []byte
+	err     error
+}
+
+func getEapTlsConfig() (*tls.Config, error) {
+	certId := conf.GetConfigString("radiusEapCertId")
+	if certId == "" {
+		return nil, fmt.Errorf("EAP is not configured, please set radiusEapCertId")
+	}
+
+	rawCert, err := object.GetCert(certId)
+	if err != nil {
+		return nil, err
+	}
+	if rawCert == nil {
+		return nil, fmt.Errorf("the cert: %s does not exist", certId)
+	}
+
+	cert, err := tls.X509KeyPair([]byte(rawCert.Certificate), []byte(rawCert.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	// PEAP and EAP-TTLS key derivation is only defined up to TLS 1.2 for most supplicants
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		// an abbreviated handshake would end on the peer side and break the PEAP acknowledgement
+		SessionTicketsDisabled: true,
+	}, nil
+}
+
+func newEapTlsTunnel(session *eapSession) (*eapTlsTunnel, error) {
+	config, err := getEapTlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &eapTlsTunnel{
+		session: session,
+		in:      make(chan []byte),
+		waiting: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	go t.run(tls.Server(t, config))
+
+	err = t.wait()
+	if err != nil {
+		t.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *eapTlsTunnel) run(conn *tls.Conn) {
+	defer close(t.done)
+
+	err := conn.Handshake()
+	if err != nil {
+		t.err = err
+		return
+	}
+
+	label := "client EAP encryption"
+	if t.session.method == eapTypeTtls {
+		label = "ttls keying material"
+	}
+	state := conn.ConnectionState()
+	msk, err := state.ExportKeyingMaterial(label, nil, 64)
+	if err != nil {
+		t.err = err
+		return
+	}
+
+	if t.session.method == eapTypeTtls {
+		t.err = t.runTtls(conn)
+	} else {
+		t.err = t.runPeap(conn)
+	}
+	if t.err == nil {
+		t.session.msk = msk
+	}
+}
+
+// wait blocks until the TLS goroutine needs more data from the peer or ends.
+func (t *eapTlsTunnel) wait() error {
+	select {
+	case <-t.waiting:
+		return nil
+	case <-t.done:
+		return nil
+	case <-time.After(eapTunnelStepTimeout):
+		return fmt.Errorf("the EAP tunnel timed out")
+	}
+}
+
+func (t *eapTlsTunnel) step(data []byte) ([]byte, bool, error) {
+	select {
+	case t.in <- data:
+	case <-t.done:
+		return nil, true, t.err
+	}
+
+	err := t.wait()
+	if err != nil {
+		return nil, false, err
+	}
+
+	select {
+	case <-t.done:
+		return nil, true, t.err
+	default:
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	output := append([]byte(nil), t.out.Bytes()...)
+	t.out.Reset()
+	return output, false, nil
+}
+
+func (t *eapTlsTunnel) close() {
+	t.once.Do(func() {
+		close(t.closed)
+	})
+}
+
+// next hands the control back to the RADIUS handler and returns the TLS data
+// of the next EAP response.
+func (t *eapTlsTunnel) next() ([]byte, error) {
+	select {
+	case t.waiting <- struct{}{}:
+	default:
+	}
+
+	select {
+	case data := <-t.in:
+		return data, nil
+	case <-t.closed:
+		return nil, io.EOF
+	}
+}
+
+func (t *eapTlsTunnel) Read(b []byte) (int, error) {
+	for len(t.readBuf) == 0 {
+		data, err := t.next()
+		if err != nil {
+			return 0, err
+		}
+		t.readBuf = data
+	}
+
+	n := copy(b, t.readBuf)
+	t.readBuf = t.readBuf[n:]
+	return n, nil
+}
+
+func (t *eapTlsTunnel) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.out.Write(b)
+}
+
+func (t *eapTlsTunnel) Close() error {
+	t.close()
+	return nil
+}
+
+func (t *eapTlsTunnel) LocalAddr() net.Addr {
+	return &net.UDPAddr{}
+}
+
+func (t *eapTlsTunnel) RemoteAddr() net.Addr {
+	return &net.UDPAddr{}
+}
+
+func (t *eapTlsTunnel) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (t *eapTlsTunnel) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (t *eapTlsTunnel) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func readTunnelMessage(conn *tls.Conn) ([]byte, error) {
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("the tunneled message is empty")
+	}
+	return buf[:n], nil
+}
+
+// runPeap implements PEAPv0 with MS-CHAPv2 as the inner method. Inner EAP
+// packets are sent without their header, except for the Result TLV.
+func (t *eapTlsTunnel) runPeap(conn *tls.Conn) error {
+	// the peer acknowledges our TLS Finished message with an empty response
+	_, err := t.next()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Write([]byte{eapTypeIdentity})
+	if err != nil {
+		return err
+	}
+	message, err := readTunnelMessage(conn)
+	if err != nil {
+		return err
+	}
+	if message[0] != eapTypeIdentity {
+		return fmt.Errorf("expected the inner EAP-Identity, got type %d", message[0])
+	}
+
+	user, err := getEapUser(t.session.organization, string(message[1:]))
+	if err != nil {
+		return err
+	}
+	password, err := getUserPlainPassword(user)
+	if err != nil {
+		return err
+	}
+
+	challenge := make([]byte, 16)
+	_, err = rand.Read(challenge)
+	if err != nil {
+		return err
+	}
+
+	msId := t.session.identifier
+	_, err = conn.Write(buildMschapv2Message(mschapv2OpCodeChallenge, msId, append(append([]byte{16}, challenge...), eapMschapv2Name...)))
+	if err != nil {
+		return err
+	}
+	message, err = readTunnelMessage(conn)
+	if err != nil {
+		return err
+	}
+	// Type, OpCode, MS-CHAPv2-ID, MS-Length, Value-Size, Value (49 bytes), Name
+	if len(message) < 55 || message[0] != eapTypeMschapv2 || message[1] != mschapv2OpCodeResponse || message[5] != 49 {
+		return fmt.Errorf("invalid MS-CHAPv2 response")
+	}
+
+	peerChallenge := message[6:22]
+	ntResponse := message[30:54]
+	username := string(message[55:])
+	if i := strings.LastIndex(username, "\\"); i != -1 {
+		username = username[i+1:]
+	}
+
+	expected, err := rfc2759.GenerateNTResponse(challenge, peerChallenge, []byte(username), []byte(password))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, ntResponse) != 1 {
+		return fmt.Errorf("MS-CHAPv2 password is incorrect for user: %s", user.GetId())
+	}
+
+	authResponse, err := rfc2759.GenerateAuthenticatorResponse(challenge, peerChallenge, ntResponse, []byte(username), []byte(password))
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(buildMschapv2Message(mschapv2OpCodeSuccess, message[2], []byte(authResponse+" M=success")))
+	if err != nil {
+		return err
+	}
+	message, err = readTunnelMessage(conn)
+	if err != nil {
+		return err
+	}
+	if len(message) < 2 || message[0] != eapTypeMschapv2 || message[1] != mschapv2OpCodeSuccess {
+		return fmt.Errorf("the peer did not acknowledge the MS-CHAPv2 success")
+	}
+
+	// Result TLV (mandatory, type 3) with status Success
+	result := &eapPacket{Code: eapCodeRequest, Identifier: t.session.identifier + 1, Type: eapTypeTlv, Data: []byte{0x80, 0x03, 0x00, 0x02, 0x00, 0x01}}
+	_, err = conn.Write(result.encode())
+	if err != nil {
+		return err
+	}
+	message, err = readTunnelMessage(conn)
+	if err != nil {
+		return err
+	}
+	response, err := parseEapPacket(message)
+	if err != nil {
+		return err
+	}
+	if response.Type != eapTypeTlv || len(response.Data) < 6 || binary.BigEndian.Uint16(response.Data[4:6]) != 1 {
+		return fmt.Errorf("the peer did not confirm the PEAP result")
+	}
+
+	t.session.user = user
+	return nil
+}
+
+func buildMschapv2Message(opCode byte, msId byte, value []byte) []byte {
+	b := make([]byte, 5, 5+len(value))
+	b[0] = eapTypeMschapv2
+	b[1] = opCode
+	b[2] = msId
+	binary.BigEndian.PutUint16(b[3:5], uint16(4+len(value)))
+	return append(b, value...)
+}
+
+// runTtls implements EAP-TTLS with PAP as the inner method, the credentials
+// arrive as Diameter AVPs right after the handshake.
+func (t *eapTlsTunnel) runTtls(conn *tls.Conn) error {
+	message, err := readTunnelMessage(conn)
+	if err != nil {
+		return err
+	}
+
+	avps, err := parseTtlsAvps(message)
+	if err != nil {
+		return err
+	}
+
+	username := string(avps[ttlsAvpUserName])
+	password := string(bytes.TrimRight(avps[ttlsAvpUserPassword], "\x00"))
+	if username == "" || password == "" {
+		return fmt.Errorf("EAP-TTLS requires User-Name and User-Password")
+	}
+
+	organization := t.session.organization
+	if strings.Contains(username, "/") {
+		organization, username, err = util.GetOwnerAndNameFromIdWithError(username)
+		if err != nil {
+			return err
+		}
+	}
+
+	user, err := object.CheckUserPassword(organization, username, password, "en")
+	if err != nil {
+		return err
+	}
+	if user.IsMfaEnabled() {
+		return fmt.Errorf("the user: %s has MFA enabled, which is not supported by EAP-TTLS", user.GetId())
+	}
+
+	t.session.user = user
+	return nil
+}
+
+func parseTtlsAvps(b []byte) (map[uint32][]byte, error) {
+	avps := map[uint32][]byte{}
+	for len(b) > 0 {
+		if len(b) < 8 {
+			return nil, fmt.Errorf("EAP-TTLS AVP is truncated")
+		}
+
+		code := binary.BigEndian.Uint32(b[0:4])
+		flags := b[4]
+		length := int(b[5])<<16 | int(b[6])<<8 | int(b[7])
+		headerLength := 8
+		if flags&ttlsAvpFlagVendor != 0 {
+			headerLength = 12
+		}
+		if length < headerLength || length > len(b) {
+			return nil, fmt.Errorf("invalid EAP-TTLS AVP length: %d", length)
+		}
+
+		if flags&ttlsAvpFlagVendor == 0 {
+			avps[code] = b[headerLength:length]
+		}
+
+		padded := (length + 3) &^ 3
+		if padded > len(b) {
+			padded = len(b)
+		}
+		b = b[padded:]
+	}
+	return avps, nil
+}
+
+func getEapUser(organization string, identity string) (*object.User, error) {
+	if strings.Contains(identity, "/") {
+		var err error
+		organization, identity, err = util.GetOwnerAndNameFromIdWithError(identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := object.GetUserByFields(organization, identity)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeleted {
+		return nil, fmt.Errorf("the user: %s doesn't exist", util.GetId(organization, identity))
+	}
+	if user.IsForbidden {
+		return nil, fmt.Errorf("the user: %s is forbidden", user.GetId())
+	}
+	if user.IsMfaEnabled() {
+		return nil, fmt.Errorf("the user: %s has MFA enabled, which is not supported by PEAP", user.GetId())
+	}
+	return user, nil
+}
+
+// getUserPlainPassword returns the clear text password MS-CHAPv2 needs to
+// compute the NT response, which is only available with the plain password type.
+func getUserPlainPassword(user *object.User) (string, error) {
+	if user.Ldap != "" {
+		return "", fmt.Errorf("PEAP-MSCHAPv2 is not supported for LDAP user: %s, please use EAP-TTLS", user.GetId())
+	}
+
+	organization, err := object.GetOrganizationByUser(user)
+	if err != nil {
+		return "", err
+	}
+	if organization == nil {
+		return "", fmt.Errorf("the organization: %s doesn't exist", user.Owner)
+	}
+
+	passwordType := user.PasswordType
+	if passwordType == "" {
+		passwordType = organization.PasswordType
+	}
+	if passwordType != "plain" {
+		return "", fmt.Errorf("PEAP-MSCHAPv2 requires the plain password type, but user: %s uses %s, please use EAP-TTLS", user.GetId(), passwordType)
+	}
+	return user.Password, nil
+}
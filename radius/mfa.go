@@ -0,0 +1,179 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/casdoor/casdoor/object"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+)
+
+const (
+	radiusChallengeTimeout = time.Second * 120
+	radiusPushResponse     = "push"
+)
+
+func writeAccessChallenge(w radius.ResponseWriter, r *radius.Request, state string, message string) {
+	response := r.Response(radius.CodeAccessChallenge)
+
+	err := rfc2865.State_SetString(response, state)
+	if err != nil {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	err = rfc2865.ReplyMessage_SetString(response, message)
+	if err != nil {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	w.Write(response)
+}
+
+// getPushProvider returns the email provider which asks the user to approve the sign-in, push approval is
+// only offered when the user can be reached by email
+func getPushProvider(user *object.User) *object.Provider {
+	provider, err := object.GetApprovalProvider(user)
+	if err != nil {
+		return nil
+	}
+	return provider
+}
+
+// sendMfaCode sends the one-time code of an SMS or email MFA, and returns the prompt shown to the user
+func sendMfaCode(user *object.User, mfaType string, clientIp string) (string, error) {
+	if mfaType != object.SmsType && mfaType != object.EmailType {
+		return "please enter OTP", nil
+	}
+
+	dest, err := object.SendMfaCode(user, mfaType, clientIp)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("please enter the code sent to %s", dest), nil
+}
+
+// startMfaChallenge answers a correct password of an MFA user with an Access-Challenge for the preferred MFA method
+func startMfaChallenge(w radius.ResponseWriter, r *radius.Request, user *object.User) {
+	mfaProps := user.GetPreferredMfaProps(false)
+	if mfaProps == nil || !mfaProps.Enabled {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	clientIp := getRemoteIp(r.RemoteAddr)
+	message, err := sendMfaCode(user, mfaProps.MfaType, clientIp)
+	if err != nil {
+		log.Printf("startMfaChallenge() failed for user: %s, err = %v", user.GetId(), err)
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	if getPushProvider(user) != nil {
+		message += fmt.Sprintf(", or enter \"%s\" to approve the sign-in in Casdoor", radiusPushResponse)
+	}
+
+	challenge := object.NewRadiusChallenge(user.GetId(), clientIp, mfaProps.MfaType, radiusChallengeTimeout)
+	err = object.AddRadiusChallenge(challenge)
+	if err != nil {
+		log.Printf("startMfaChallenge() failed for user: %s, err = %v", user.GetId(), err)
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	writeAccessChallenge(w, r, challenge.State, message)
+}
+
+func startPushApproval(w radius.ResponseWriter, r *radius.Request, user *object.User, challenge *object.RadiusChallenge) {
+	provider := getPushProvider(user)
+	if provider == nil {
+		writeAccessChallenge(w, r, challenge.State, "push approval is not available, please enter OTP")
+		return
+	}
+
+	content := fmt.Sprintf("RADIUS sign-in request of %s from %s, please approve or deny it in Casdoor", user.GetId(), challenge.ClientIp)
+	err := object.SendApprovalRequest(user, provider, content)
+	if err != nil {
+		log.Printf("startPushApproval() failed for user: %s, err = %v", user.GetId(), err)
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	challenge.MfaType = object.RadiusPushType
+	challenge.ApprovalState = object.RadiusApprovalStatePending
+	err = object.UpdateRadiusChallenge(challenge)
+	if err != nil {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	writeAccessChallenge(w, r, challenge.State, "please approve the sign-in request in Casdoor, then press enter")
+}
+
+// handleMfaChallenge checks the response to an Access-Challenge, the challenge can only be used once
+// except while a push approval is pending
+func handleMfaChallenge(w radius.ResponseWriter, r *radius.Request, userId string, state string, password string) {
+	challenge, err := object.GetRadiusChallenge(state)
+	if err != nil || challenge == nil || challenge.UserId != userId {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	user, err := object.GetUser(challenge.UserId)
+	if err != nil || user == nil || user.IsForbidden || user.IsDeleted {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	if challenge.MfaType != object.RadiusPushType && password == radiusPushResponse {
+		startPushApproval(w, r, user, challenge)
+		return
+	}
+
+	if challenge.MfaType == object.RadiusPushType && challenge.ApprovalState == object.RadiusApprovalStatePending {
+		writeAccessChallenge(w, r, challenge.State, "the sign-in request is not approved yet, please approve it in Casdoor, then press enter")
+		return
+	}
+
+	consumed, err := object.ConsumeRadiusChallenge(challenge.State)
+	if err != nil || !consumed {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	if challenge.MfaType == object.RadiusPushType {
+		if challenge.ApprovalState != object.RadiusApprovalStateApproved {
+			w.Write(r.Response(radius.CodeAccessReject))
+			return
+		}
+
+		writeAccessAccept(w, r, user)
+		return
+	}
+
+	mfaProps := user.GetMfaProps(challenge.MfaType, false)
+	mfaUtil := object.GetMfaUtil(challenge.MfaType, mfaProps)
+	if mfaUtil == nil || mfaUtil.Verify(password) != nil {
+		w.Write(r.Response(radius.CodeAccessReject))
+		return
+	}
+
+	writeAccessAccept(w, r, user)
+}
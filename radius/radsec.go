@@ -0,0 +1,185 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"layeh.com/radius"
+)
+
+const (
+	// RFC 6614: the shared secret of RADIUS over TLS is always "radsec"
+	radsecSecret      = "radsec"
+	radsecIdleTimeout = time.Minute * 5
+)
+
+// StartRadSecServer listens for RADIUS over TLS (RFC 6614), the NAS clients must present a certificate
+// signed by the CA of radsecClientCaCertId
+func StartRadSecServer() {
+	radsecServerPort := conf.GetConfigString("radsecServerPort")
+	if radsecServerPort == "" || radsecServerPort == "0" {
+		return
+	}
+	radsecCertId := conf.GetConfigString("radsecCertId")
+	if radsecCertId == "" {
+		return
+	}
+
+	config, err := getRadSecTlsConfig(radsecCertId, conf.GetConfigString("radsecClientCaCertId"))
+	if err != nil {
+		log.Printf("StartRadSecServer() failed, err = %v", err)
+		return
+	}
+
+	listener, err := tls.Listen("tcp", "0.0.0.0:"+radsecServerPort, config)
+	if err != nil {
+		log.Printf("StartRadSecServer() failed, err = %v", err)
+		return
+	}
+
+	log.Printf("Starting RadSec server on %s", listener.Addr())
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("StartRadSecServer() failed, err = %v", err)
+			return
+		}
+
+		go handleRadSecConn(conn)
+	}
+}
+
+func getRadSecTlsConfig(certId string, clientCaCertId string) (*tls.Config, error) {
+	rawCert, err := object.GetCert(certId)
+	if err != nil {
+		return nil, err
+	}
+	if rawCert == nil {
+		return nil, fmt.Errorf("the cert: %s does not exist", certId)
+	}
+
+	cert, err := tls.X509KeyPair([]byte(rawCert.Certificate), []byte(rawCert.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if clientCaCertId == "" {
+		return nil, fmt.Errorf("RadSec requires mutual TLS, please set radsecClientCaCertId")
+	}
+	caCert, err := object.GetCert(clientCaCertId)
+	if err != nil {
+		return nil, err
+	}
+	if caCert == nil {
+		return nil, fmt.Errorf("the cert: %s does not exist", clientCaCertId)
+	}
+
+	clientCas := x509.NewCertPool()
+	if !clientCas.AppendCertsFromPEM([]byte(caCert.Certificate)) {
+		return nil, fmt.Errorf("the cert: %s has no valid certificate", clientCaCertId)
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCas,
+	}, nil
+}
+
+// radsecResponseWriter writes replies back to the TLS stream, requests of the same connection are
+// handled concurrently like the UDP server does
+type radsecResponseWriter struct {
+	conn net.Conn
+	mu   *sync.Mutex
+}
+
+func (w radsecResponseWriter) Write(packet *radius.Packet) error {
+	b, err := packet.Encode()
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err = w.conn.Write(b)
+	return err
+}
+
+func readRadSecPacket(conn net.Conn) ([]byte, error) {
+	header := make([]byte, 4)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return nil, err
+	}
+
+	length := int(binary.BigEndian.Uint16(header[2:4]))
+	if length < 20 || length > radius.MaxPacketLength {
+		return nil, fmt.Errorf("invalid RADIUS packet length: %d", length)
+	}
+
+	b := make([]byte, length)
+	copy(b, header)
+	_, err = io.ReadFull(conn, b[4:])
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func handleRadSecConn(conn net.Conn) {
+	defer conn.Close()
+
+	w := radsecResponseWriter{conn: conn, mu: &sync.Mutex{}}
+	for {
+		err := conn.SetReadDeadline(time.Now().Add(radsecIdleTimeout))
+		if err != nil {
+			return
+		}
+
+		b, err := readRadSecPacket(conn)
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("handleRadSecConn() closed the connection from %s, err = %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		packet, err := radius.Parse(b, []byte(radsecSecret))
+		if err != nil {
+			log.Printf("handleRadSecConn() got an invalid packet from %s, err = %v", conn.RemoteAddr(), err)
+			return
+		}
+
+		r := &radius.Request{
+			LocalAddr:  conn.LocalAddr(),
+			RemoteAddr: conn.RemoteAddr(),
+			Packet:     packet,
+		}
+		go handlerRadius(w, r)
+	}
+}
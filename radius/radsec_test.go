@@ -0,0 +1,51 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+)
+
+func TestReadRadSecPacket(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+
+	packet := radius.New(radius.CodeAccessRequest, []byte(radsecSecret))
+	rfc2865.UserName_SetString(packet, "alice")
+	b, err := packet.Encode()
+	assert.Nil(t, err)
+
+	go func() {
+		client.Write(append(b, b...))
+		client.Close()
+	}()
+
+	for i := 0; i < 2; i++ {
+		received, err := readRadSecPacket(server)
+		assert.Nil(t, err)
+
+		parsed, err := radius.Parse(received, []byte(radsecSecret))
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", rfc2865.UserName_GetString(parsed))
+	}
+
+	_, err = readRadSecPacket(server)
+	assert.NotNil(t, err)
+}
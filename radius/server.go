@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"log"
 	"strings"
-	"time"
 
 	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/object"
@@ -26,22 +25,14 @@ import (
 	"layeh.com/radius"
 	"layeh.com/radius/rfc2865"
 	"layeh.com/radius/rfc2866"
+	"layeh.com/radius/rfc2869"
 )
 
-var StateMap map[string]AccessStateContent
-
-const StateExpiredTime = time.Second * 120
-
-type AccessStateContent struct {
-	ExpiredAt time.Time
-}
-
 func StartRadiusServer() {
-	secret := conf.GetConfigString("radiusSecret")
 	server := radius.PacketServer{
 		Addr:         "0.0.0.0:" + conf.GetConfigString("radiusServerPort"),
 		Handler:      radius.HandlerFunc(handlerRadius),
-		SecretSource: radius.StaticSecretSource([]byte(secret)),
+		SecretSource: clientSecretSource{},
 	}
 	log.Printf("Starting Radius server on %s", server.Addr)
 	if err := server.ListenAndServe(); err != nil {
@@ -61,89 +52,35 @@ func handlerRadius(w radius.ResponseWriter, r *radius.Request) {
 }
 
 func handleAccessRequest(w radius.ResponseWriter, r *radius.Request) {
+	eapMessage, err := rfc2869.EAPMessage_Lookup(r.Packet)
+	if err == nil {
+		handleEapAccessRequest(w, r, eapMessage)
+		return
+	}
+
 	username := rfc2865.UserName_GetString(r.Packet)
 	password := rfc2865.UserPassword_GetString(r.Packet)
-	organization := rfc2865.Class_GetString(r.Packet)
+	organization := getRadiusOrganization(r)
 	state := rfc2865.State_GetString(r.Packet)
 	log.Printf("handleAccessRequest() username=%v, org=%v, password=%v", username, organization, password)
 
-	if organization == "" {
-		organization = conf.GetConfigString("radiusDefaultOrganization")
-		if organization == "" {
-			organization = "built-in"
-		}
-	}
-
-	var user *object.User
-	var err error
-
-	if state == "" {
-		user, err = object.CheckUserPassword(organization, username, password, "en")
-	} else {
-		user, err = object.GetUser(fmt.Sprintf("%s/%s", organization, username))
+	if state != "" {
+		handleMfaChallenge(w, r, util.GetId(organization, username), state, password)
+		return
 	}
 
+	user, err := object.CheckUserPassword(organization, username, password, "en")
 	if err != nil {
 		w.Write(r.Response(radius.CodeAccessReject))
 		return
 	}
 
 	if user.IsMfaEnabled() {
-		mfaProp := user.GetMfaProps(object.TotpType, false)
-		if mfaProp == nil {
-			w.Write(r.Response(radius.CodeAccessReject))
-			return
-		}
-
-		if StateMap == nil {
-			StateMap = map[string]AccessStateContent{}
-		}
-
-		if state != "" {
-			stateContent, ok := StateMap[state]
-			if !ok {
-				w.Write(r.Response(radius.CodeAccessReject))
-				return
-			}
-
-			delete(StateMap, state)
-			if stateContent.ExpiredAt.Before(time.Now()) {
-				w.Write(r.Response(radius.CodeAccessReject))
-				return
-			}
-
-			mfaUtil := object.GetMfaUtil(mfaProp.MfaType, mfaProp)
-			if mfaUtil.Verify(password) != nil {
-				w.Write(r.Response(radius.CodeAccessReject))
-				return
-			}
-
-			w.Write(r.Response(radius.CodeAccessAccept))
-			return
-		}
-
-		responseState := util.GenerateId()
-		StateMap[responseState] = AccessStateContent{
-			time.Now().Add(StateExpiredTime),
-		}
-
-		err = rfc2865.State_Set(r.Packet, []byte(responseState))
-		if err != nil {
-			w.Write(r.Response(radius.CodeAccessReject))
-			return
-		}
-
-		err = rfc2865.ReplyMessage_Set(r.Packet, []byte("please enter OTP"))
-		if err != nil {
-			w.Write(r.Response(radius.CodeAccessReject))
-			return
-		}
-
-		r.Packet.Code = radius.CodeAccessChallenge
-		w.Write(r.Packet)
+		startMfaChallenge(w, r, user)
+		return
 	}
 
-	w.Write(r.Response(radius.CodeAccessAccept))
+	writeAccessAccept(w, r, user)
 }
 
 func handleAccountingRequest(w radius.ResponseWriter, r *radius.Request) {
@@ -243,6 +243,8 @@ func initAPI() {
 	beego.Router("/api/add-syncer", &controllers.ApiController{}, "POST:AddSyncer")
 	beego.Router("/api/delete-syncer", &controllers.ApiController{}, "POST:DeleteSyncer")
 	beego.Router("/api/run-syncer", &controllers.ApiController{}, "GET:RunSyncer")
+	beego.Router("/api/dry-run-syncer", &controllers.ApiController{}, "POST:DryRunSyncer")
+	beego.Router("/api/get-syncer-runs", &controllers.ApiController{}, "GET:GetSyncerRuns")
 	beego.Router("/api/test-syncer-db", &controllers.ApiController{}, "POST:TestSyncerDb")
 
 	beego.Router("/api/get-webhooks", &controllers.ApiController{}, "GET:GetWebhooks")
@@ -250,6 +252,22 @@ func initAPI() {
 	beego.Router("/api/update-webhook", &controllers.ApiController{}, "POST:UpdateWebhook")
 	beego.Router("/api/add-webhook", &controllers.ApiController{}, "POST:AddWebhook")
 	beego.Router("/api/delete-webhook", &controllers.ApiController{}, "POST:DeleteWebhook")
+	beego.Router("/api/get-webhook-deliveries", &controllers.ApiController{}, "GET:GetWebhookDeliveries")
+	beego.Router("/api/replay-webhook-deliveries", &controllers.ApiController{}, "POST:ReplayWebhookDeliveries")
+	beego.Router("/api/rotate-webhook-secret", &controllers.ApiController{}, "POST:RotateWebhookSecret")
+
+	beego.Router("/api/get-event-hooks", &controllers.ApiController{}, "GET:GetEventHooks")
+	beego.Router("/api/get-event-hook", &controllers.ApiController{}, "GET:GetEventHook")
+	beego.Router("/api/update-event-hook", &controllers.ApiController{}, "POST:UpdateEventHook")
+	beego.Router("/api/add-event-hook", &controllers.ApiController{}, "POST:AddEventHook")
+	beego.Router("/api/delete-event-hook", &controllers.ApiController{}, "POST:DeleteEventHook")
+
+	beego.Router("/api/get-event-sinks", &controllers.ApiController{}, "GET:GetEventSinks")
+	beego.Router("/api/get-event-sink", &controllers.ApiController{}, "GET:GetEventSink")
+	beego.Router("/api/update-event-sink", &controllers.ApiController{}, "POST:UpdateEventSink")
+	beego.Router("/api/add-event-sink", &controllers.ApiController{}, "POST:AddEventSink")
+	beego.Router("/api/delete-event-sink", &controllers.ApiController{}, "POST:DeleteEventSink")
+	beego.Router("/api/get-domain-events", &controllers.ApiController{}, "GET:GetDomainEvents")
 
 	beego.Router("/api/set-password", &controllers.ApiController{}, "POST:SetPassword")
 	beego.Router("/api/check-user-password", &controllers.ApiController{}, "POST:CheckUserPassword")
@@ -268,6 +286,30 @@ func initAPI() {
 	beego.Router("/api/update-ldap", &controllers.ApiController{}, "POST:UpdateLdap")
 	beego.Router("/api/delete-ldap", &controllers.ApiController{}, "POST:DeleteLdap")
 	beego.Router("/api/sync-ldap-users", &controllers.ApiController{}, "POST:SyncLdapUsers")
+	beego.Router("/api/run-ldap-sync", &controllers.ApiController{}, "POST:RunLdapSync")
+	beego.Router("/api/get-ldap-service-accounts", &controllers.ApiController{}, "GET:GetLdapServiceAccounts")
+	beego.Router("/api/get-ldap-service-account", &controllers.ApiController{}, "GET:GetLdapServiceAccount")
+	beego.Router("/api/update-ldap-service-account", &controllers.ApiController{}, "POST:UpdateLdapServiceAccount")
+	beego.Router("/api/add-ldap-service-account", &controllers.ApiController{}, "POST:AddLdapServiceAccount")
+	beego.Router("/api/delete-ldap-service-account", &controllers.ApiController{}, "POST:DeleteLdapServiceAccount")
+	beego.Router("/api/get-ldap-bind-approvals", &controllers.ApiController{}, "GET:GetLdapBindApprovals")
+	beego.Router("/api/approve-ldap-bind", &controllers.ApiController{}, "POST:ApproveLdapBind")
+
+	beego.Router("/api/get-scim-provisioning-records", &controllers.ApiController{}, "GET:GetScimProvisioningRecords")
+	beego.Router("/api/run-scim-reconciliation", &controllers.ApiController{}, "POST:RunScimReconciliation")
+
+	beego.Router("/api/get-radius-clients", &controllers.ApiController{}, "GET:GetRadiusClients")
+	beego.Router("/api/get-radius-client", &controllers.ApiController{}, "GET:GetRadiusClient")
+	beego.Router("/api/update-radius-client", &controllers.ApiController{}, "POST:UpdateRadiusClient")
+	beego.Router("/api/add-radius-client", &controllers.ApiController{}, "POST:AddRadiusClient")
+	beego.Router("/api/delete-radius-client", &controllers.ApiController{}, "POST:DeleteRadiusClient")
+	beego.Router("/api/get-radius-policies", &controllers.ApiController{}, "GET:GetRadiusPolicies")
+	beego.Router("/api/get-radius-policy", &controllers.ApiController{}, "GET:GetRadiusPolicy")
+	beego.Router("/api/update-radius-policy", &controllers.ApiController{}, "POST:UpdateRadiusPolicy")
+	beego.Router("/api/add-radius-policy", &controllers.ApiController{}, "POST:AddRadiusPolicy")
+	beego.Router("/api/delete-radius-policy", &controllers.ApiController{}, "POST:DeleteRadiusPolicy")
+	beego.Router("/api/get-radius-challenges", &controllers.ApiController{}, "GET:GetRadiusChallenges")
+	beego.Router("/api/approve-radius-challenge", &controllers.ApiController{}, "POST:ApproveRadiusChallenge")
 
 	beego.Router("/api/login/oauth/access_token", &controllers.ApiController{}, "POST:GetOAuthToken")
 	beego.Router("/api/login/oauth/refresh_token", &controllers.ApiController{}, "POST:RefreshToken")
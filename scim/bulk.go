@@ -0,0 +1,215 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/errors"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.7 Bulk Operations
+
+const (
+	BulkRequestSchema  = "urn:ietf:params:scim:api:messages:2.0:BulkRequest"
+	BulkResponseSchema = "urn:ietf:params:scim:api:messages:2.0:BulkResponse"
+
+	BulkMaxOperations  = 1000
+	BulkMaxPayloadSize = 1048576
+)
+
+type BulkOperation struct {
+	Method  string          `json:"method"`
+	BulkId  string          `json:"bulkId,omitempty"`
+	Version string          `json:"version,omitempty"`
+	Path    string          `json:"path"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type BulkRequest struct {
+	Schemas      []string         `json:"schemas"`
+	FailOnErrors int              `json:"failOnErrors"`
+	Operations   []*BulkOperation `json:"Operations"`
+}
+
+type BulkOperationResponse struct {
+	Method   string          `json:"method"`
+	BulkId   string          `json:"bulkId,omitempty"`
+	Version  string          `json:"version,omitempty"`
+	Location string          `json:"location,omitempty"`
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+type BulkResponse struct {
+	Schemas    []string                 `json:"schemas"`
+	Operations []*BulkOperationResponse `json:"Operations"`
+}
+
+// bulkResponseWriter records the response of an operation that is dispatched to the SCIM server
+type bulkResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bulkResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bulkResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *bulkResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func writeScimError(w http.ResponseWriter, scimErr errors.ScimError) {
+	writeScimResponse(w, scimErr.Status, scimErr)
+}
+
+func handleBulk(w http.ResponseWriter, r *http.Request, server scim.Server) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, BulkMaxPayloadSize+1))
+	if err != nil {
+		writeScimError(w, errors.ScimErrorInvalidSyntax)
+		return
+	}
+	if len(data) > BulkMaxPayloadSize {
+		writeScimError(w, errors.ScimError{
+			Detail: fmt.Sprintf("the size of the bulk request exceeds the maxPayloadSize: %d", BulkMaxPayloadSize),
+			Status: http.StatusRequestEntityTooLarge,
+		})
+		return
+	}
+
+	var request BulkRequest
+	err = json.Unmarshal(data, &request)
+	if err != nil || len(request.Schemas) != 1 || request.Schemas[0] != BulkRequestSchema {
+		writeScimError(w, errors.ScimErrorInvalidSyntax)
+		return
+	}
+	if len(request.Operations) > BulkMaxOperations {
+		writeScimError(w, errors.ScimError{
+			Detail: fmt.Sprintf("the number of the bulk operations exceeds the maxOperations: %d", BulkMaxOperations),
+			Status: http.StatusRequestEntityTooLarge,
+		})
+		return
+	}
+
+	response := BulkResponse{
+		Schemas:    []string{BulkResponseSchema},
+		Operations: []*BulkOperationResponse{},
+	}
+
+	// The ids of the resources created in this request, referenced as "bulkId:<bulkId>" by the later operations
+	bulkIds := map[string]string{}
+	errorCount := 0
+	for _, operation := range request.Operations {
+		operationResponse := doBulkOperation(server, r, operation, bulkIds)
+		response.Operations = append(response.Operations, operationResponse)
+
+		if status, _ := strconv.Atoi(operationResponse.Status); status >= http.StatusBadRequest {
+			errorCount++
+			if request.FailOnErrors > 0 && errorCount >= request.FailOnErrors {
+				break
+			}
+		}
+	}
+
+	writeScimResponse(w, http.StatusOK, response)
+}
+
+func doBulkOperation(server scim.Server, r *http.Request, operation *BulkOperation, bulkIds map[string]string) *BulkOperationResponse {
+	method := strings.ToUpper(operation.Method)
+	res := &BulkOperationResponse{
+		Method: method,
+		BulkId: operation.BulkId,
+	}
+	writeError := func(scimErr errors.ScimError) *BulkOperationResponse {
+		res.Status = strconv.Itoa(scimErr.Status)
+		res.Response, _ = json.Marshal(scimErr)
+		return res
+	}
+
+	if method == http.MethodPost && operation.BulkId == "" {
+		return writeError(errors.ScimErrorBadRequest("bulkId is required for the POST operation"))
+	}
+	if !strings.HasPrefix(operation.Path, "/") || strings.HasPrefix(operation.Path, "/Bulk") {
+		return writeError(errors.ScimErrorInvalidPath)
+	}
+
+	path := replaceBulkIds(operation.Path, bulkIds)
+	data := replaceBulkIds(string(operation.Data), bulkIds)
+	if strings.Contains(path+data, "bulkId:") {
+		return writeError(errors.ScimError{
+			Detail: "the operation references a bulkId that isn't created",
+			Status: http.StatusConflict,
+		})
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), method, path, strings.NewReader(data))
+	if err != nil {
+		return writeError(errors.ScimErrorInvalidPath)
+	}
+	req.Header.Set("Content-Type", "application/scim+json")
+	if operation.Version != "" {
+		req.Header.Set("If-Match", operation.Version)
+	}
+
+	writer := &bulkResponseWriter{header: http.Header{}}
+	server.ServeHTTP(writer, req)
+
+	res.Status = strconv.Itoa(writer.status)
+	res.Version = writer.header.Get("Etag")
+	if writer.status >= http.StatusBadRequest {
+		res.Response = writer.body.Bytes()
+		return res
+	}
+
+	var resource struct {
+		Id   string `json:"id"`
+		Meta struct {
+			Location string `json:"location"`
+		} `json:"meta"`
+	}
+	if json.Unmarshal(writer.body.Bytes(), &resource) == nil {
+		res.Location = resource.Meta.Location
+		if method == http.MethodPost {
+			bulkIds[operation.BulkId] = resource.Id
+		}
+	}
+	if res.Location == "" && method != http.MethodPost {
+		res.Location = strings.TrimPrefix(path, "/")
+	}
+	return res
+}
+
+func replaceBulkIds(s string, bulkIds map[string]string) string {
+	for bulkId, id := range bulkIds {
+		s = strings.ReplaceAll(s, fmt.Sprintf("bulkId:%s", bulkId), id)
+	}
+	return s
+}
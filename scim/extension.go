@@ -0,0 +1,320 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/optional"
+	"github.com/elimity-com/scim/schema"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc7643#section-4.3 Enterprise User Schema Extension
+// The department is mapped to the affiliation of the user, the other attributes are stored in the user properties.
+// The organization attribute is the organization of the user in Casdoor.
+
+var EnterpriseUserAttributes = []string{"employeeNumber", "costCenter", "division", "department", "manager"}
+
+type extensionsKey struct{}
+
+func withRequestExtensions(r *http.Request, extensions map[string][]*object.ScimExtension) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), extensionsKey{}, extensions))
+}
+
+// getRequestExtensions returns the custom schema extensions of the organizations that the request can access
+func getRequestExtensions(r *http.Request) map[string][]*object.ScimExtension {
+	extensions, _ := r.Context().Value(extensionsKey{}).(map[string][]*object.ScimExtension)
+	return extensions
+}
+
+func getEnterpriseUserSchema() schema.Schema {
+	return schema.Schema{
+		ID:          UserExtensionKey,
+		Name:        optional.NewString("EnterpriseUser"),
+		Description: optional.NewString("Enterprise User"),
+		Attributes: []schema.CoreAttribute{
+			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
+				Name:     "organization",
+				Required: true,
+			})),
+			schema.SimpleCoreAttribute(newStringParams("employeeNumber", false, false)),
+			schema.SimpleCoreAttribute(newStringParams("costCenter", false, false)),
+			schema.SimpleCoreAttribute(newStringParams("division", false, false)),
+			schema.SimpleCoreAttribute(newStringParams("department", false, false)),
+			schema.ComplexCoreAttribute(newComplexParams("manager", false, false, []schema.SimpleParams{
+				newStringParams("value", false, false),
+				newStringParams("displayName", false, false),
+			})),
+		},
+	}
+}
+
+func getExtensionSchema(extension *object.ScimExtension) schema.Schema {
+	attributes := []schema.CoreAttribute{}
+	for _, attribute := range extension.Attributes {
+		description := optional.NewString(attribute.Description)
+		var params schema.SimpleParams
+		switch attribute.Type {
+		case "boolean":
+			params = schema.SimpleBooleanParams(schema.BooleanParams{Name: attribute.Name, Required: attribute.Required, Description: description})
+		case "integer":
+			params = schema.SimpleNumberParams(schema.NumberParams{Name: attribute.Name, Required: attribute.Required, Description: description, Type: schema.AttributeTypeInteger()})
+		case "decimal":
+			params = schema.SimpleNumberParams(schema.NumberParams{Name: attribute.Name, Required: attribute.Required, Description: description, Type: schema.AttributeTypeDecimal()})
+		case "dateTime":
+			params = schema.SimpleDateTimeParams(schema.DateTimeParams{Name: attribute.Name, Required: attribute.Required, Description: description})
+		default:
+			params = schema.SimpleStringParams(schema.StringParams{Name: attribute.Name, Required: attribute.Required, Description: description})
+		}
+		attributes = append(attributes, schema.SimpleCoreAttribute(params))
+	}
+
+	name := extension.Name
+	if name == "" {
+		name = extension.Id
+	}
+	return schema.Schema{
+		ID:          extension.Id,
+		Name:        optional.NewString(name),
+		Description: optional.NewString(extension.Description),
+		Attributes:  attributes,
+	}
+}
+
+// getExtensionSchemas returns the schemas of the extensions, an extension id shared by several organizations is
+// described by the extension of the first organization in name order
+func getExtensionSchemas(extensions map[string][]*object.ScimExtension) []schema.Schema {
+	owners := []string{}
+	for owner := range extensions {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	ids := map[string]bool{}
+	res := []schema.Schema{}
+	for _, owner := range owners {
+		for _, extension := range extensions[owner] {
+			if !ids[extension.Id] {
+				ids[extension.Id] = true
+				res = append(res, getExtensionSchema(extension))
+			}
+		}
+	}
+	return res
+}
+
+func getAttrValue(attrs map[string]interface{}, key string) (interface{}, bool) {
+	for k, v := range attrs {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func toPropertyValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func setUserProperty(user *object.User, key string, value string) {
+	if user.Properties == nil {
+		user.Properties = map[string]string{}
+	}
+	if value == "" {
+		delete(user.Properties, key)
+	} else {
+		user.Properties[key] = value
+	}
+}
+
+func getEnterpriseUserAttrs(user *object.User) scim.ResourceAttributes {
+	attrs := scim.ResourceAttributes{
+		"organization": user.Owner,
+	}
+	if user.Affiliation != "" {
+		attrs["department"] = user.Affiliation
+	}
+	for _, name := range []string{"employeeNumber", "costCenter", "division"} {
+		if value := user.Properties[name]; value != "" {
+			attrs[name] = value
+		}
+	}
+	if manager := user.Properties["manager"]; manager != "" {
+		attrs["manager"] = scim.ResourceAttributes{"value": manager}
+	}
+	return attrs
+}
+
+// setEnterpriseUserAttrs sets the enterprise attributes present in the value, a nil value removes all of them
+func setEnterpriseUserAttrs(user *object.User, value map[string]interface{}) {
+	for _, name := range EnterpriseUserAttributes {
+		v, ok := getAttrValue(value, name)
+		if ok || value == nil {
+			setEnterpriseUserAttr(user, name, v)
+		}
+	}
+}
+
+func setEnterpriseUserAttr(user *object.User, name string, value interface{}) {
+	if name == "manager" {
+		if manager, ok := value.(map[string]interface{}); ok {
+			value, _ = getAttrValue(manager, "value")
+		}
+	}
+
+	if name == "department" {
+		user.Affiliation = toPropertyValue(value)
+	} else {
+		setUserProperty(user, name, toPropertyValue(value))
+	}
+}
+
+func getExtensionAttrs(user *object.User, extension *object.ScimExtension) scim.ResourceAttributes {
+	attrs := scim.ResourceAttributes{}
+	for _, attribute := range extension.Attributes {
+		value := user.Properties[attribute.GetProperty()]
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch attribute.Type {
+		case "boolean":
+			attrs[attribute.Name], err = strconv.ParseBool(value)
+		case "integer":
+			attrs[attribute.Name], err = strconv.ParseInt(value, 10, 64)
+		case "decimal":
+			attrs[attribute.Name], err = strconv.ParseFloat(value, 64)
+		default:
+			attrs[attribute.Name] = value
+		}
+		if err != nil {
+			delete(attrs, attribute.Name)
+		}
+	}
+	return attrs
+}
+
+// setExtensionAttrs sets the extension attributes present in the value, a nil value removes all of them
+func setExtensionAttrs(user *object.User, extension *object.ScimExtension, value map[string]interface{}) {
+	for _, attribute := range extension.Attributes {
+		v, ok := getAttrValue(value, attribute.Name)
+		if ok || value == nil {
+			setUserProperty(user, attribute.GetProperty(), toPropertyValue(v))
+		}
+	}
+}
+
+// getManagedProperties returns the user properties that are backed by the SCIM attributes
+func getManagedProperties(extensions []*object.ScimExtension) []string {
+	res := []string{"employeeNumber", "costCenter", "division", "manager"}
+	for _, extension := range extensions {
+		for _, attribute := range extension.Attributes {
+			res = append(res, attribute.GetProperty())
+		}
+	}
+	return res
+}
+
+// mergeUserProperties replaces the managed properties of the old properties with the new ones, the other properties,
+// e.g. the ones written by the identity providers, are kept
+func mergeUserProperties(oldProperties map[string]string, newProperties map[string]string, managed []string) map[string]string {
+	res := map[string]string{}
+	for key, value := range oldProperties {
+		res[key] = value
+	}
+	for _, key := range managed {
+		delete(res, key)
+	}
+	for key, value := range newProperties {
+		res[key] = value
+	}
+	return res
+}
+
+// cutExtensionPath returns the attribute path after the extension id, e.g. "manager.value" for
+// "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value"
+func cutExtensionPath(path string, id string) (string, bool) {
+	if strings.EqualFold(path, id) {
+		return "", true
+	}
+	if len(path) > len(id) && strings.EqualFold(path[:len(id)], id) && path[len(id)] == ':' {
+		return path[len(id)+1:], true
+	}
+	return "", false
+}
+
+func toAttributes(value interface{}) map[string]interface{} {
+	if attrs, ok := value.(map[string]interface{}); ok {
+		return attrs
+	}
+	if attrs, ok := value.(AnyMap); ok {
+		return attrs
+	}
+	return nil
+}
+
+// patchUserExtension applies a PATCH operation on an attribute of the enterprise or a custom extension, it returns
+// false if the path doesn't belong to any of the extensions
+func patchUserExtension(user *object.User, path string, value interface{}, extensions []*object.ScimExtension) bool {
+	if subPath, ok := cutExtensionPath(path, UserExtensionKey); ok {
+		name, _, _ := strings.Cut(subPath, ".")
+		for _, attribute := range EnterpriseUserAttributes {
+			if strings.EqualFold(attribute, name) {
+				setEnterpriseUserAttr(user, attribute, value)
+			}
+		}
+		return true
+	}
+
+	for _, extension := range extensions {
+		subPath, ok := cutExtensionPath(path, extension.Id)
+		if !ok {
+			continue
+		}
+		if subPath == "" {
+			setExtensionAttrs(user, extension, toAttributes(value))
+			return true
+		}
+		for _, attribute := range extension.Attributes {
+			if strings.EqualFold(attribute.Name, subPath) {
+				setUserProperty(user, attribute.GetProperty(), toPropertyValue(value))
+			}
+		}
+		return true
+	}
+	return false
+}
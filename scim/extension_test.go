@@ -0,0 +1,87 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/elimity-com/scim"
+	"github.com/stretchr/testify/assert"
+)
+
+var testExtension = &object.ScimExtension{
+	Id:   "urn:example:params:scim:schemas:extension:acme:2.0:User",
+	Name: "AcmeUser",
+	Attributes: []*object.ScimExtensionAttribute{
+		{Name: "badge", Type: "string", Property: "badge_number"},
+		{Name: "contractor", Type: "boolean"},
+		{Name: "level", Type: "integer"},
+	},
+}
+
+func TestUserExtensionAttrs(t *testing.T) {
+	extensions := map[string][]*object.ScimExtension{"org": {testExtension}}
+	attrs := map[string]interface{}{
+		"userName": "alice",
+		UserExtensionKey: map[string]interface{}{
+			"organization":   "org",
+			"employeeNumber": "701984",
+			"department":     "R&D",
+			"manager":        map[string]interface{}{"value": "bob-id"},
+		},
+		testExtension.Id: map[string]interface{}{
+			"badge":      "B-12",
+			"contractor": true,
+			"level":      int64(3),
+		},
+	}
+
+	user, err := resource2user(attrs, extensions)
+	assert.Nil(t, err)
+	assert.Equal(t, "R&D", user.Affiliation)
+	assert.Equal(t, map[string]string{"employeeNumber": "701984", "manager": "bob-id", "badge_number": "B-12", "contractor": "true", "level": "3"}, user.Properties)
+
+	resource := user2resource(user, extensions["org"])
+	assert.Equal(t, "701984", resource.Attributes[UserExtensionKey].(scim.ResourceAttributes)["employeeNumber"])
+	assert.Equal(t, scim.ResourceAttributes{"badge": "B-12", "contractor": true, "level": int64(3)}, resource.Attributes[testExtension.Id])
+
+	assert.True(t, patchUserExtension(user, UserExtensionKey+":manager.value", "carol-id", nil))
+	assert.True(t, patchUserExtension(user, testExtension.Id+":contractor", nil, extensions["org"]))
+	assert.False(t, patchUserExtension(user, "urn:example:unknown:badge", "x", extensions["org"]))
+	assert.Equal(t, "carol-id", user.Properties["manager"])
+	assert.NotContains(t, user.Properties, "contractor")
+
+	merged := mergeUserProperties(map[string]string{"oauth_GitHub_id": "1", "badge_number": "old"}, map[string]string{"level": "4"}, getManagedProperties(extensions["org"]))
+	assert.Equal(t, map[string]string{"oauth_GitHub_id": "1", "level": "4"}, merged)
+}
+
+func TestExtensionSchemas(t *testing.T) {
+	server := GetScimServer(getExtensionSchemas(map[string][]*object.ScimExtension{"org": {testExtension}})...)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/Schemas/"+testExtension.Id, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), `"name":"contractor"`))
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ResourceTypes/User", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), testExtension.Id))
+	assert.True(t, strings.Contains(w.Body.String(), UserExtensionKey))
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elimity-com/scim/errors"
+	"github.com/scim2/filter-parser/v2"
+	"github.com/xorm-io/builder"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.2 Filtering
+// The filterable attributes of a resource are mapped to the columns of its table, the attribute names are case-insensitive
+
+var UserFilterColumns = map[string]string{
+	"id":                 "id",
+	"externalId":         "external_id",
+	"userName":           "name",
+	"displayName":        "display_name",
+	"nickName":           "display_name",
+	"userType":           "type",
+	"profileUrl":         "homepage",
+	"name.givenName":     "first_name",
+	"name.familyName":    "last_name",
+	"emails":             "email",
+	"emails.value":       "email",
+	"phoneNumbers":       "phone",
+	"phoneNumbers.value": "phone",
+	"photos":             "avatar",
+	"photos.value":       "avatar",
+	"addresses.locality": "location",
+	"addresses.region":   "region",
+	"addresses.country":  "country_code",
+	"active":             "is_forbidden",
+	"meta.created":       "created_time",
+	"meta.lastModified":  "updated_time",
+
+	UserExtensionKey + ":organization": "owner",
+}
+
+var GroupFilterColumns = map[string]string{
+	"displayName":       "display_name",
+	"meta.created":      "created_time",
+	"meta.lastModified": "updated_time",
+
+	GroupExtensionKey + ":organization": "owner",
+}
+
+func getAttributeName(path filter.AttributePath, parent string) string {
+	name := path.AttributeName
+	if parent != "" {
+		name = fmt.Sprintf("%s.%s", parent, name)
+	}
+	if path.SubAttribute != nil {
+		name = fmt.Sprintf("%s.%s", name, path.SubAttributeName())
+	}
+	if path.URIPrefix != nil {
+		name = fmt.Sprintf("%s:%s", path.URI(), name)
+	}
+	return name
+}
+
+func getFilterColumn(name string, columns map[string]string) (string, error) {
+	for attribute, column := range columns {
+		if strings.EqualFold(attribute, name) {
+			return column, nil
+		}
+	}
+	return "", errors.ScimErrorBadRequest(fmt.Sprintf("the attribute: %s is not filterable", name))
+}
+
+// getFilterCond translates a SCIM filter expression to a condition on the columns of the resource table
+func getFilterCond(expression filter.Expression, columns map[string]string) (builder.Cond, error) {
+	return getFilterCondWithParent(expression, columns, "")
+}
+
+func getFilterCondWithParent(expression filter.Expression, columns map[string]string, parent string) (builder.Cond, error) {
+	switch e := expression.(type) {
+	case *filter.LogicalExpression:
+		left, err := getFilterCondWithParent(e.Left, columns, parent)
+		if err != nil {
+			return nil, err
+		}
+		right, err := getFilterCondWithParent(e.Right, columns, parent)
+		if err != nil {
+			return nil, err
+		}
+		if e.Operator == filter.OR {
+			return builder.Or(left, right), nil
+		}
+		return builder.And(left, right), nil
+	case *filter.NotExpression:
+		cond, err := getFilterCondWithParent(e.Expression, columns, parent)
+		if err != nil {
+			return nil, err
+		}
+		return builder.Not{cond}, nil
+	case *filter.ValuePath:
+		// e.g. emails[value ew "@example.com"]
+		return getFilterCondWithParent(e.ValueFilter, columns, getAttributeName(e.AttributePath, parent))
+	case *filter.AttributeExpression:
+		column, err := getFilterColumn(getAttributeName(e.AttributePath, parent), columns)
+		if err != nil {
+			return nil, err
+		}
+		if column == "is_forbidden" {
+			return getActiveCond(e)
+		}
+		return getAttributeCond(column, e.Operator, e.CompareValue)
+	default:
+		return nil, errors.ScimErrorInvalidFilter
+	}
+}
+
+// caseInsensitiveColumns are the columns of the attributes which aren't case-exact, they are compared in lower case
+// because the comparison of some databases like PostgreSQL is case-sensitive
+var caseInsensitiveColumns = map[string]bool{
+	"name":  true,
+	"email": true,
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with "!", which is used instead of the backslash because
+// the backslash is quoted differently by MySQL and PostgreSQL
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func getLikeCond(column string, pattern string) builder.Cond {
+	return builder.Expr(fmt.Sprintf("%s LIKE ? ESCAPE '!'", column), pattern)
+}
+
+func getAttributeCond(column string, operator filter.CompareOperator, value interface{}) (builder.Cond, error) {
+	if operator == filter.PR {
+		return builder.And(builder.NotNull{column}, builder.Neq{column: ""}), nil
+	}
+
+	if s, ok := value.(string); ok && caseInsensitiveColumns[column] {
+		column = fmt.Sprintf("LOWER(%s)", column)
+		value = strings.ToLower(s)
+	}
+
+	switch operator {
+	case filter.EQ:
+		return builder.Eq{column: value}, nil
+	case filter.NE:
+		return builder.Neq{column: value}, nil
+	case filter.GT:
+		return builder.Gt{column: value}, nil
+	case filter.GE:
+		return builder.Gte{column: value}, nil
+	case filter.LT:
+		return builder.Lt{column: value}, nil
+	case filter.LE:
+		return builder.Lte{column: value}, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.ScimErrorInvalidFilter
+	}
+	switch operator {
+	case filter.CO:
+		return getLikeCond(column, "%"+likeEscaper.Replace(s)+"%"), nil
+	case filter.SW:
+		return getLikeCond(column, likeEscaper.Replace(s)+"%"), nil
+	case filter.EW:
+		return getLikeCond(column, "%"+likeEscaper.Replace(s)), nil
+	default:
+		return nil, errors.ScimErrorInvalidFilter
+	}
+}
+
+// getActiveCond maps the "active" attribute to the inverse of the forbidden flag
+func getActiveCond(e *filter.AttributeExpression) (builder.Cond, error) {
+	active, ok := e.CompareValue.(bool)
+	if !ok || (e.Operator != filter.EQ && e.Operator != filter.NE) {
+		return nil, errors.ScimErrorInvalidFilter
+	}
+	if e.Operator == filter.NE {
+		active = !active
+	}
+	return builder.Eq{"is_forbidden": !active}, nil
+}
+
+// getSortParams reads the sortBy and sortOrder query parameters, the results are sorted by the created time by default
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.3 Sorting
+func getSortParams(r *http.Request, columns map[string]string) (string, string, error) {
+	sortOrder := "ascend"
+	if strings.EqualFold(r.URL.Query().Get("sortOrder"), "descending") {
+		sortOrder = "descend"
+	}
+
+	sortBy := r.URL.Query().Get("sortBy")
+	if sortBy == "" {
+		return "created_time", sortOrder, nil
+	}
+	column, err := getFilterColumn(sortBy, columns)
+	if err != nil {
+		return "", "", errors.ScimErrorBadParams([]string{"sortBy"})
+	}
+	return column, sortOrder, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/elimity-com/scim"
+	"github.com/scim2/filter-parser/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/builder"
+)
+
+func TestGetFilterCond(t *testing.T) {
+	tests := []struct {
+		filter string
+		sql    string
+		args   []interface{}
+	}{
+		{`userName eq "alice"`, "LOWER(name)=?", []interface{}{"alice"}},
+		{`USERNAME Eq "Alice"`, "LOWER(name)=?", []interface{}{"alice"}},
+		{`emails[value ew "@Example.com"] and active eq true`, "(LOWER(email) LIKE ? ESCAPE '!') AND is_forbidden=?", []interface{}{"%@example.com", false}},
+		{`name.givenName sw "A" or not (userType eq "normal-user" and active eq false)`, "(first_name LIKE ? ESCAPE '!') OR NOT (type=? AND is_forbidden=?)", []interface{}{"A%", "normal-user", true}},
+		{`displayName co "50%_off!"`, "display_name LIKE ? ESCAPE '!'", []interface{}{"%50!%!_off!!%"}},
+		{`meta.lastModified gt "2025-01-01T00:00:00Z"`, "updated_time>?", []interface{}{"2025-01-01T00:00:00Z"}},
+		{`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:organization eq "built-in"`, "owner=?", []interface{}{"built-in"}},
+	}
+
+	for _, test := range tests {
+		expression, err := filter.ParseFilter([]byte(test.filter))
+		assert.Nil(t, err)
+		cond, err := getFilterCond(expression, UserFilterColumns)
+		assert.Nil(t, err)
+		sql, args, err := builder.ToSQL(cond)
+		assert.Nil(t, err)
+		assert.Equal(t, test.sql, sql, test.filter)
+		assert.Equal(t, test.args, args, test.filter)
+	}
+
+	expression, err := filter.ParseFilter([]byte(`password eq "123"`))
+	assert.Nil(t, err)
+	_, err = getFilterCond(expression, UserFilterColumns)
+	assert.NotNil(t, err)
+}
+
+func TestCheckResourceVersion(t *testing.T) {
+	resource := &scim.Resource{ID: "1", Meta: scim.Meta{Version: getResourceVersion(scim.ResourceAttributes{"userName": "alice"})}}
+
+	r, _ := http.NewRequest(http.MethodPut, "/Users/1", nil)
+	assert.Nil(t, checkResourceVersion(r, resource))
+
+	r.Header.Set("If-Match", resource.Meta.Version)
+	assert.Nil(t, checkResourceVersion(r, resource))
+
+	r.Header.Set("If-Match", getResourceVersion(scim.ResourceAttributes{"userName": "bob"}))
+	assert.NotNil(t, checkResourceVersion(r, resource))
+}
@@ -0,0 +1,466 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/errors"
+	"github.com/scim2/filter-parser/v2"
+	"github.com/xorm-io/builder"
+)
+
+type GroupResourceHandler struct{}
+
+// https://datatracker.ietf.org/doc/html/rfc7643#section-4.2 Group resource schema
+// The SCIM id of a group is its Casdoor id "<organization>/<name>", the members are referenced by the SCIM user ids
+
+func (h GroupResourceHandler) Create(r *http.Request, attrs scim.ResourceAttributes) (scim.Resource, error) {
+	err := checkResourceOrganization(r, attrs, GroupExtensionKey)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	resource := &scim.Resource{Attributes: attrs}
+	err = AddScimGroup(resource)
+	return *resource, err
+}
+
+func (h GroupResourceHandler) Get(r *http.Request, id string) (scim.Resource, error) {
+	group, err := getScimGroup(r, id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	if group == nil {
+		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
+	}
+	resource, err := group2resource(group)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	return *resource, nil
+}
+
+func (h GroupResourceHandler) Delete(r *http.Request, id string) error {
+	group, err := getScimGroup(r, id)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return errors.ScimErrorResourceNotFound(id)
+	}
+	err = checkGroupVersion(r, group)
+	if err != nil {
+		return err
+	}
+
+	err = setGroupMembers(group, []string{})
+	if err != nil {
+		return err
+	}
+	_, err = object.DeleteGroup(group)
+	return err
+}
+
+func (h GroupResourceHandler) GetAll(r *http.Request, params scim.ListRequestParams) (scim.Page, error) {
+	var cond builder.Cond
+	if params.Filter != nil {
+		var err error
+		cond, err = getFilterCond(params.Filter, GroupFilterColumns)
+		if err != nil {
+			return scim.Page{}, err
+		}
+	}
+	sortField, sortOrder, err := getSortParams(r, GroupFilterColumns)
+	if err != nil {
+		return scim.Page{}, err
+	}
+
+	owner := getRequestOrganization(r)
+	count, err := object.GetGroupCountWithFilter(owner, cond)
+	if err != nil {
+		return scim.Page{}, err
+	}
+	if params.Count == 0 {
+		return scim.Page{TotalResults: int(count)}, nil
+	}
+
+	resources := make([]scim.Resource, 0)
+	// startIndex is 1-based index
+	groups, err := object.GetPaginationGroupsWithFilter(owner, params.StartIndex-1, params.Count, cond, sortField, sortOrder)
+	if err != nil {
+		return scim.Page{}, err
+	}
+	for _, group := range groups {
+		resource, err := group2resource(group)
+		if err != nil {
+			return scim.Page{}, err
+		}
+		resources = append(resources, *resource)
+	}
+	return scim.Page{
+		TotalResults: int(count),
+		Resources:    resources,
+	}, nil
+}
+
+func (h GroupResourceHandler) Patch(r *http.Request, id string, operations []scim.PatchOperation) (scim.Resource, error) {
+	group, err := getScimGroup(r, id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	if group == nil {
+		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
+	}
+	err = checkGroupVersion(r, group)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	return UpdateScimGroupByPatchOperation(id, operations)
+}
+
+func (h GroupResourceHandler) Replace(r *http.Request, id string, attrs scim.ResourceAttributes) (scim.Resource, error) {
+	group, err := getScimGroup(r, id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	if group == nil {
+		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
+	}
+	err = checkGroupVersion(r, group)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	resource := &scim.Resource{Attributes: attrs}
+	err = UpdateScimGroup(id, resource)
+	return *resource, err
+}
+
+// getScimGroup returns the group with the SCIM id if it belongs to the organization of the request
+func getScimGroup(r *http.Request, id string) (*object.Group, error) {
+	group, err := getGroupByScimId(id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil || !isRequestOrganization(r, group.Owner) {
+		return nil, nil
+	}
+	return group, nil
+}
+
+func getGroupByScimId(id string) (*object.Group, error) {
+	owner, name, err := util.GetOwnerAndNameFromIdWithError(id)
+	if err != nil {
+		return nil, nil
+	}
+	return object.GetGroup(util.GetId(owner, name))
+}
+
+func checkGroupVersion(r *http.Request, group *object.Group) error {
+	resource, err := group2resource(group)
+	if err != nil {
+		return err
+	}
+	return checkResourceVersion(r, resource)
+}
+
+func GetScimGroup(id string) (*scim.Resource, error) {
+	group, err := getGroupByScimId(id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, nil
+	}
+	return group2resource(group)
+}
+
+func AddScimGroup(r *scim.Resource) error {
+	newGroup, memberIds, err := resource2group(r.Attributes)
+	if err != nil {
+		return err
+	}
+	members, err := getGroupMemberUsers(memberIds)
+	if err != nil {
+		return err
+	}
+	if newGroup.Owner == "" && len(members) > 0 {
+		newGroup.Owner = members[0].Owner
+	}
+	if newGroup.Owner == "" {
+		return errors.ScimErrorBadRequest(fmt.Sprintf("organization in %s is required", GroupExtensionKey))
+	}
+	newGroup.ParentId = newGroup.Owner
+
+	// Check whether the group exists.
+	oldGroup, err := object.GetGroup(newGroup.GetId())
+	if err != nil {
+		return err
+	}
+	if oldGroup != nil {
+		return errors.ScimErrorUniqueness
+	}
+
+	affect, err := object.AddGroup(newGroup)
+	if err != nil {
+		return err
+	}
+	if !affect {
+		return fmt.Errorf("add new group failed")
+	}
+
+	err = setGroupMembers(newGroup, memberIds)
+	if err != nil {
+		return err
+	}
+
+	resource, err := group2resource(newGroup)
+	if err != nil {
+		return err
+	}
+	*r = *resource
+	return nil
+}
+
+func UpdateScimGroup(id string, r *scim.Resource) error {
+	group, err := getGroupByScimId(id)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return errors.ScimErrorResourceNotFound(id)
+	}
+	newGroup, memberIds, err := resource2group(r.Attributes)
+	if err != nil {
+		return err
+	}
+
+	// The name is the identifier of the group, so only the display name is replaced
+	group.DisplayName = newGroup.DisplayName
+	group.UpdatedTime = util.GetCurrentTime()
+	_, err = object.UpdateGroup(group.GetId(), group)
+	if err != nil {
+		return err
+	}
+
+	err = setGroupMembers(group, memberIds)
+	if err != nil {
+		return err
+	}
+
+	resource, err := group2resource(group)
+	if err != nil {
+		return err
+	}
+	*r = *resource
+	return nil
+}
+
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2 Modifying with PATCH
+func UpdateScimGroupByPatchOperation(id string, ops []scim.PatchOperation) (r scim.Resource, err error) {
+	group, err := getGroupByScimId(id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	if group == nil {
+		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid patch op value: %v", r)
+		}
+	}()
+
+	memberIds, err := getGroupMemberIds(group)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	for _, op := range ops {
+		if op.Path == nil {
+			// e.g. {"op": "replace", "value": {"displayName": "Engineering", "members": [{"value": "..."}]}}
+			for key, value := range ToAnyMap(op.Value) {
+				switch key {
+				case "displayName":
+					group.DisplayName = ToString(value, group.DisplayName)
+				case "members":
+					memberIds = patchGroupMemberIds(memberIds, op.Op, getMemberIds(value))
+				}
+			}
+			continue
+		}
+
+		switch op.Path.AttributePath.AttributeName {
+		case "displayName":
+			if op.Op != scim.PatchOperationRemove {
+				group.DisplayName = ToString(op.Value, group.DisplayName)
+			}
+		case "members":
+			if op.Path.ValueExpression != nil {
+				// e.g. members[value eq "2819c223-7f76-453a-919d-413861904646"]
+				memberId, err := getMemberFilterValue(op.Path.ValueExpression)
+				if err != nil {
+					return scim.Resource{}, err
+				}
+				if op.Op == scim.PatchOperationRemove {
+					memberIds = util.DeleteVal(memberIds, memberId)
+				}
+				continue
+			}
+			if op.Op == scim.PatchOperationRemove && op.Value == nil {
+				memberIds = []string{}
+				continue
+			}
+			memberIds = patchGroupMemberIds(memberIds, op.Op, getMemberIds(op.Value))
+		}
+	}
+
+	group.UpdatedTime = util.GetCurrentTime()
+	_, err = object.UpdateGroup(group.GetId(), group)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	err = setGroupMembers(group, memberIds)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	resource, err := group2resource(group)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	return *resource, nil
+}
+
+func patchGroupMemberIds(memberIds []string, op string, values []string) []string {
+	switch op {
+	case scim.PatchOperationReplace:
+		return values
+	case scim.PatchOperationRemove:
+		res := []string{}
+		for _, memberId := range memberIds {
+			if !util.InSlice(values, memberId) {
+				res = append(res, memberId)
+			}
+		}
+		return res
+	default:
+		for _, value := range values {
+			if !util.InSlice(memberIds, value) {
+				memberIds = append(memberIds, value)
+			}
+		}
+		return memberIds
+	}
+}
+
+func getMemberIds(value interface{}) []string {
+	res := []string{}
+	for _, member := range ToAnyArray(value, AnyArray{}) {
+		res = append(res, ToString(ToAnyMap(member)["value"], ""))
+	}
+	return res
+}
+
+func getMemberFilterValue(expression filter.Expression) (string, error) {
+	e, ok := expression.(*filter.AttributeExpression)
+	if !ok || e.AttributePath.AttributeName != "value" || e.Operator != filter.EQ {
+		return "", errors.ScimErrorInvalidFilter
+	}
+	value, ok := e.CompareValue.(string)
+	if !ok {
+		return "", errors.ScimErrorInvalidFilter
+	}
+	return value, nil
+}
+
+func getGroupMemberUsers(memberIds []string) ([]*object.User, error) {
+	users := []*object.User{}
+	for _, memberId := range memberIds {
+		user, err := object.GetUserByUserIdOnly(memberId)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, errors.ScimErrorBadRequest(fmt.Sprintf("the member: %s is not found", memberId))
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func getGroupMemberIds(group *object.Group) ([]string, error) {
+	users, err := object.GetGroupUsers(group.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	memberIds := []string{}
+	for _, user := range users {
+		memberIds = append(memberIds, user.Id)
+	}
+	return memberIds, nil
+}
+
+// setGroupMembers makes the given users the only members of the group by updating their groups
+func setGroupMembers(group *object.Group, memberIds []string) error {
+	members, err := getGroupMemberUsers(memberIds)
+	if err != nil {
+		return err
+	}
+	for _, user := range members {
+		if user.Owner != group.Owner {
+			return errors.ScimErrorBadRequest(fmt.Sprintf("the member: %s doesn't belong to the organization: %s", user.Id, group.Owner))
+		}
+	}
+
+	groupId := group.GetId()
+	oldMembers, err := object.GetGroupUsers(groupId)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range oldMembers {
+		if util.InSlice(memberIds, user.Id) {
+			continue
+		}
+		user.Groups = util.DeleteVal(user.Groups, groupId)
+		_, err = object.UpdateUser(user.GetId(), user, []string{"groups"}, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, user := range members {
+		if util.InSlice(user.Groups, groupId) {
+			continue
+		}
+		user.Groups = append(user.Groups, groupId)
+		_, err = object.UpdateUser(user.GetId(), user, []string{"groups"}, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getGroupName(displayName string) string {
+	return strings.ReplaceAll(strings.TrimSpace(displayName), "/", "_")
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"testing"
+
+	"github.com/elimity-com/scim"
+	"github.com/scim2/filter-parser/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchGroupMemberIds(t *testing.T) {
+	memberIds := []string{"a", "b"}
+	assert.Equal(t, []string{"a", "b", "c"}, patchGroupMemberIds(memberIds, scim.PatchOperationAdd, []string{"b", "c"}))
+	assert.Equal(t, []string{"b"}, patchGroupMemberIds([]string{"a", "b"}, scim.PatchOperationRemove, []string{"a"}))
+	assert.Equal(t, []string{"c"}, patchGroupMemberIds([]string{"a", "b"}, scim.PatchOperationReplace, []string{"c"}))
+
+	value := []interface{}{map[string]interface{}{"value": "a"}, map[string]interface{}{"value": "c"}}
+	assert.Equal(t, []string{"a", "c"}, getMemberIds(value))
+}
+
+func TestGroupMemberFilter(t *testing.T) {
+	path, err := filter.ParsePath([]byte(`members[value eq "2819c223"]`))
+	assert.Nil(t, err)
+	memberId, err := getMemberFilterValue(path.ValueExpression)
+	assert.Nil(t, err)
+	assert.Equal(t, "2819c223", memberId)
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/errors"
+)
+
+// A SCIM request of an organization admin is scoped to the organization of the admin, the requests of the global
+// admins are not scoped and can access the resources of all organizations
+
+type organizationKey struct{}
+
+var errOrganizationForbidden = errors.ScimError{
+	Detail: "the resource belongs to another organization",
+	Status: http.StatusForbidden,
+}
+
+func withRequestOrganization(r *http.Request, owner string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), organizationKey{}, owner))
+}
+
+func getRequestOrganization(r *http.Request) string {
+	owner, _ := r.Context().Value(organizationKey{}).(string)
+	return owner
+}
+
+func isRequestOrganization(r *http.Request, owner string) bool {
+	requestOwner := getRequestOrganization(r)
+	return requestOwner == "" || requestOwner == owner
+}
+
+// checkResourceOrganization defaults the organization of a new or replaced resource to the organization of the request
+func checkResourceOrganization(r *http.Request, attrs scim.ResourceAttributes, extensionKey string) error {
+	owner := getRequestOrganization(r)
+	if owner == "" {
+		return nil
+	}
+
+	extension, ok := attrs[extensionKey].(map[string]interface{})
+	if !ok {
+		extension = map[string]interface{}{}
+		attrs[extensionKey] = extension
+	}
+	organization, _ := extension["organization"].(string)
+	if organization == "" {
+		extension["organization"] = owner
+	} else if organization != owner {
+		return errOrganizationForbidden
+	}
+	return nil
+}
+
+// isOrganizationPatched returns whether the operations change the organization attribute of the extension
+func isOrganizationPatched(ops []scim.PatchOperation, extensionKey string) bool {
+	for _, op := range ops {
+		if op.Path != nil {
+			if isOrganizationAttribute(op.Path.String(), op.Value, extensionKey) {
+				return true
+			}
+			continue
+		}
+
+		if value, ok := op.Value.(map[string]interface{}); ok {
+			for key, v := range value {
+				if isOrganizationAttribute(key, v, extensionKey) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isOrganizationAttribute(path string, value interface{}, extensionKey string) bool {
+	if strings.EqualFold(path, extensionKey+".organization") {
+		return true
+	}
+
+	subPath, ok := cutExtensionPath(path, extensionKey)
+	if !ok {
+		return false
+	}
+	if subPath == "" {
+		_, ok = getAttrValue(toAttributes(value), "organization")
+		return ok
+	}
+	return strings.EqualFold(subPath, "organization")
+}
@@ -15,7 +15,14 @@
 package scim
 
 import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
 	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/errors"
 	"github.com/elimity-com/scim/optional"
 	"github.com/elimity-com/scim/schema"
 )
@@ -64,7 +71,10 @@ Example JSON user resource
 */
 
 const (
-	UserExtensionKey = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	UserExtensionKey  = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	GroupExtensionKey = "urn:ietf:params:scim:schemas:extension:casdoor:2.0:Group"
+
+	MaxResults = 100
 )
 
 var (
@@ -96,13 +106,34 @@ var (
 			newStringParams("country", false, false),
 		}),
 	}
+	GroupStringField = []schema.SimpleParams{
+		newStringParams("displayName", true, false),
+	}
+	GroupComplexField = []schema.ComplexParams{
+		newComplexParams("members", false, true, []schema.SimpleParams{
+			newStringParams("value", true, false),
+			newStringParams("display", false, false),
+			newStringParams("type", false, false),
+		}),
+	}
 	Server = GetScimServer()
 )
 
-func GetScimServer() scim.Server {
+// GetScimServer returns the SCIM server, the user extensions are the custom schema extensions of the User resource
+func GetScimServer(userExtensions ...schema.Schema) scim.Server {
 	config := scim.ServiceProviderConfig{
 		// DocumentationURI: optional.NewString("www.example.com/scim"),
-		SupportPatch: true,
+		AuthenticationSchemes: []scim.AuthenticationScheme{
+			{
+				Type:        scim.AuthenticationTypeOauthBearerToken,
+				Name:        "OAuth Bearer Token",
+				Description: "Authentication with the access token of an administrator",
+				Primary:     true,
+			},
+		},
+		MaxResults:       MaxResults,
+		SupportFiltering: true,
+		SupportPatch:     true,
 	}
 
 	codeAttrs := make([]schema.CoreAttribute, 0, len(UserStringField)+len(UserComplexField))
@@ -120,29 +151,59 @@ func GetScimServer() scim.Server {
 		Attributes:  codeAttrs,
 	}
 
-	extension := schema.Schema{
-		ID:          UserExtensionKey,
-		Name:        optional.NewString("EnterpriseUser"),
-		Description: optional.NewString("Enterprise User"),
+	userSchemaExtensions := []scim.SchemaExtension{
+		{Schema: getEnterpriseUserSchema()},
+	}
+	for _, extension := range userExtensions {
+		userSchemaExtensions = append(userSchemaExtensions, scim.SchemaExtension{Schema: extension})
+	}
+
+	groupAttrs := make([]schema.CoreAttribute, 0, len(GroupStringField)+len(GroupComplexField))
+	for _, field := range GroupStringField {
+		groupAttrs = append(groupAttrs, schema.SimpleCoreAttribute(field))
+	}
+	for _, field := range GroupComplexField {
+		groupAttrs = append(groupAttrs, schema.ComplexCoreAttribute(field))
+	}
+
+	groupSchema := schema.Schema{
+		ID:          schema.GroupSchema,
+		Name:        optional.NewString("Group"),
+		Description: optional.NewString("Group"),
+		Attributes:  groupAttrs,
+	}
+
+	groupExtension := schema.Schema{
+		ID:          GroupExtensionKey,
+		Name:        optional.NewString("CasdoorGroup"),
+		Description: optional.NewString("Casdoor Group"),
 		Attributes: []schema.CoreAttribute{
 			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
-				Name:     "organization",
-				Required: true,
+				Name: "organization",
 			})),
 		},
 	}
 
 	resourceTypes := []scim.ResourceType{
 		{
-			ID:          optional.NewString("User"),
-			Name:        "User",
-			Endpoint:    "/Users",
-			Description: optional.NewString("User Account in Casdoor"),
-			Schema:      userSchema,
+			ID:               optional.NewString("User"),
+			Name:             "User",
+			Endpoint:         "/Users",
+			Description:      optional.NewString("User Account in Casdoor"),
+			Schema:           userSchema,
+			SchemaExtensions: userSchemaExtensions,
+			Handler:          UserResourceHandler{},
+		},
+		{
+			ID:          optional.NewString("Group"),
+			Name:        "Group",
+			Endpoint:    "/Groups",
+			Description: optional.NewString("Group in Casdoor"),
+			Schema:      groupSchema,
 			SchemaExtensions: []scim.SchemaExtension{
-				{Schema: extension},
+				{Schema: groupExtension},
 			},
-			Handler: UserResourceHandler{},
+			Handler: GroupResourceHandler{},
 		},
 	}
 
@@ -152,3 +213,86 @@ func GetScimServer() scim.Server {
 	}
 	return server
 }
+
+// HandleRequest serves a SCIM request scoped to the organization of the requesting admin, an empty owner means that the
+// request is made by a global admin. The bulk operations and the capabilities that the underlying server doesn't
+// implement are handled here. The User resource of the request includes the custom schema extensions of the
+// organizations that the admin can access.
+func HandleRequest(w http.ResponseWriter, r *http.Request, owner string) {
+	extensions, err := object.GetScimExtensions(owner)
+	if err != nil {
+		writeScimError(w, errors.ScimError{Detail: err.Error(), Status: http.StatusInternalServerError})
+		return
+	}
+
+	r = withRequestOrganization(r, owner)
+	r = withRequestExtensions(r, extensions)
+	server := Server
+	if len(extensions) != 0 {
+		server = GetScimServer(getExtensionSchemas(extensions)...)
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2")
+	switch {
+	case path == "/ServiceProviderConfig" && r.Method == http.MethodGet:
+		writeScimResponse(w, http.StatusOK, getServiceProviderConfig(server.Config))
+	case path == "/Bulk" && r.Method == http.MethodPost:
+		handleBulk(w, r, server)
+	default:
+		server.ServeHTTP(w, r)
+	}
+}
+
+// https://datatracker.ietf.org/doc/html/rfc7643#section-5 Service Provider Configuration Schema
+func getServiceProviderConfig(config scim.ServiceProviderConfig) map[string]interface{} {
+	authenticationSchemes := []map[string]interface{}{}
+	for _, scheme := range config.AuthenticationSchemes {
+		authenticationSchemes = append(authenticationSchemes, map[string]interface{}{
+			"type":        scheme.Type,
+			"name":        scheme.Name,
+			"description": scheme.Description,
+			"primary":     scheme.Primary,
+		})
+	}
+
+	return map[string]interface{}{
+		"schemas": []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch": map[string]bool{
+			"supported": config.SupportPatch,
+		},
+		"bulk": map[string]interface{}{
+			"supported":      true,
+			"maxOperations":  BulkMaxOperations,
+			"maxPayloadSize": BulkMaxPayloadSize,
+		},
+		"filter": map[string]interface{}{
+			"supported":  config.SupportFiltering,
+			"maxResults": config.MaxResults,
+		},
+		"changePassword": map[string]bool{
+			"supported": true,
+		},
+		"sort": map[string]bool{
+			"supported": true,
+		},
+		"etag": map[string]bool{
+			"supported": true,
+		},
+		"authenticationSchemes": authenticationSchemes,
+	}
+}
+
+func writeScimResponse(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	if err != nil {
+		log.Printf("failed writing response: %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/casdoor/casdoor/object"
 	"github.com/elimity-com/scim"
 	"github.com/elimity-com/scim/errors"
+	"github.com/xorm-io/builder"
 )
 
 type UserResourceHandler struct{}
@@ -29,83 +30,133 @@ type UserResourceHandler struct{}
 // https://datatracker.ietf.org/doc/html/rfc7644#section-3.4 How to query/update resources
 
 func (h UserResourceHandler) Create(r *http.Request, attrs scim.ResourceAttributes) (scim.Resource, error) {
+	err := checkResourceOrganization(r, attrs, UserExtensionKey)
+	if err != nil {
+		return scim.Resource{}, err
+	}
 	resource := &scim.Resource{Attributes: attrs}
-	err := AddScimUser(resource)
+	err = AddScimUser(resource, getRequestExtensions(r))
 	return *resource, err
 }
 
 func (h UserResourceHandler) Get(r *http.Request, id string) (scim.Resource, error) {
-	resource, err := GetScimUser(id)
+	user, err := getScimUser(r, id)
 	if err != nil {
 		return scim.Resource{}, err
 	}
-	if resource == nil {
+	if user == nil {
 		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
 	}
-	return *resource, nil
+	return *user2resource(user, getRequestExtensions(r)[user.Owner]), nil
 }
 
 func (h UserResourceHandler) Delete(r *http.Request, id string) error {
-	user, err := object.GetUserByUserIdOnly(id)
+	user, err := getScimUser(r, id)
 	if err != nil {
 		return err
 	}
 	if user == nil {
 		return errors.ScimErrorResourceNotFound(id)
 	}
+	err = checkResourceVersion(r, user2resource(user, getRequestExtensions(r)[user.Owner]))
+	if err != nil {
+		return err
+	}
 	_, err = object.DeleteUser(user)
 	return err
 }
 
 func (h UserResourceHandler) GetAll(r *http.Request, params scim.ListRequestParams) (scim.Page, error) {
-	if params.Count == 0 {
-		count, err := object.GetGlobalUserCount("", "")
+	var cond builder.Cond
+	if params.Filter != nil {
+		var err error
+		cond, err = getFilterCond(params.Filter, UserFilterColumns)
 		if err != nil {
 			return scim.Page{}, err
 		}
+	}
+	sortField, sortOrder, err := getSortParams(r, UserFilterColumns)
+	if err != nil {
+		return scim.Page{}, err
+	}
+
+	owner := getRequestOrganization(r)
+	count, err := object.GetUserCountWithFilter(owner, cond)
+	if err != nil {
+		return scim.Page{}, err
+	}
+	if params.Count == 0 {
 		return scim.Page{TotalResults: int(count)}, nil
 	}
 
+	extensions := getRequestExtensions(r)
 	resources := make([]scim.Resource, 0)
 	// startIndex is 1-based index
-	users, err := object.GetPaginationGlobalUsers(params.StartIndex-1, params.Count, "", "", "", "")
+	users, err := object.GetPaginationUsersWithFilter(owner, params.StartIndex-1, params.Count, cond, sortField, sortOrder)
 	if err != nil {
 		return scim.Page{}, err
 	}
 	for _, user := range users {
-		resources = append(resources, *user2resource(user))
+		resources = append(resources, *user2resource(user, extensions[user.Owner]))
 	}
 	return scim.Page{
-		TotalResults: len(resources),
+		TotalResults: int(count),
 		Resources:    resources,
 	}, nil
 }
 
 func (h UserResourceHandler) Patch(r *http.Request, id string, operations []scim.PatchOperation) (scim.Resource, error) {
-	user, err := object.GetUserByUserIdOnly(id)
+	user, err := getScimUser(r, id)
 	if err != nil {
 		return scim.Resource{}, err
 	}
 	if user == nil {
 		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
 	}
-	return UpdateScimUserByPatchOperation(id, operations)
+	err = checkResourceVersion(r, user2resource(user, getRequestExtensions(r)[user.Owner]))
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	if getRequestOrganization(r) != "" && isOrganizationPatched(operations, UserExtensionKey) {
+		return scim.Resource{}, errOrganizationForbidden
+	}
+	return UpdateScimUserByPatchOperation(id, operations, getRequestExtensions(r))
 }
 
 func (h UserResourceHandler) Replace(r *http.Request, id string, attrs scim.ResourceAttributes) (scim.Resource, error) {
-	user, err := object.GetUserByUserIdOnly(id)
+	user, err := getScimUser(r, id)
 	if err != nil {
 		return scim.Resource{}, err
 	}
 	if user == nil {
 		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
 	}
+	err = checkResourceVersion(r, user2resource(user, getRequestExtensions(r)[user.Owner]))
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	err = checkResourceOrganization(r, attrs, UserExtensionKey)
+	if err != nil {
+		return scim.Resource{}, err
+	}
 	resource := &scim.Resource{Attributes: attrs}
-	err = UpdateScimUser(id, resource)
+	err = UpdateScimUser(id, resource, getRequestExtensions(r))
 	return *resource, err
 }
 
-func GetScimUser(id string) (*scim.Resource, error) {
+// getScimUser returns the user with the SCIM id if it belongs to the organization of the request
+func getScimUser(r *http.Request, id string) (*object.User, error) {
+	user, err := object.GetUserByUserIdOnly(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !isRequestOrganization(r, user.Owner) {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func GetScimUser(id string, extensions map[string][]*object.ScimExtension) (*scim.Resource, error) {
 	user, err := object.GetUserByUserIdOnly(id)
 	if err != nil {
 		return nil, err
@@ -113,12 +164,12 @@ func GetScimUser(id string) (*scim.Resource, error) {
 	if user == nil {
 		return nil, nil
 	}
-	r := user2resource(user)
+	r := user2resource(user, extensions[user.Owner])
 	return r, nil
 }
 
-func AddScimUser(r *scim.Resource) error {
-	newUser, err := resource2user(r.Attributes)
+func AddScimUser(r *scim.Resource, extensions map[string][]*object.ScimExtension) error {
+	newUser, err := resource2user(r.Attributes, extensions)
 	if err != nil {
 		return err
 	}
@@ -140,14 +191,11 @@ func AddScimUser(r *scim.Resource) error {
 		return fmt.Errorf("add new user failed")
 	}
 
-	r.Attributes = user2resource(newUser).Attributes
-	r.ID = newUser.Id
-	r.ExternalID = buildExternalId(newUser)
-	r.Meta = buildMeta(newUser)
+	*r = *user2resource(newUser, extensions[newUser.Owner])
 	return nil
 }
 
-func UpdateScimUser(id string, r *scim.Resource) error {
+func UpdateScimUser(id string, r *scim.Resource, extensions map[string][]*object.ScimExtension) error {
 	oldUser, err := object.GetUserByUserIdOnly(id)
 	if err != nil {
 		return err
@@ -155,23 +203,28 @@ func UpdateScimUser(id string, r *scim.Resource) error {
 	if oldUser == nil {
 		return errors.ScimErrorResourceNotFound(id)
 	}
-	newUser, err := resource2user(r.Attributes)
+	newUser, err := resource2user(r.Attributes, extensions)
 	if err != nil {
 		return err
 	}
+	// The groups of a user are managed through the Group resource
+	newUser.Groups = oldUser.Groups
+	newUser.Properties = mergeUserProperties(oldUser.Properties, newUser.Properties, getManagedProperties(extensions[newUser.Owner]))
 	_, err = object.UpdateUser(oldUser.GetId(), newUser, nil, true)
 	if err != nil {
 		return err
 	}
 
-	r.ID = newUser.Id
-	r.ExternalID = buildExternalId(newUser)
-	r.Meta = buildMeta(newUser)
+	user, err := object.GetUserByUserIdOnly(oldUser.Id)
+	if err != nil {
+		return err
+	}
+	*r = *user2resource(user, extensions[user.Owner])
 	return nil
 }
 
 // https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2 Modifying with PATCH
-func UpdateScimUserByPatchOperation(id string, ops []scim.PatchOperation) (r scim.Resource, err error) {
+func UpdateScimUserByPatchOperation(id string, ops []scim.PatchOperation, extensions map[string][]*object.ScimExtension) (r scim.Resource, err error) {
 	user, err := object.GetUserByUserIdOnly(id)
 	if err != nil {
 		return scim.Resource{}, err
@@ -247,14 +300,17 @@ func UpdateScimUserByPatchOperation(id string, ops []scim.PatchOperation) (r sci
 			defaultV := AnyMap{"organization": user.Owner}
 			v := ToAnyMap(value, defaultV) // e.g. {"organization": "org1"}
 			user.Owner = ToString(v["organization"], user.Owner)
-		case fmt.Sprintf("%v.%v", UserExtensionKey, "organization"):
+			setEnterpriseUserAttrs(user, toAttributes(value))
+		case fmt.Sprintf("%v.%v", UserExtensionKey, "organization"), fmt.Sprintf("%v:%v", UserExtensionKey, "organization"):
 			user.Owner = ToString(value, user.Owner)
+		default:
+			patchUserExtension(user, op.Path.String(), value, extensions[user.Owner])
 		}
 	}
 	_, err = object.UpdateUser(old, user, nil, true)
 	if err != nil {
 		return scim.Resource{}, err
 	}
-	r = *user2resource(user)
+	r = *user2resource(user, extensions[user.Owner])
 	return r, nil
 }
@@ -15,12 +15,16 @@
 package scim
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/casdoor/casdoor/object"
 	"github.com/casdoor/casdoor/util"
 	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/errors"
 	"github.com/elimity-com/scim/optional"
 	"github.com/elimity-com/scim/schema"
 )
@@ -93,16 +97,53 @@ func buildExternalId(user *object.User) optional.String {
 	}
 }
 
-func buildMeta(user *object.User) scim.Meta {
-	createdTime := util.String2Time(user.CreatedTime)
-	updatedTime := util.String2Time(user.UpdatedTime)
-	if user.UpdatedTime == "" {
+func buildMeta(user *object.User, attrs scim.ResourceAttributes) scim.Meta {
+	return newMeta(user.CreatedTime, user.UpdatedTime, attrs)
+}
+
+func buildGroupMeta(group *object.Group, attrs scim.ResourceAttributes) scim.Meta {
+	return newMeta(group.CreatedTime, group.UpdatedTime, attrs)
+}
+
+func newMeta(created string, updated string, attrs scim.ResourceAttributes) scim.Meta {
+	createdTime := util.String2Time(created)
+	updatedTime := util.String2Time(updated)
+	if updated == "" {
 		updatedTime = createdTime
 	}
 	return scim.Meta{
 		Created:      &createdTime,
 		LastModified: &updatedTime,
-		Version:      util.Time2String(updatedTime),
+		Version:      getResourceVersion(attrs),
+	}
+}
+
+// getResourceVersion returns a weak entity tag of the attributes, so that it changes with every visible modification
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.14 Versioning Resources
+func getResourceVersion(attrs scim.ResourceAttributes) string {
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("W/\"%s\"", util.GetMd5Hash(string(data)))
+}
+
+func checkResourceVersion(r *http.Request, resource *scim.Resource) error {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil
+	}
+
+	version := strings.TrimPrefix(resource.Meta.Version, "W/")
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == version {
+			return nil
+		}
+	}
+	return errors.ScimError{
+		Detail: fmt.Sprintf("the version of the resource: %s doesn't match %s", resource.ID, ifMatch),
+		Status: http.StatusPreconditionFailed,
 	}
 }
 
@@ -141,7 +182,7 @@ func getAttrJsonValue(attrs scim.ResourceAttributes, key1 string, key2 string) s
 	}
 }
 
-func user2resource(user *object.User) *scim.Resource {
+func user2resource(user *object.User, extensions []*object.ScimExtension) *scim.Resource {
 	attrs := make(map[string]interface{})
 	// Singular attributes
 	attrs["userName"] = user.Name
@@ -189,20 +230,34 @@ func user2resource(user *object.User) *scim.Resource {
 		},
 	}
 
+	groups := []scim.ResourceAttributes{}
+	for _, groupId := range user.Groups {
+		groups = append(groups, scim.ResourceAttributes{
+			"value": groupId,
+			"type":  "direct",
+		})
+	}
+	attrs["groups"] = groups
+
 	// Enterprise user schema extension
-	attrs[UserExtensionKey] = scim.ResourceAttributes{
-		"organization": user.Owner,
+	attrs[UserExtensionKey] = getEnterpriseUserAttrs(user)
+
+	// Custom schema extensions of the organization
+	for _, extension := range extensions {
+		if extensionAttrs := getExtensionAttrs(user, extension); len(extensionAttrs) != 0 {
+			attrs[extension.Id] = extensionAttrs
+		}
 	}
 
 	return &scim.Resource{
 		ID:         user.Id,
 		ExternalID: buildExternalId(user),
 		Attributes: attrs,
-		Meta:       buildMeta(user),
+		Meta:       buildMeta(user, attrs),
 	}
 }
 
-func resource2user(attrs scim.ResourceAttributes) (user *object.User, err error) {
+func resource2user(attrs scim.ResourceAttributes, extensions map[string][]*object.ScimExtension) (user *object.User, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("failed to parse attrs: %v", r)
@@ -226,6 +281,7 @@ func resource2user(attrs scim.ResourceAttributes) (user *object.User, err error)
 		Location:    getAttrJsonValue(attrs, "addresses", "locality"),
 		Region:      getAttrJsonValue(attrs, "addresses", "region"),
 		CountryCode: getAttrJsonValue(attrs, "addresses", "country"),
+		Properties:  map[string]string{},
 
 		CreatedTime: util.GetCurrentTime(),
 		UpdatedTime: util.GetCurrentTime(),
@@ -233,6 +289,68 @@ func resource2user(attrs scim.ResourceAttributes) (user *object.User, err error)
 
 	if user.Owner == "" {
 		err = fmt.Errorf("organization in %s is required", UserExtensionKey)
+		return
+	}
+
+	setEnterpriseUserAttrs(user, getAttrJson(attrs, UserExtensionKey))
+	for _, extension := range extensions[user.Owner] {
+		setExtensionAttrs(user, extension, getAttrJson(attrs, extension.Id))
+	}
+	return
+}
+
+func group2resource(group *object.Group) (*scim.Resource, error) {
+	users, err := object.GetGroupUsers(group.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	members := []scim.ResourceAttributes{}
+	for _, user := range users {
+		members = append(members, scim.ResourceAttributes{
+			"value":   user.Id,
+			"display": user.Name,
+			"type":    "User",
+		})
+	}
+
+	attrs := make(map[string]interface{})
+	attrs["displayName"] = group.DisplayName
+	attrs["members"] = members
+	attrs[GroupExtensionKey] = scim.ResourceAttributes{
+		"organization": group.Owner,
+	}
+
+	return &scim.Resource{
+		ID:         group.GetId(),
+		Attributes: attrs,
+		Meta:       buildGroupMeta(group, attrs),
+	}, nil
+}
+
+func resource2group(attrs scim.ResourceAttributes) (group *object.Group, memberIds []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("failed to parse attrs: %v", r)
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	displayName := getAttrString(attrs, "displayName")
+	group = &object.Group{
+		Owner:       getAttrJsonValue(attrs, GroupExtensionKey, "organization"),
+		Name:        getGroupName(displayName),
+		DisplayName: displayName,
+		Type:        "Virtual",
+		IsTopGroup:  true,
+		IsEnabled:   true,
+
+		CreatedTime: util.GetCurrentTime(),
+		UpdatedTime: util.GetCurrentTime(),
+	}
+	memberIds = getMemberIds(attrs["members"])
+
+	if group.Name == "" {
+		err = fmt.Errorf("displayName is required")
 	}
 	return
 }
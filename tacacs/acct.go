@@ -0,0 +1,108 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tacacs
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	acctFlagStart    = 0x02
+	acctFlagStop     = 0x04
+	acctFlagWatchdog = 0x08
+)
+
+const (
+	acctStatusSuccess = 0x01
+	acctStatusError   = 0x02
+)
+
+func acctReply(status byte, serverMsg string) []byte {
+	w := &fieldWriter{}
+	w.uint16(len(serverMsg))
+	w.uint16(0)
+	w.byte(status)
+	w.bytes([]byte(serverMsg))
+	return w.b
+}
+
+func (c *tacacsConn) getAccountingFromRequest(req *request) *object.TacacsAccounting {
+	organization, name, err := getOrganizationAndName(req.User)
+	if err != nil {
+		organization, name = "", req.User
+	}
+
+	ta := &object.TacacsAccounting{
+		Owner:       organization,
+		Name:        "ta_" + util.GenerateId()[:6],
+		CreatedTime: time.Now(),
+
+		Username: name,
+		NasAddr:  c.getNasAddr(),
+		Port:     req.Port,
+		RemAddr:  req.RemAddr,
+		Service:  getArgValue(req.Args, "service"),
+		TaskId:   getArgValue(req.Args, "task_id"),
+		PrivLvl:  int(req.PrivLvl),
+		Command:  getCommand(req.Args),
+	}
+	ta.ElapsedTime, _ = strconv.ParseInt(getArgValue(req.Args, "elapsed_time"), 10, 64)
+	return ta
+}
+
+// handleAcct stores the start, stop and watchdog records of a task, which are merged by task id
+func (c *tacacsConn) handleAcct(body []byte) ([]byte, error) {
+	r := &fieldReader{b: body}
+	flags := r.byte()
+	req, err := parseRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	newTa := c.getAccountingFromRequest(req)
+	oldTa, err := object.GetTacacsAccountingByTaskId(newTa.NasAddr, newTa.TaskId)
+	if err != nil {
+		log.Printf("handleAcct() failed, err = %v", err)
+		return acctReply(acctStatusError, "Accounting error"), nil
+	}
+
+	switch {
+	case flags&acctFlagStart != 0 || oldTa == nil:
+		newTa.StartTime = time.Now()
+		if flags&acctFlagStop != 0 {
+			newTa.StopTime = newTa.StartTime
+		}
+		err = object.AddTacacsAccounting(newTa)
+	case flags&acctFlagStop != 0:
+		oldTa.StopTime = time.Now()
+		oldTa.ElapsedTime = newTa.ElapsedTime
+		err = object.UpdateTacacsAccounting(oldTa)
+	case flags&acctFlagWatchdog != 0:
+		oldTa.LastUpdate = time.Now()
+		oldTa.ElapsedTime = newTa.ElapsedTime
+		err = object.UpdateTacacsAccounting(oldTa)
+	}
+	if err != nil {
+		log.Printf("handleAcct() failed, err = %v", err)
+		return acctReply(acctStatusError, "Accounting error"), nil
+	}
+
+	return acctReply(acctStatusSuccess, ""), nil
+}
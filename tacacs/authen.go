@@ -0,0 +1,242 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tacacs
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+const (
+	authenActionLogin = 1
+	authenTypeAscii   = 1
+	authenTypePap     = 2
+)
+
+const (
+	authenStatusPass    = 1
+	authenStatusFail    = 2
+	authenStatusGetData = 3
+	authenStatusGetUser = 4
+	authenStatusGetPass = 5
+	authenStatusError   = 7
+)
+
+const (
+	authenReplyFlagNoEcho    = 0x01
+	authenContinueFlagAbort  = 0x01
+	authenStateWaitUser      = 1
+	authenStateWaitPassword  = 2
+	authenStateWaitMfaCode   = 3
+	authenFailedMessage      = "Authentication failed"
+	authenUnsupportedMessage = "Only ASCII and PAP login are supported"
+)
+
+type authenStart struct {
+	Action     byte
+	PrivLvl    byte
+	AuthenType byte
+	Service    byte
+	User       string
+	Port       string
+	RemAddr    string
+	Data       []byte
+}
+
+type authenContinue struct {
+	UserMsg string
+	Data    []byte
+	Flags   byte
+}
+
+// authenSession is an ASCII login in progress, which prompts for the username, the password and the MFA code
+type authenSession struct {
+	state    int
+	username string
+	remAddr  string
+	user     *object.User
+	mfaType  string
+}
+
+func parseAuthenStart(body []byte) (*authenStart, error) {
+	r := &fieldReader{b: body}
+	start := &authenStart{
+		Action:     r.byte(),
+		PrivLvl:    r.byte(),
+		AuthenType: r.byte(),
+		Service:    r.byte(),
+	}
+	userLen, portLen, remAddrLen, dataLen := int(r.byte()), int(r.byte()), int(r.byte()), int(r.byte())
+	start.User = string(r.bytes(userLen))
+	start.Port = string(r.bytes(portLen))
+	start.RemAddr = string(r.bytes(remAddrLen))
+	start.Data = r.bytes(dataLen)
+	return start, r.err
+}
+
+func parseAuthenContinue(body []byte) (*authenContinue, error) {
+	r := &fieldReader{b: body}
+	userMsgLen, dataLen := r.uint16(), r.uint16()
+	cont := &authenContinue{Flags: r.byte()}
+	cont.UserMsg = string(r.bytes(userMsgLen))
+	cont.Data = r.bytes(dataLen)
+	return cont, r.err
+}
+
+func authenReply(status byte, flags byte, serverMsg string) []byte {
+	w := &fieldWriter{}
+	w.byte(status)
+	w.byte(flags)
+	w.uint16(len(serverMsg))
+	w.uint16(0)
+	w.bytes([]byte(serverMsg))
+	return w.b
+}
+
+func (c *tacacsConn) handleAuthen(h *header, body []byte) ([]byte, error) {
+	if h.SeqNo == 1 {
+		start, err := parseAuthenStart(body)
+		if err != nil {
+			return nil, err
+		}
+
+		delete(c.sessions, h.SessionId)
+		return c.handleAuthenStart(h.SessionId, start), nil
+	}
+
+	cont, err := parseAuthenContinue(body)
+	if err != nil {
+		return nil, err
+	}
+
+	session, ok := c.sessions[h.SessionId]
+	if !ok {
+		return authenReply(authenStatusError, 0, "The authentication session is not found"), nil
+	}
+	if cont.Flags&authenContinueFlagAbort != 0 {
+		delete(c.sessions, h.SessionId)
+		return nil, nil
+	}
+	return c.handleAuthenContinue(h.SessionId, session, cont), nil
+}
+
+func (c *tacacsConn) handleAuthenStart(sessionId uint32, start *authenStart) []byte {
+	if start.Action != authenActionLogin {
+		return authenReply(authenStatusError, 0, authenUnsupportedMessage)
+	}
+
+	switch start.AuthenType {
+	case authenTypePap:
+		user, err := checkUserPassword(start.User, string(start.Data))
+		if err != nil {
+			log.Printf("handleAuthenStart() failed for user: %s from %s, err = %v", start.User, start.RemAddr, err)
+			return authenReply(authenStatusFail, 0, authenFailedMessage)
+		}
+		if user.IsMfaEnabled() {
+			return authenReply(authenStatusFail, 0, "MFA is enabled, please use ASCII login")
+		}
+		return authenReply(authenStatusPass, 0, "")
+	case authenTypeAscii:
+		session := &authenSession{username: start.User, remAddr: start.RemAddr}
+		c.sessions[sessionId] = session
+		if session.username == "" {
+			session.state = authenStateWaitUser
+			return authenReply(authenStatusGetUser, 0, "Username: ")
+		}
+
+		session.state = authenStateWaitPassword
+		return authenReply(authenStatusGetPass, authenReplyFlagNoEcho, "Password: ")
+	default:
+		return authenReply(authenStatusFail, 0, authenUnsupportedMessage)
+	}
+}
+
+func (c *tacacsConn) handleAuthenContinue(sessionId uint32, session *authenSession, cont *authenContinue) []byte {
+	switch session.state {
+	case authenStateWaitUser:
+		if cont.UserMsg == "" {
+			delete(c.sessions, sessionId)
+			return authenReply(authenStatusFail, 0, authenFailedMessage)
+		}
+
+		session.username = cont.UserMsg
+		session.state = authenStateWaitPassword
+		return authenReply(authenStatusGetPass, authenReplyFlagNoEcho, "Password: ")
+	case authenStateWaitPassword:
+		user, err := checkUserPassword(session.username, cont.UserMsg)
+		if err != nil {
+			log.Printf("handleAuthenContinue() failed for user: %s from %s, err = %v", session.username, session.remAddr, err)
+			delete(c.sessions, sessionId)
+			return authenReply(authenStatusFail, 0, authenFailedMessage)
+		}
+
+		if !user.IsMfaEnabled() {
+			delete(c.sessions, sessionId)
+			return authenReply(authenStatusPass, 0, "")
+		}
+
+		prompt, err := c.startMfa(session, user)
+		if err != nil {
+			log.Printf("handleAuthenContinue() failed to start MFA for user: %s, err = %v", user.GetId(), err)
+			delete(c.sessions, sessionId)
+			return authenReply(authenStatusFail, 0, authenFailedMessage)
+		}
+		return authenReply(authenStatusGetData, 0, prompt)
+	case authenStateWaitMfaCode:
+		delete(c.sessions, sessionId)
+
+		mfaUtil := object.GetMfaUtil(session.mfaType, session.user.GetMfaProps(session.mfaType, false))
+		if mfaUtil == nil || mfaUtil.Verify(cont.UserMsg) != nil {
+			return authenReply(authenStatusFail, 0, authenFailedMessage)
+		}
+		return authenReply(authenStatusPass, 0, "")
+	default:
+		delete(c.sessions, sessionId)
+		return authenReply(authenStatusError, 0, "Unexpected authentication state")
+	}
+}
+
+// startMfa prompts for the code of the user's preferred MFA method, sending it first for SMS and email
+func (c *tacacsConn) startMfa(session *authenSession, user *object.User) (string, error) {
+	mfaProps := user.GetPreferredMfaProps(false)
+	if mfaProps == nil || !mfaProps.Enabled {
+		return "", fmt.Errorf("the preferred MFA method is not enabled")
+	}
+
+	prompt := "OTP: "
+	if mfaProps.MfaType == object.SmsType || mfaProps.MfaType == object.EmailType {
+		dest, err := object.SendMfaCode(user, mfaProps.MfaType, c.getNasAddr())
+		if err != nil {
+			return "", err
+		}
+		prompt = fmt.Sprintf("Enter the code sent to %s: ", dest)
+	}
+
+	session.user = user
+	session.mfaType = mfaProps.MfaType
+	session.state = authenStateWaitMfaCode
+	return prompt, nil
+}
+
+func checkUserPassword(username string, password string) (*object.User, error) {
+	organization, name, err := getOrganizationAndName(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return object.CheckUserPassword(organization, name, password, "en")
+}
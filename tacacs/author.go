@@ -0,0 +1,139 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tacacs
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	authorStatusPassAdd = 0x01
+	authorStatusFail    = 0x10
+	authorStatusError   = 0x11
+)
+
+// request is the common part of authorization and accounting requests
+type request struct {
+	AuthenMethod byte
+	PrivLvl      byte
+	AuthenType   byte
+	Service      byte
+	User         string
+	Port         string
+	RemAddr      string
+	Args         []string
+}
+
+func parseRequest(r *fieldReader) (*request, error) {
+	req := &request{
+		AuthenMethod: r.byte(),
+		PrivLvl:      r.byte(),
+		AuthenType:   r.byte(),
+		Service:      r.byte(),
+	}
+	userLen, portLen, remAddrLen, argCnt := int(r.byte()), int(r.byte()), int(r.byte()), int(r.byte())
+	argLens := []int{}
+	for i := 0; i < argCnt; i++ {
+		argLens = append(argLens, int(r.byte()))
+	}
+	req.User = string(r.bytes(userLen))
+	req.Port = string(r.bytes(portLen))
+	req.RemAddr = string(r.bytes(remAddrLen))
+	req.Args = readArgs(r, argLens)
+	return req, r.err
+}
+
+func authorReply(status byte, serverMsg string, args []string) []byte {
+	w := &fieldWriter{}
+	w.byte(status)
+	w.byte(byte(len(args)))
+	w.uint16(len(serverMsg))
+	w.uint16(0)
+	for _, arg := range args {
+		w.byte(byte(len(arg)))
+	}
+	w.bytes([]byte(serverMsg))
+	for _, arg := range args {
+		w.bytes([]byte(arg))
+	}
+	return w.b
+}
+
+func getUser(username string) (*object.User, error) {
+	organization, name, err := getOrganizationAndName(username)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := object.GetUser(util.GetId(organization, name))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDeleted || user.IsForbidden {
+		return nil, fmt.Errorf("the user: %s doesn't exist or is forbidden", util.GetId(organization, name))
+	}
+	return user, nil
+}
+
+// getCommand joins "cmd" and "cmd-arg" into the command line, "<cr>" ends the arguments on Cisco devices
+func getCommand(args []string) string {
+	command := []string{getArgValue(args, "cmd")}
+	for _, arg := range getArgValues(args, "cmd-arg") {
+		if arg != "<cr>" {
+			command = append(command, arg)
+		}
+	}
+	return strings.TrimSpace(strings.Join(command, " "))
+}
+
+// handleAuthor grants the privilege level when a session starts, and checks each command against the
+// TACACS+ permissions of the user
+func (c *tacacsConn) handleAuthor(body []byte) ([]byte, error) {
+	req, err := parseRequest(&fieldReader{b: body})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := getUser(req.User)
+	if err != nil {
+		log.Printf("handleAuthor() failed for user: %s, err = %v", req.User, err)
+		return authorReply(authorStatusFail, "Authorization failed", nil), nil
+	}
+
+	authorization, err := object.GetTacacsAuthorization(user.GetId())
+	if err != nil {
+		log.Printf("handleAuthor() failed for user: %s, err = %v", user.GetId(), err)
+		return authorReply(authorStatusError, "Authorization error", nil), nil
+	}
+	if !authorization.IsAllowed {
+		return authorReply(authorStatusFail, "The user is not allowed to access the device", nil), nil
+	}
+
+	command := getCommand(req.Args)
+	if command == "" {
+		return authorReply(authorStatusPassAdd, "", []string{fmt.Sprintf("priv-lvl=%d", authorization.PrivLvl)}), nil
+	}
+
+	if !authorization.IsCommandAllowed(command) {
+		log.Printf("handleAuthor() denied command: %s for user: %s", command, user.GetId())
+		return authorReply(authorStatusFail, "Command authorization failed", nil), nil
+	}
+	return authorReply(authorStatusPassAdd, "", nil), nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tacacs
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	majorVersion = 0xc
+	headerLength = 12
+	maxBodySize  = 1 << 16
+)
+
+const (
+	typeAuthen = 1
+	typeAuthor = 2
+	typeAcct   = 3
+)
+
+const (
+	flagUnencrypted   = 0x01
+	flagSingleConnect = 0x04
+)
+
+type header struct {
+	Version   byte
+	Type      byte
+	SeqNo     byte
+	Flags     byte
+	SessionId uint32
+	Length    uint32
+}
+
+func readPacket(r io.Reader) (*header, []byte, error) {
+	b := make([]byte, headerLength)
+	_, err := io.ReadFull(r, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := &header{
+		Version:   b[0],
+		Type:      b[1],
+		SeqNo:     b[2],
+		Flags:     b[3],
+		SessionId: binary.BigEndian.Uint32(b[4:8]),
+		Length:    binary.BigEndian.Uint32(b[8:12]),
+	}
+	if h.Version>>4 != majorVersion {
+		return nil, nil, fmt.Errorf("unsupported TACACS+ version: %#x", h.Version)
+	}
+	if h.Length > maxBodySize {
+		return nil, nil, fmt.Errorf("TACACS+ body is too large: %d", h.Length)
+	}
+
+	body := make([]byte, h.Length)
+	_, err = io.ReadFull(r, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, body, nil
+}
+
+func writePacket(w io.Writer, h *header, body []byte) error {
+	b := make([]byte, headerLength, headerLength+len(body))
+	b[0] = h.Version
+	b[1] = h.Type
+	b[2] = h.SeqNo
+	b[3] = h.Flags
+	binary.BigEndian.PutUint32(b[4:8], h.SessionId)
+	binary.BigEndian.PutUint32(b[8:12], uint32(len(body)))
+
+	_, err := w.Write(append(b, body...))
+	return err
+}
+
+// obfuscate XORs the body with the MD5 based pad of RFC 8907 section 4.5, the same
+// operation encrypts and decrypts
+func obfuscate(h *header, secret []byte, body []byte) {
+	if len(secret) == 0 || h.Flags&flagUnencrypted != 0 {
+		return
+	}
+
+	prefix := make([]byte, 4, 4+len(secret)+2)
+	binary.BigEndian.PutUint32(prefix, h.SessionId)
+	prefix = append(prefix, secret...)
+	prefix = append(prefix, h.Version, h.SeqNo)
+
+	var pad []byte
+	for i := 0; i < len(body); i++ {
+		if i%md5.Size == 0 {
+			sum := md5.Sum(append(append([]byte(nil), prefix...), pad...))
+			pad = sum[:]
+		}
+		body[i] ^= pad[i%md5.Size]
+	}
+}
+
+// fieldReader decodes the fixed and variable length fields of a packet body
+type fieldReader struct {
+	b   []byte
+	err error
+}
+
+func (r *fieldReader) byte() byte {
+	if r.err != nil || len(r.b) < 1 {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	v := r.b[0]
+	r.b = r.b[1:]
+	return v
+}
+
+func (r *fieldReader) uint16() int {
+	if r.err != nil || len(r.b) < 2 {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	v := binary.BigEndian.Uint16(r.b)
+	r.b = r.b[2:]
+	return int(v)
+}
+
+func (r *fieldReader) bytes(n int) []byte {
+	if r.err != nil || len(r.b) < n {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+// fieldWriter encodes a packet body
+type fieldWriter struct {
+	b []byte
+}
+
+func (w *fieldWriter) byte(v byte) {
+	w.b = append(w.b, v)
+}
+
+func (w *fieldWriter) uint16(v int) {
+	w.b = binary.BigEndian.AppendUint16(w.b, uint16(v))
+}
+
+func (w *fieldWriter) bytes(v []byte) {
+	w.b = append(w.b, v...)
+}
+
+// readArgs decodes the "name=value" or "name*value" arguments of authorization and accounting requests
+func readArgs(r *fieldReader, lengths []int) []string {
+	args := []string{}
+	for _, length := range lengths {
+		args = append(args, string(r.bytes(length)))
+	}
+	return args
+}
+
+func getArgValues(args []string, name string) []string {
+	res := []string{}
+	for _, arg := range args {
+		for _, separator := range []string{"=", "*"} {
+			if len(arg) > len(name) && arg[:len(name)] == name && arg[len(name):len(name)+1] == separator {
+				res = append(res, arg[len(name)+1:])
+				break
+			}
+		}
+	}
+	return res
+}
+
+func getArgValue(args []string, name string) string {
+	values := getArgValues(args, name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
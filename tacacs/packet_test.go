@@ -0,0 +1,56 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tacacs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscate(t *testing.T) {
+	h := &header{Version: majorVersion << 4, Type: typeAuthen, SeqNo: 1, SessionId: 0x12345678}
+	body := bytes.Repeat([]byte("casdoor"), 10)
+	data := append([]byte(nil), body...)
+
+	obfuscate(h, []byte("secret"), data)
+	assert.NotEqual(t, body, data)
+
+	obfuscate(h, []byte("secret"), data)
+	assert.Equal(t, body, data)
+}
+
+func TestParseAuthenStart(t *testing.T) {
+	body := []byte{authenActionLogin, 1, authenTypePap, 1, 5, 4, 8, 6}
+	body = append(body, "alicetty110.0.0.1secret"...)
+
+	start, err := parseAuthenStart(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", start.User)
+	assert.Equal(t, "tty1", start.Port)
+	assert.Equal(t, "10.0.0.1", start.RemAddr)
+	assert.Equal(t, "secret", string(start.Data))
+
+	_, err = parseAuthenStart(body[:20])
+	assert.NotNil(t, err)
+}
+
+func TestGetCommand(t *testing.T) {
+	args := []string{"service=shell", "cmd=show", "cmd-arg=running-config", "cmd-arg=<cr>"}
+	assert.Equal(t, "show running-config", getCommand(args))
+	assert.Equal(t, "shell", getArgValue(args, "service"))
+	assert.Equal(t, "", getCommand([]string{"service=shell", "cmd*"}))
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tacacs
+
+import (
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+)
+
+const connIdleTimeout = time.Minute * 5
+
+func StartTacacsServer() {
+	tacacsServerPort := conf.GetConfigString("tacacsServerPort")
+	if tacacsServerPort == "" || tacacsServerPort == "0" {
+		return
+	}
+	// the body is sent in clear text without a shared key
+	if conf.GetConfigString("tacacsSecret") == "" {
+		return
+	}
+
+	listener, err := net.Listen("tcp", "0.0.0.0:"+tacacsServerPort)
+	if err != nil {
+		log.Printf("StartTacacsServer() failed, err = %v", err)
+		return
+	}
+
+	log.Printf("Starting TACACS+ server on %s", listener.Addr())
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("StartTacacsServer() failed, err = %v", err)
+			return
+		}
+
+		go handleConn(conn)
+	}
+}
+
+// tacacsConn is a connection from a network device, several sessions can share it with single-connect mode
+type tacacsConn struct {
+	conn     net.Conn
+	secret   []byte
+	sessions map[uint32]*authenSession
+}
+
+func handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	c := &tacacsConn{
+		conn:     conn,
+		secret:   []byte(conf.GetConfigString("tacacsSecret")),
+		sessions: map[uint32]*authenSession{},
+	}
+
+	for {
+		err := conn.SetReadDeadline(time.Now().Add(connIdleTimeout))
+		if err != nil {
+			return
+		}
+
+		h, body, err := readPacket(conn)
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("handleConn() closed the connection from %s, err = %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		if h.Flags&flagUnencrypted != 0 {
+			log.Printf("handleConn() rejected an unencrypted packet from %s", conn.RemoteAddr())
+			return
+		}
+		obfuscate(h, c.secret, body)
+
+		var reply []byte
+		switch h.Type {
+		case typeAuthen:
+			reply, err = c.handleAuthen(h, body)
+		case typeAuthor:
+			reply, err = c.handleAuthor(body)
+		case typeAcct:
+			reply, err = c.handleAcct(body)
+		default:
+			log.Printf("handleConn() got an unknown packet type: %d from %s", h.Type, conn.RemoteAddr())
+			return
+		}
+		if err != nil {
+			log.Printf("handleConn() got an invalid packet from %s, err = %v", conn.RemoteAddr(), err)
+			return
+		}
+		if reply == nil {
+			continue
+		}
+
+		replyHeader := &header{
+			Version:   h.Version,
+			Type:      h.Type,
+			SeqNo:     h.SeqNo + 1,
+			Flags:     h.Flags & (flagUnencrypted | flagSingleConnect),
+			SessionId: h.SessionId,
+		}
+		obfuscate(replyHeader, c.secret, reply)
+		err = writePacket(conn, replyHeader, reply)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *tacacsConn) getNasAddr() string {
+	host, _, err := net.SplitHostPort(c.conn.RemoteAddr().String())
+	if err != nil {
+		return c.conn.RemoteAddr().String()
+	}
+	return host
+}
+
+// getOrganizationAndName accepts both "organization/name" and a bare name of tacacsDefaultOrganization
+func getOrganizationAndName(username string) (string, string, error) {
+	if strings.Contains(username, "/") {
+		return util.GetOwnerAndNameFromIdWithError(username)
+	}
+
+	organization := conf.GetConfigString("tacacsDefaultOrganization")
+	if organization == "" {
+		organization = "built-in"
+	}
+	return organization, username, nil
+}
@@ -17,6 +17,8 @@ package util
 import (
 	"encoding/json"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 func StructToJson(v interface{}) string {
@@ -67,3 +69,62 @@ func TryJsonToAnonymousStruct(j string) (interface{}, error) {
 	}
 	return i, nil
 }
+
+// GetJsonPathValue returns the value at the JSONPath in the decoded JSON data, only the child and the array index
+// operators are supported, e.g. "$.data.users", "$.emails[0].value" or "$['first name']". A path without the leading
+// "$" is relative to the root.
+func GetJsonPathValue(data interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(path, "$")
+	if path != "" && path[0] != '.' && path[0] != '[' {
+		path = "." + path
+	}
+
+	for path != "" {
+		key := ""
+		index := -1
+		if path[0] == '.' {
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			key, path = path[:end], path[end:]
+		} else if path[0] == '[' {
+			end := strings.Index(path, "]")
+			if end == -1 {
+				return nil, false
+			}
+			token := path[1:end]
+			path = path[end+1:]
+			if len(token) >= 2 && (token[0] == '\'' || token[0] == '"') && token[len(token)-1] == token[0] {
+				key = token[1 : len(token)-1]
+			} else {
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 {
+					return nil, false
+				}
+				index = i
+			}
+		} else {
+			return nil, false
+		}
+
+		if index >= 0 {
+			array, ok := data.([]interface{})
+			if !ok || index >= len(array) {
+				return nil, false
+			}
+			data = array[index]
+		} else {
+			m, ok := data.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			data, ok = m[key]
+			if !ok {
+				return nil, false
+			}
+		}
+	}
+	return data, true
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJsonPathValue(t *testing.T) {
+	var data interface{}
+	err := json.Unmarshal([]byte(`{"data": {"users": [{"name": "alice", "emails": [{"value": "a@example.com"}], "first name": "Alice"}]}}`), &data)
+	assert.Nil(t, err)
+
+	scenarios := []struct {
+		path     string
+		expected interface{}
+		found    bool
+	}{
+		{"$.data.users[0].name", "alice", true},
+		{"data.users[0].emails[0].value", "a@example.com", true},
+		{"$.data.users[0]['first name']", "Alice", true},
+		{"$.data.users[1].name", nil, false},
+		{"$.data.groups", nil, false},
+		{"$.data.users[x]", nil, false},
+	}
+	for _, scenario := range scenarios {
+		value, found := GetJsonPathValue(data, scenario.path)
+		assert.Equal(t, scenario.found, found, scenario.path)
+		assert.Equal(t, scenario.expected, value, scenario.path)
+	}
+
+	value, found := GetJsonPathValue(data, "$")
+	assert.True(t, found)
+	assert.Equal(t, data, value)
+}
@@ -76,3 +76,23 @@ func HaveIntersection(arr1 []string, arr2 []string) bool {
 
 	return false
 }
+
+func IsSameElements(arr1 []string, arr2 []string) bool {
+	if len(arr1) != len(arr2) {
+		return false
+	}
+
+	counts := make(map[string]int)
+	for _, str := range arr1 {
+		counts[str]++
+	}
+
+	for _, str := range arr2 {
+		counts[str]--
+		if counts[str] < 0 {
+			return false
+		}
+	}
+
+	return true
+}